@@ -0,0 +1,287 @@
+package logger
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type amqpPublished struct {
+	conn, channel        int
+	exchange, routingKey string
+	contentType          string
+	deliveryMode         byte
+	body                 string
+}
+
+// fakeAMQPBroker speaks enough AMQP 0-9-1 to accept publishes. onPublish
+// decides what happens to each message: "ack", "close-channel" or
+// "drop-connection".
+type fakeAMQPBroker struct {
+	t         *testing.T
+	ln        net.Listener
+	onPublish func(n int) string
+
+	mu        sync.Mutex
+	published []amqpPublished
+	auth      []string
+	vhosts    []string
+	conns     int
+}
+
+func newFakeAMQPBroker(t *testing.T, onPublish func(n int) string) *fakeAMQPBroker {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &fakeAMQPBroker{t: t, ln: ln, onPublish: onPublish}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			b.mu.Lock()
+			b.conns++
+			id := b.conns
+			b.mu.Unlock()
+			go b.serve(conn, id)
+		}
+	}()
+	return b
+}
+
+func (b *fakeAMQPBroker) serve(conn net.Conn, id int) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	header := make([]byte, 8)
+	if _, err := r.Read(header); err != nil || string(header) != string(amqpProtocolHeader) {
+		return
+	}
+	send := func(channel uint16, e *amqpEncoder) {
+		_, _ = conn.Write(appendAMQPFrame(nil, amqpFrameMethod, channel, e.b))
+	}
+	next := func() (amqpMethod, bool) {
+		typ, channel, payload, err := readAMQPFrame(r)
+		if err != nil || typ != amqpFrameMethod {
+			return amqpMethod{}, false
+		}
+		m, err := parseAMQPMethod(channel, payload)
+		return m, err == nil
+	}
+
+	e := newAMQPMethod(amqpConnection, amqpConnectionStart)
+	e.octet(0)
+	e.octet(9)
+	e.table(map[string]interface{}{"product": "fake"})
+	e.longstr("AMQPLAIN PLAIN")
+	e.longstr("en_US")
+	send(0, e)
+	m, ok := next()
+	if !ok {
+		return
+	}
+	d := amqpDecoder{b: m.args}
+	d.skipTable()
+	d.shortstr()
+	auth := d.longstr()
+
+	e = newAMQPMethod(amqpConnection, amqpConnectionTune)
+	e.short(2047)
+	e.long(4096)
+	e.short(60)
+	send(0, e)
+	if _, ok := next(); !ok {
+		return
+	}
+	m, ok = next()
+	if !ok {
+		return
+	}
+	d = amqpDecoder{b: m.args}
+	vhost := d.shortstr()
+	b.mu.Lock()
+	b.auth = append(b.auth, auth)
+	b.vhosts = append(b.vhosts, vhost)
+	b.mu.Unlock()
+	e = newAMQPMethod(amqpConnection, amqpConnectionOpenOk)
+	e.shortstr("")
+	send(0, e)
+
+	confirms := map[uint16]uint64{}
+	for {
+		m, ok := next()
+		if !ok {
+			return
+		}
+		switch {
+		case m.is(amqpChannel, amqpChannelOpen):
+			e := newAMQPMethod(amqpChannel, amqpChannelOpenOk)
+			e.longstr("")
+			send(m.channel, e)
+		case m.is(amqpConfirm, amqpConfirmSelect):
+			confirms[m.channel] = 0
+			send(m.channel, newAMQPMethod(amqpConfirm, amqpConfirmSelectOk))
+		case m.is(amqpConnection, amqpConnectionClose):
+			send(0, newAMQPMethod(amqpConnection, amqpConnectionCloseOk))
+			return
+		case m.is(amqpBasic, amqpBasicPublish):
+			d := amqpDecoder{b: m.args}
+			d.short()
+			msg := amqpPublished{conn: id, channel: int(m.channel), exchange: d.shortstr(), routingKey: d.shortstr()}
+
+			_, _, payload, err := readAMQPFrame(r)
+			if err != nil {
+				return
+			}
+			d = amqpDecoder{b: payload}
+			d.short()
+			d.short()
+			size := d.longlong()
+			d.short()
+			msg.contentType = d.shortstr()
+			msg.deliveryMode = d.octet()
+			var body []byte
+			for uint64(len(body)) < size {
+				_, _, payload, err := readAMQPFrame(r)
+				if err != nil {
+					return
+				}
+				body = append(body, payload...)
+			}
+			msg.body = string(body)
+
+			b.mu.Lock()
+			b.published = append(b.published, msg)
+			n := len(b.published)
+			b.mu.Unlock()
+
+			switch b.onPublish(n) {
+			case "close-channel":
+				e := newAMQPMethod(amqpChannel, amqpChannelClose)
+				e.short(404)
+				e.shortstr("NOT_FOUND - no exchange 'logs' in vhost '/'")
+				e.short(amqpBasic)
+				e.short(amqpBasicPublish)
+				send(m.channel, e)
+			case "drop-connection":
+				return
+			default:
+				if seq, ok := confirms[m.channel]; ok {
+					confirms[m.channel] = seq + 1
+					e := newAMQPMethod(amqpBasic, amqpBasicAck)
+					e.longlong(seq + 1)
+					e.octet(0)
+					send(m.channel, e)
+				}
+			}
+		}
+	}
+}
+
+func (b *fakeAMQPBroker) messages() []amqpPublished {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]amqpPublished(nil), b.published...)
+}
+
+func (b *fakeAMQPBroker) url(userinfo, vhost string) string {
+	return "amqp://" + userinfo + b.ln.Addr().String() + vhost
+}
+
+func TestAMQPWriter_PublishConfirm(t *testing.T) {
+	broker := newFakeAMQPBroker(t, func(int) string { return "ack" })
+	w, err := NewAMQPWriter(AMQPConfig{
+		URL: broker.url("app:s3cret@", "/logs"), Exchange: "logs", RoutingKey: "billing", Confirm: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	large := `{"message":"` + strings.Repeat("x", 10000) + `"}`
+	if _, err := w.Write([]byte(`{"level":"INFO","message":"hello"}` + "\n" + large + "\nWARN: plain text\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := broker.messages()
+	if len(msgs) != 3 {
+		t.Fatalf("got %d messages, want 3", len(msgs))
+	}
+	first := msgs[0]
+	if first.exchange != "logs" || first.routingKey != "billing" || first.deliveryMode != 2 ||
+		first.contentType != "application/json" || first.body != `{"level":"INFO","message":"hello"}` {
+		t.Errorf("unexpected message %+v", first)
+	}
+	if msgs[1].body != large {
+		t.Errorf("large body split over frames was not reassembled: %d bytes", len(msgs[1].body))
+	}
+	if msgs[2].contentType != "text/plain" {
+		t.Errorf("unexpected content type %s", msgs[2].contentType)
+	}
+	if broker.auth[0] != "\x00app\x00s3cret" || broker.vhosts[0] != "logs" {
+		t.Errorf("unexpected auth %q or vhost %q", broker.auth[0], broker.vhosts[0])
+	}
+}
+
+func TestAMQPWriter_ReopensClosedChannel(t *testing.T) {
+	broker := newFakeAMQPBroker(t, func(n int) string {
+		if n == 1 {
+			return "close-channel"
+		}
+		return "ack"
+	})
+	w, err := NewAMQPWriter(AMQPConfig{URL: broker.url("", ""), Exchange: "logs", Confirm: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("INFO: first\n")); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("Write() = %v, want the channel error", err)
+	}
+	if _, err := w.Write([]byte("INFO: second\n")); err != nil {
+		t.Fatal(err)
+	}
+	msgs := broker.messages()
+	if len(msgs) != 2 || msgs[1].channel != 2 || msgs[1].conn != 1 {
+		t.Errorf("want the second message on a new channel of the same connection, got %+v", msgs)
+	}
+}
+
+func TestAMQPWriter_Reconnects(t *testing.T) {
+	broker := newFakeAMQPBroker(t, func(n int) string {
+		if n == 1 {
+			return "drop-connection"
+		}
+		return "ack"
+	})
+	w, err := NewAMQPWriter(AMQPConfig{URL: broker.url("", ""), RoutingKey: "logs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Write([]byte("INFO: first\n")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-w.conn.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection loss not noticed")
+	}
+	if _, err := w.Write([]byte("INFO: second\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	msgs := broker.messages()
+	if len(msgs) != 2 || msgs[1].conn != 2 || msgs[1].exchange != "" || msgs[1].routingKey != "logs" {
+		t.Errorf("want the second message on a new connection, got %+v", msgs)
+	}
+}
@@ -0,0 +1,236 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// A minimal AMQP 0-9-1 frame codec: enough of the connection, channel,
+// confirm and basic classes to publish messages.
+
+const (
+	amqpFrameMethod    = 1
+	amqpFrameHeader    = 2
+	amqpFrameBody      = 3
+	amqpFrameHeartbeat = 8
+	amqpFrameEnd       = 0xce
+
+	amqpFrameMinSize = 4096
+)
+
+var amqpProtocolHeader = []byte("AMQP\x00\x00\x09\x01")
+
+// AMQP class and method IDs.
+const (
+	amqpConnection = 10
+	amqpChannel    = 20
+	amqpBasic      = 60
+	amqpConfirm    = 85
+
+	amqpConnectionStart   = 10
+	amqpConnectionStartOk = 11
+	amqpConnectionTune    = 30
+	amqpConnectionTuneOk  = 31
+	amqpConnectionOpen    = 40
+	amqpConnectionOpenOk  = 41
+	amqpConnectionClose   = 50
+	amqpConnectionCloseOk = 51
+
+	amqpChannelOpen    = 10
+	amqpChannelOpenOk  = 11
+	amqpChannelClose   = 40
+	amqpChannelCloseOk = 41
+
+	amqpBasicPublish = 40
+	amqpBasicAck     = 80
+	amqpBasicNack    = 120
+
+	amqpConfirmSelect   = 10
+	amqpConfirmSelectOk = 11
+)
+
+// amqpMethod is a decoded method frame.
+type amqpMethod struct {
+	channel       uint16
+	class, method uint16
+	args          []byte
+}
+
+func (m amqpMethod) is(class, method uint16) bool {
+	return m.class == class && m.method == method
+}
+
+// closeError describes a Connection.Close or Channel.Close method.
+func (m amqpMethod) closeError() error {
+	d := amqpDecoder{b: m.args}
+	code := d.short()
+	text := d.shortstr()
+	return fmt.Errorf("%d %s", code, text)
+}
+
+func readAMQPFrame(r *bufio.Reader) (typ byte, channel uint16, payload []byte, err error) {
+	var hdr [7]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(hdr[3:])
+	if size > 1<<24 {
+		return 0, 0, nil, fmt.Errorf("amqp: frame of %d bytes is too large", size)
+	}
+	buf := make([]byte, size+1)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return 0, 0, nil, err
+	}
+	if buf[size] != amqpFrameEnd {
+		return 0, 0, nil, errors.New("amqp: malformed frame")
+	}
+	return hdr[0], binary.BigEndian.Uint16(hdr[1:]), buf[:size], nil
+}
+
+func appendAMQPFrame(b []byte, typ byte, channel uint16, payload []byte) []byte {
+	b = append(b, typ)
+	b = binary.BigEndian.AppendUint16(b, channel)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(payload)))
+	b = append(b, payload...)
+	return append(b, amqpFrameEnd)
+}
+
+// parseAMQPMethod decodes the payload of a method frame.
+func parseAMQPMethod(channel uint16, payload []byte) (amqpMethod, error) {
+	if len(payload) < 4 {
+		return amqpMethod{}, errors.New("amqp: short method frame")
+	}
+	return amqpMethod{
+		channel: channel,
+		class:   binary.BigEndian.Uint16(payload),
+		method:  binary.BigEndian.Uint16(payload[2:]),
+		args:    payload[4:],
+	}, nil
+}
+
+// amqpEncoder builds method arguments and content headers.
+type amqpEncoder struct {
+	b []byte
+}
+
+func newAMQPMethod(class, method uint16) *amqpEncoder {
+	e := &amqpEncoder{}
+	e.short(class)
+	e.short(method)
+	return e
+}
+
+func (e *amqpEncoder) octet(v byte)      { e.b = append(e.b, v) }
+func (e *amqpEncoder) short(v uint16)    { e.b = binary.BigEndian.AppendUint16(e.b, v) }
+func (e *amqpEncoder) long(v uint32)     { e.b = binary.BigEndian.AppendUint32(e.b, v) }
+func (e *amqpEncoder) longlong(v uint64) { e.b = binary.BigEndian.AppendUint64(e.b, v) }
+
+func (e *amqpEncoder) shortstr(s string) {
+	if len(s) > 255 {
+		s = s[:255]
+	}
+	e.b = append(e.b, byte(len(s)))
+	e.b = append(e.b, s...)
+}
+
+func (e *amqpEncoder) longstr(s string) {
+	e.long(uint32(len(s)))
+	e.b = append(e.b, s...)
+}
+
+// table encodes a field table of strings, booleans and nested tables.
+func (e *amqpEncoder) table(t map[string]interface{}) {
+	keys := make([]string, 0, len(t))
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	inner := &amqpEncoder{}
+	for _, k := range keys {
+		inner.shortstr(k)
+		switch v := t[k].(type) {
+		case string:
+			inner.octet('S')
+			inner.longstr(v)
+		case bool:
+			inner.octet('t')
+			if v {
+				inner.octet(1)
+			} else {
+				inner.octet(0)
+			}
+		case map[string]interface{}:
+			inner.octet('F')
+			inner.table(v)
+		default:
+			inner.octet('V')
+		}
+	}
+	e.long(uint32(len(inner.b)))
+	e.b = append(e.b, inner.b...)
+}
+
+// amqpDecoder reads method arguments; errors are sticky and reported by err.
+type amqpDecoder struct {
+	b   []byte
+	err error
+}
+
+func (d *amqpDecoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.b) < n {
+		d.err = errors.New("amqp: truncated method arguments")
+		d.b = nil
+		return nil
+	}
+	p := d.b[:n]
+	d.b = d.b[n:]
+	return p
+}
+
+func (d *amqpDecoder) octet() byte {
+	if p := d.take(1); p != nil {
+		return p[0]
+	}
+	return 0
+}
+
+func (d *amqpDecoder) short() uint16 {
+	if p := d.take(2); p != nil {
+		return binary.BigEndian.Uint16(p)
+	}
+	return 0
+}
+
+func (d *amqpDecoder) long() uint32 {
+	if p := d.take(4); p != nil {
+		return binary.BigEndian.Uint32(p)
+	}
+	return 0
+}
+
+func (d *amqpDecoder) longlong() uint64 {
+	if p := d.take(8); p != nil {
+		return binary.BigEndian.Uint64(p)
+	}
+	return 0
+}
+
+func (d *amqpDecoder) shortstr() string {
+	return string(d.take(int(d.octet())))
+}
+
+func (d *amqpDecoder) longstr() string {
+	return string(d.take(int(d.long())))
+}
+
+func (d *amqpDecoder) skipTable() {
+	d.take(int(d.long()))
+}
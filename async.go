@@ -0,0 +1,197 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// AsyncConfig configures asynchronous logging.
+type AsyncConfig struct {
+	// QueueSize is the number of entries held for the workers. Defaults to
+	// 8192.
+	QueueSize int `json:"queue_size" yaml:"queue_size"`
+	// Workers is the number of goroutines writing queued entries to the
+	// outputs. Defaults to 1, which keeps entries in order; more workers
+	// help with slow outputs but may reorder entries.
+	Workers int `json:"workers" yaml:"workers"`
+	// Overflow is what a log call does when the queue is full: "block" (the
+	// default) waits for room, "drop_oldest" discards the oldest queued
+	// entry, "drop_newest" discards the new one, and "drop_below_level"
+	// discards new entries below OverflowLevel (default "info", so debug and
+	// trace go first) and waits for room for the others. Drops are counted
+	// in the "async" entry of Queues.
+	Overflow      string `json:"overflow" yaml:"overflow"`
+	OverflowLevel string `json:"overflow_level" yaml:"overflow_level"`
+}
+
+func (ac AsyncConfig) withDefaults() AsyncConfig {
+	if ac.QueueSize <= 0 {
+		ac.QueueSize = 8192
+	}
+	if ac.Workers <= 0 {
+		ac.Workers = 1
+	}
+	if ac.Overflow == "" {
+		ac.Overflow = "block"
+	}
+	if ac.OverflowLevel == "" {
+		ac.OverflowLevel = "info"
+	}
+	return ac
+}
+
+func (ac AsyncConfig) validate() error {
+	var errs []error
+	switch ac.Overflow {
+	case "", "block", "drop_oldest", "drop_newest", "drop_below_level":
+	default:
+		errs = append(errs, fmt.Errorf("unknown async overflow %q (want block, drop_oldest, drop_newest or drop_below_level)", ac.Overflow))
+	}
+	if _, ok := parseLevelName(ac.OverflowLevel); !ok && ac.OverflowLevel != "" {
+		errs = append(errs, fmt.Errorf("async overflow_level: unknown level %q", ac.OverflowLevel))
+	}
+	if ac.QueueSize < 0 || ac.Workers < 0 {
+		errs = append(errs, errors.New("async settings must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// asyncWriter hands entries to worker goroutines that write them to out, so
+// log calls don't wait for slow outputs. A full queue is handled according to
+// the overflow policy.
+type asyncWriter struct {
+	out      io.Writer
+	queue    chan []byte
+	wg       sync.WaitGroup
+	overflow string
+	minLevel logLevel // entries below it are dropped by drop_below_level
+
+	dropped     atomic.Uint64
+	dropReports diagnosticLimiter
+
+	// mu orders Write against close: writers hold it shared while they
+	// enqueue, and close takes it exclusively before closing the queue.
+	mu     sync.RWMutex
+	closed bool
+
+	// pending counts the entries queued or being written, for flush.
+	pendingMu sync.Mutex
+	pending   int
+	idle      *sync.Cond
+}
+
+func newAsyncWriter(out io.Writer, ac AsyncConfig) *asyncWriter {
+	ac = ac.withDefaults()
+	a := &asyncWriter{out: out, queue: make(chan []byte, ac.QueueSize), overflow: ac.Overflow}
+	a.minLevel, _ = parseLevelName(ac.OverflowLevel)
+	a.idle = sync.NewCond(&a.pendingMu)
+	for i := 0; i < ac.Workers; i++ {
+		a.wg.Add(1)
+		go a.run()
+	}
+	registerQueue(a)
+	return a
+}
+
+// Write queues a copy of p. After close, entries are written synchronously.
+func (a *asyncWriter) Write(p []byte) (int, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return a.out.Write(p)
+	}
+	a.pendingMu.Lock()
+	a.pending++
+	a.pendingMu.Unlock()
+	a.enqueue(append([]byte(nil), p...))
+	checkPressure()
+	return len(p), nil
+}
+
+// enqueue queues p, applying the overflow policy when the queue is full.
+func (a *asyncWriter) enqueue(p []byte) {
+	select {
+	case a.queue <- p:
+		return
+	default:
+	}
+	switch a.overflow {
+	case "drop_newest":
+		a.drop()
+		return
+	case "drop_oldest":
+		for {
+			select {
+			case a.queue <- p:
+				return
+			default:
+			}
+			select {
+			case <-a.queue:
+				a.drop()
+			default:
+			}
+		}
+	case "drop_below_level":
+		if level, _ := syslogEntryMeta(p); SeverityNumber(level) < SeverityNumber(a.minLevel) {
+			a.drop()
+			return
+		}
+	}
+	a.queue <- p
+}
+
+// drop discards a counted entry.
+func (a *asyncWriter) drop() {
+	n := a.dropped.Add(1)
+	a.done()
+	if a.dropReports.allow() {
+		diagnose("async", nil, "queue full, %d entries dropped so far (overflow %s)", n, a.overflow)
+	}
+}
+
+func (a *asyncWriter) run() {
+	defer a.wg.Done()
+	for p := range a.queue {
+		_, _ = a.out.Write(p)
+		a.done()
+		checkPressure()
+	}
+}
+
+func (a *asyncWriter) done() {
+	a.pendingMu.Lock()
+	a.pending--
+	if a.pending == 0 {
+		a.idle.Broadcast()
+	}
+	a.pendingMu.Unlock()
+}
+
+// flush waits until every entry queued so far has been written.
+func (a *asyncWriter) flush() {
+	a.pendingMu.Lock()
+	for a.pending > 0 {
+		a.idle.Wait()
+	}
+	a.pendingMu.Unlock()
+}
+
+// close writes the queued entries and stops the workers.
+func (a *asyncWriter) close() {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+	a.wg.Wait()
+	unregisterQueue(a)
+}
+
+func (a *asyncWriter) queueStats() QueueStats {
+	return QueueStats{Name: "async", Depth: len(a.queue), Capacity: cap(a.queue), Dropped: a.dropped.Load()}
+}
@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// gatedWriter blocks every write until release is closed.
+type gatedWriter struct {
+	release chan struct{}
+	mu      sync.Mutex
+	buf     bytes.Buffer
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsync_DecouplesSlowOutputs(t *testing.T) {
+	out := &gatedWriter{release: make(chan struct{})}
+	l := New(WithFormat("json"), WithOutputs(out), WithAsync(AsyncConfig{QueueSize: 16}))
+	defer l.Close()
+
+	for i := 0; i < 3; i++ {
+		l.Infof("entry %d", i) // returns although the output is blocked
+	}
+	found := false
+	for _, q := range Queues() {
+		if q.Name == "async" && q.Capacity == 16 && q.Depth >= 2 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected the async queue in Queues(), got %+v", Queues())
+	}
+
+	close(out.release)
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for i, want := range []string{"entry 0", "entry 1", "entry 2"} {
+		if idx := strings.Index(got, want); idx < 0 {
+			t.Errorf("entry %d missing after Flush: %q", i, got)
+		}
+	}
+	if strings.Index(got, "entry 0") > strings.Index(got, "entry 2") {
+		t.Errorf("entries reordered with one worker: %q", got)
+	}
+}
+
+func TestAsync_CloseDrains(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithFormat("json"), WithOutputs(&out), WithAsync(AsyncConfig{}))
+	for i := 0; i < 100; i++ {
+		l.Infof("entry %d", i)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(out.String(), "\n"); n != 100 {
+		t.Errorf("got %d entries after Close, want 100", n)
+	}
+	for _, q := range Queues() {
+		if q.Name == "async" {
+			t.Error("the async queue is still registered after Close")
+		}
+	}
+
+	l.Info("after close") // written synchronously
+	if !strings.Contains(out.String(), "after close") {
+		t.Error("entry logged after Close was lost")
+	}
+}
+
+func TestAsync_OverflowPolicies(t *testing.T) {
+	for _, tc := range []struct {
+		policy string
+		last   string
+		want   []string
+	}{
+		{"drop_newest", `{"level":"ERROR","message":"e3"}`, []string{"e0", "e1", "e2"}},
+		{"drop_oldest", `{"level":"ERROR","message":"e3"}`, []string{"e0", "e2", "e3"}},
+		{"drop_below_level", `{"level":"DEBUG","message":"e3"}`, []string{"e0", "e1", "e2"}},
+	} {
+		t.Run(tc.policy, func(t *testing.T) {
+			out := &gatedWriter{release: make(chan struct{})}
+			a := newAsyncWriter(out, AsyncConfig{QueueSize: 2, Overflow: tc.policy})
+			defer a.close()
+			unregisterQueue(a)
+
+			_, _ = a.Write([]byte(`{"level":"INFO","message":"e0"}` + "\n"))
+			for len(a.queue) > 0 { // the worker takes e0 and blocks on the output
+				runtime.Gosched()
+			}
+			_, _ = a.Write([]byte(`{"level":"INFO","message":"e1"}` + "\n"))
+			_, _ = a.Write([]byte(`{"level":"INFO","message":"e2"}` + "\n"))
+			_, _ = a.Write([]byte(tc.last + "\n"))
+
+			if d := a.queueStats().Dropped; d != 1 {
+				t.Errorf("Dropped = %d, want 1", d)
+			}
+			close(out.release)
+			a.flush()
+			got := out.String()
+			for _, want := range tc.want {
+				if !strings.Contains(got, `"`+want+`"`) {
+					t.Errorf("%s missing from %q", want, got)
+				}
+			}
+			if n := strings.Count(got, "\n"); n != len(tc.want) {
+				t.Errorf("got %d entries, want %d: %q", n, len(tc.want), got)
+			}
+		})
+	}
+}
+
+func TestAsyncConfig_Validate(t *testing.T) {
+	err := Config{Async: &AsyncConfig{Overflow: "spill", OverflowLevel: "loud"}}.Validate()
+	for _, want := range []string{`overflow "spill"`, `unknown level "loud"`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
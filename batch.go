@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxPendingBatches bounds the items a batcher holds while sends are slow or
+// failing, as a multiple of the batch size.
+const maxPendingBatches = 10
+
+// batcher collects items and hands them to send when maxSize items are
+// pending or every interval, whichever comes first. Sends happen on the
+// batcher's goroutine, so a send retrying against a backend that is down
+// never blocks a logging call, and are serialized so batches are delivered
+// in order. Errors from sends are kept and returned by the next add, as the
+// network sinks report delivery failures on the next Write.
+type batcher[T any] struct {
+	send     func(batch []T) error
+	maxSize  int
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending []T
+	lastErr error
+
+	sendMu sync.Mutex
+
+	kick      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newBatcher[T any](maxSize int, interval time.Duration, send func([]T) error) *batcher[T] {
+	b := &batcher[T]{
+		send:     send,
+		maxSize:  maxSize,
+		interval: interval,
+		kick:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go b.loop()
+	return b
+}
+
+func (b *batcher[T]) loop() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		var err error
+		select {
+		case <-ticker.C:
+			err = b.flush()
+		case <-b.kick:
+			err = b.sendPending(true)
+		case <-b.stop:
+			return
+		}
+		if err != nil {
+			b.mu.Lock()
+			b.lastErr = err
+			b.mu.Unlock()
+		}
+	}
+}
+
+// add queues item and wakes the batcher's goroutine when a batch is full. It
+// returns the error of an earlier send, if any, or rejects item when sends
+// have fallen maxPendingBatches batches behind.
+func (b *batcher[T]) add(item T) error {
+	b.mu.Lock()
+	err := b.lastErr
+	b.lastErr = nil
+	if n := len(b.pending); n >= b.maxSize*maxPendingBatches {
+		b.mu.Unlock()
+		if err == nil {
+			err = fmt.Errorf("%d entries waiting to be sent, dropping the entry", n)
+		}
+		return err
+	}
+	b.pending = append(b.pending, item)
+	full := len(b.pending) >= b.maxSize
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.kick <- struct{}{}:
+		default:
+		}
+	}
+	return err
+}
+
+// flush sends the pending items.
+func (b *batcher[T]) flush() error {
+	return b.sendPending(false)
+}
+
+// sendPending sends the pending items, at most maxSize at a time, and
+// returns the first error. With fullOnly, a last partial batch is kept.
+func (b *batcher[T]) sendPending(fullOnly bool) error {
+	b.sendMu.Lock()
+	defer b.sendMu.Unlock()
+
+	var firstErr error
+	for {
+		b.mu.Lock()
+		n := min(len(b.pending), b.maxSize)
+		if fullOnly && n < b.maxSize {
+			n = 0
+		}
+		batch := b.pending[:n:n]
+		if b.pending = b.pending[n:]; len(b.pending) == 0 {
+			b.pending = nil
+		}
+		b.mu.Unlock()
+
+		if n == 0 {
+			return firstErr
+		}
+		if err := b.send(batch); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+}
+
+// lastError returns the most recent background send error.
+func (b *batcher[T]) lastError() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastErr
+}
+
+// close stops the background sends and sends what is pending.
+func (b *batcher[T]) close() error {
+	b.closeOnce.Do(func() { close(b.stop) })
+	<-b.done
+	return b.flush()
+}
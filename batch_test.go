@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatcher_SizeIntervalAndClose(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]int
+	sent := make(chan struct{}, 10)
+	b := newBatcher(3, 50*time.Millisecond, func(batch []int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		batches = append(batches, batch)
+		sent <- struct{}{}
+		return nil
+	})
+
+	for i := 1; i <= 4; i++ {
+		_ = b.add(i)
+	}
+	select {
+	case <-sent:
+	case <-time.After(40 * time.Millisecond):
+		t.Fatal("expected a full batch to be sent before the interval")
+	}
+	mu.Lock()
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Errorf("expected only the full batch to be sent, got %v", batches)
+	}
+	mu.Unlock()
+
+	time.Sleep(120 * time.Millisecond)
+	_ = b.add(5)
+	if err := b.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 3 || batches[1][0] != 4 || batches[2][0] != 5 {
+		t.Errorf("expected the interval and close to send the rest, got %v", batches)
+	}
+}
+
+func TestBatcher_SlowSendsDontBlockAdd(t *testing.T) {
+	release := make(chan struct{})
+	b := newBatcher(2, time.Hour, func([]int) error {
+		<-release
+		return nil
+	})
+	defer b.close()
+	defer close(release)
+
+	start := time.Now()
+	var err error
+	for i := 0; i < 2*maxPendingBatches+5 && err == nil; i++ {
+		err = b.add(i)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("add blocked on a slow send")
+	}
+	if err == nil {
+		t.Errorf("expected entries to be rejected once %d batches are pending", maxPendingBatches)
+	}
+}
+
+func TestBatcher_ReportsBackgroundErrors(t *testing.T) {
+	failure := errors.New("unavailable")
+	b := newBatcher(100, 10*time.Millisecond, func([]string) error { return failure })
+	defer b.close()
+
+	_ = b.add("a")
+	time.Sleep(50 * time.Millisecond)
+	if !errors.Is(b.lastError(), failure) {
+		t.Fatalf("expected the background error to be kept, got %v", b.lastError())
+	}
+	if err := b.add("b"); !errors.Is(err, failure) {
+		t.Errorf("expected the next add to return the error, got %v", err)
+	}
+}
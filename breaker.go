@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrSinkCircuitOpen is reported for writes skipped because the output's
+// circuit breaker is open.
+var ErrSinkCircuitOpen = errors.New("sink circuit breaker open")
+
+// circuitBreaker stops calls to a failing destination: after threshold
+// consecutive failures it opens for cooldown, then lets calls through again
+// and closes once one succeeds.
+type circuitBreaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	// warn reports the breaker opening and closing as a diagnostic, since
+	// the entry can't go through the output that is failing.
+	warn func(msg string)
+
+	mu        sync.Mutex
+	failures  int
+	open      bool
+	openUntil time.Time
+}
+
+func newCircuitBreaker(name string, threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		warn:      func(msg string) { diagnose(name, nil, "%s", msg) },
+	}
+}
+
+// allow reports whether a call may be attempted.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.open || !time.Now().Before(b.openUntil)
+}
+
+// isOpen reports whether the breaker is open, including after the cooldown
+// until a call succeeds.
+func (b *circuitBreaker) isOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+// success records a call that succeeded, closing the breaker.
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	wasOpen := b.open
+	b.failures, b.open = 0, false
+	b.mu.Unlock()
+	if wasOpen {
+		b.warn("circuit breaker closed, sends resumed")
+	}
+}
+
+// failure records a failed call, opening the breaker at the threshold and
+// extending the cooldown when the trial call after one fails.
+func (b *circuitBreaker) failure(err error) {
+	b.mu.Lock()
+	b.failures++
+	opened := !b.open && b.failures >= b.threshold
+	if opened || b.open {
+		b.open = true
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+	failures := b.failures
+	b.mu.Unlock()
+	if opened {
+		b.warn(fmt.Sprintf("circuit breaker open after %d consecutive failures, pausing sends for %s: %v", failures, b.cooldown, err))
+	}
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var warnings []string
+	b := newCircuitBreaker("kafka", 2, 20*time.Millisecond)
+	b.warn = func(msg string) { warnings = append(warnings, msg) }
+	boom := errors.New("boom")
+
+	b.failure(boom)
+	if !b.allow() {
+		t.Fatal("breaker opened before the threshold")
+	}
+	b.failure(boom)
+	if b.allow() {
+		t.Fatal("breaker still closed after the threshold")
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "open after 2 consecutive failures") {
+		t.Fatalf("unexpected warnings %q", warnings)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("breaker still open after the cooldown")
+	}
+	b.failure(boom)
+	if b.allow() {
+		t.Fatal("a failed trial call should reopen the breaker")
+	}
+	time.Sleep(30 * time.Millisecond)
+	b.success()
+	if !b.allow() || len(warnings) != 2 || !strings.Contains(warnings[1], "closed") {
+		t.Fatalf("breaker did not close: allow %v, warnings %q", b.allow(), warnings)
+	}
+}
+
+func TestKafkaLogWriter_CircuitBreaker(t *testing.T) {
+	fake := &fakeProducer{down: true}
+	w := testKafkaWriter(t, KafkaConfig{Brokers: []string{"k:9092"}, Topic: "logs", BreakerThreshold: 2, BreakerCooldownMS: 60000})
+	w.produce = fake.produce
+	w.breaker.warn = func(string) {}
+
+	for i := 0; i < 2; i++ {
+		if _, err := w.Write([]byte(`{"n":1}`)); err == nil || errors.Is(err, ErrSinkCircuitOpen) {
+			t.Fatalf("write %d: got %v, want the producer error", i, err)
+		}
+	}
+	fake.setDown(false)
+	if _, err := w.Write([]byte(`{"n":2}`)); !errors.Is(err, ErrSinkCircuitOpen) {
+		t.Fatalf("got %v, want ErrSinkCircuitOpen while the breaker is open", err)
+	}
+	if got := fake.values(); len(got) != 0 {
+		t.Errorf("produced %q while the breaker was open", got)
+	}
+}
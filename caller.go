@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// addCaller adds the file:line and function of the logging call to a
+// structured entry. The stack is walked past this package, the log and
+// log/slog packages, klog and the logr adapter, so the result doesn't depend
+// on which wrapper was called. Fields already set, e.g. by the klog bridge,
+// are kept.
+func addCaller(fields map[string]interface{}) {
+	if hasCallerFields(fields) {
+		return
+	}
+	if frame, ok := callerFrame(); ok {
+		setCallerFields(fields, frame)
+	}
+}
+
+// addCallerPC is addCaller for a call site already known by its program
+// counter, such as a slog.Record's PC.
+func addCallerPC(fields map[string]interface{}, pc uintptr) {
+	if hasCallerFields(fields) {
+		return
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.Function != "" {
+		setCallerFields(fields, frame)
+	}
+}
+
+func hasCallerFields(fields map[string]interface{}) bool {
+	_, hasCaller := fields["caller"]
+	_, hasFunction := fields["function"]
+	return hasCaller && hasFunction
+}
+
+func setCallerFields(fields map[string]interface{}, frame runtime.Frame) {
+	if _, ok := fields["caller"]; !ok {
+		fields["caller"] = filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+	}
+	if _, ok := fields["function"]; !ok {
+		fields["function"] = frame.Function
+	}
+}
+
+// callerSkipPrefixes are the logging front ends walked past, besides this
+// package.
+var callerSkipPrefixes = []string{
+	"github.com/go-logr/logr.",
+	"log/slog.",
+	"k8s.io/klog",
+}
+
+func callerFrame() (runtime.Frame, bool) {
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !isLoggerFrame(frame) && !hasAnyPrefix(frame.Function, callerSkipPrefixes) {
+			return frame, true
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
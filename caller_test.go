@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// thisLine returns the line it is called from.
+func thisLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+func TestWithCaller(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithFormat("json"), WithLevel("trace"), WithCaller(), WithOutputs(&out))
+	ctx := context.Background()
+
+	// Each call logs and reports its line from the same source line.
+	calls := []struct {
+		name string
+		log  func() int
+	}{
+		{"Info", func() int { l.Info("plain"); return thisLine() }},
+		{"Warningf", func() int { l.Warningf("formatted %d", 1); return thisLine() }},
+		{"ErrorCtx", func() int { l.ErrorCtx(ctx, "with context"); return thisLine() }},
+		{"InfofMap", func() int { l.InfofMap(ctx, map[string]interface{}{"message": "map"}); return thisLine() }},
+		{"Logf", func() int { l.Logf(LevelDebug, ctx, nil, "logf %s", "x"); return thisLine() }},
+		{"With", func() int { l.With(map[string]interface{}{"k": "v"}).Info("child"); return thisLine() }},
+		{"InfoOnce", func() int { l.InfoOnce("caller-test", "once"); return thisLine() }},
+		{"Logr", func() int { l.Logr().Info("logr"); return thisLine() }},
+		{"StdLogger", func() int { l.StdLogger(LevelWarn).Print("std"); return thisLine() }},
+		{"Slog", func() int { slog.New(l.SlogHandler()).Info("slog"); return thisLine() }},
+	}
+	for _, c := range calls {
+		out.Reset()
+		want := "caller_test.go:" + strconv.Itoa(c.log())
+
+		entries := decodeLogLines(t, &out)
+		if len(entries) != 1 {
+			t.Fatalf("%s: got %d entries, want 1", c.name, len(entries))
+		}
+		if entries[0]["caller"] != want {
+			t.Errorf("%s: caller = %v, want %s", c.name, entries[0]["caller"], want)
+		}
+		if fn, _ := entries[0]["function"].(string); !strings.HasPrefix(fn, "github.com/paaavkata/go-logger.TestWithCaller.") {
+			t.Errorf("%s: function = %q, want the test's closure", c.name, fn)
+		}
+	}
+}
+
+func TestWithCaller_PackageFunctions(t *testing.T) {
+	var out bytes.Buffer
+	InitWithOptions(WithFormat("logfmt"), WithCaller(), WithOutputs(&out))
+
+	Info("package level")
+	want := "caller=caller_test.go:" + strconv.Itoa(thisLine()-1)
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("entry %q does not contain %s", out.String(), want)
+	}
+}
+
+func TestWithCaller_Disabled(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithFormat("json"), WithOutputs(&out))
+	l.InfofMap(nil, map[string]interface{}{"message": "no caller"})
+	l.Info("no caller")
+	for _, e := range decodeLogLines(t, &out) {
+		if _, ok := e["caller"]; ok {
+			t.Errorf("caller added without WithCaller: %v", e)
+		}
+	}
+}
+
+func TestWithCaller_KeepsExistingField(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithFormat("json"), WithCaller(), WithOutputs(&out))
+	l.InfofMap(nil, map[string]interface{}{"message": "bridged", "caller": "klog.go:12"})
+	if e := decodeLogLines(t, &out)[0]; e["caller"] != "klog.go:12" {
+		t.Errorf("caller = %v, want the field set by the call", e["caller"])
+	}
+}
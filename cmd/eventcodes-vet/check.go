@@ -0,0 +1,99 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	logger "github.com/paaavkata/go-logger"
+)
+
+// eventCodeUsage is a literal event code found in source code.
+type eventCodeUsage struct {
+	Code     string
+	Position token.Position
+}
+
+// findUnknownEventCodes walks the Go files under root and returns every
+// string literal used as an event code that is not in the registry: the code
+// argument of LogEvent calls and "event_id"/"error_code" map literal values.
+func findUnknownEventCodes(root string) ([]eventCodeUsage, error) {
+	var unknown []eventCodeUsage
+	fset := token.NewFileSet()
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		for _, usage := range eventCodeLiterals(fset, file) {
+			if _, ok := logger.LookupEventCode(usage.Code); !ok {
+				unknown = append(unknown, usage)
+			}
+		}
+		return nil
+	})
+	return unknown, err
+}
+
+func eventCodeLiterals(fset *token.FileSet, file *ast.File) []eventCodeUsage {
+	var found []eventCodeUsage
+	record := func(expr ast.Expr) {
+		lit, ok := expr.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return
+		}
+		code, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return
+		}
+		found = append(found, eventCodeUsage{Code: code, Position: fset.Position(lit.Pos())})
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.CallExpr:
+			if name := calleeName(node.Fun); name == "LogEvent" && len(node.Args) >= 2 {
+				record(node.Args[1])
+			}
+		case *ast.KeyValueExpr:
+			key, ok := node.Key.(*ast.BasicLit)
+			if !ok || key.Kind != token.STRING {
+				return true
+			}
+			if k, _ := strconv.Unquote(key.Value); k == "event_id" || k == "error_code" {
+				record(node.Value)
+			}
+		}
+		return true
+	})
+	return found
+}
+
+func calleeName(fun ast.Expr) string {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f.Name
+	case *ast.SelectorExpr:
+		return f.Sel.Name
+	}
+	return ""
+}
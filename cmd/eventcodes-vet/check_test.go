@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	logger "github.com/paaavkata/go-logger"
+)
+
+func TestFindUnknownEventCodes(t *testing.T) {
+	_ = logger.RegisterEventCodes(logger.EventCode{Code: "DB-001"})
+
+	dir := t.TempDir()
+	src := `package app
+
+import "github.com/paaavkata/go-logger"
+
+func f() {
+	logger.LogEvent(nil, "DB-001", nil)
+	logger.LogEvent(nil, "DB-404", nil)
+	logger.ErrorfMap(nil, map[string]interface{}{"error_code": "NET-7"})
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "app.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	unknown, err := findUnknownEventCodes(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unknown) != 2 || unknown[0].Code != "DB-404" || unknown[1].Code != "NET-7" {
+		t.Fatalf("unexpected unknown codes: %+v", unknown)
+	}
+	if unknown[0].Position.Line != 7 {
+		t.Errorf("expected position of the literal, got %v", unknown[0].Position)
+	}
+}
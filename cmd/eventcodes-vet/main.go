@@ -0,0 +1,45 @@
+// Command eventcodes-vet reports event codes used in Go source that are
+// missing from an event code registry file.
+//
+//	eventcodes-vet -codes event_codes.json ./...
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	logger "github.com/paaavkata/go-logger"
+)
+
+func main() {
+	codesFile := flag.String("codes", "event_codes.json", "JSON file with the registered event codes")
+	flag.Parse()
+
+	if err := logger.LoadEventCodes(*codesFile); err != nil {
+		fmt.Fprintf(os.Stderr, "eventcodes-vet: %v\n", err)
+		os.Exit(2)
+	}
+
+	roots := flag.Args()
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+
+	failed := false
+	for _, root := range roots {
+		unknown, err := findUnknownEventCodes(strings.TrimSuffix(root, "/..."))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "eventcodes-vet: %v\n", err)
+			os.Exit(2)
+		}
+		for _, u := range unknown {
+			fmt.Printf("%s: unknown event code %q\n", u.Position, u.Code)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"time"
+)
+
+// Cmd is an *exec.Cmd whose stdout and stderr stream into log entries and
+// whose Start, Wait and Run log the command start, exit code and duration.
+type Cmd struct {
+	*exec.Cmd
+
+	ctx     context.Context
+	name    string
+	stdout  io.WriteCloser
+	stderr  io.WriteCloser
+	started time.Time
+}
+
+// Command is like exec.CommandContext, but stdout lines are logged at INFO
+// and stderr lines at WARNING, tagged with the command name and stream.
+func Command(ctx context.Context, name string, args ...string) *Cmd {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	c := &Cmd{
+		Cmd:  exec.CommandContext(ctx, name, args...),
+		ctx:  ctx,
+		name: name,
+	}
+	c.stdout = NewWriter(LevelInfo, WriterOptions{
+		Context: ctx,
+		Fields:  map[string]interface{}{"command": name, "stream": "stdout"},
+	})
+	c.stderr = NewWriter(LevelWarn, WriterOptions{
+		Context: ctx,
+		Fields:  map[string]interface{}{"command": name, "stream": "stderr"},
+	})
+	c.Cmd.Stdout = c.stdout
+	c.Cmd.Stderr = c.stderr
+	return c
+}
+
+// Start logs the start entry and starts the command.
+func (c *Cmd) Start() error {
+	c.started = time.Now()
+	logWithMap(LevelInfo, c.ctx, map[string]interface{}{
+		"message": c.name + " started",
+		"command": c.name,
+		"args":    c.Args[1:],
+	})
+
+	if err := c.Cmd.Start(); err != nil {
+		c.logExit(err)
+		return err
+	}
+	return nil
+}
+
+// Wait waits for the command, flushes its captured output and logs the exit
+// code and duration.
+func (c *Cmd) Wait() error {
+	err := c.Cmd.Wait()
+	c.logExit(err)
+	return err
+}
+
+// Run starts the command and waits for it to complete.
+func (c *Cmd) Run() error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+	return c.Wait()
+}
+
+func (c *Cmd) logExit(err error) {
+	_ = c.stdout.Close()
+	_ = c.stderr.Close()
+
+	fields := map[string]interface{}{
+		"command":     c.name,
+		"duration_ms": time.Since(c.started).Milliseconds(),
+	}
+
+	exitCode := -1
+	if c.ProcessState != nil {
+		exitCode = c.ProcessState.ExitCode()
+	}
+	fields["exit_code"] = exitCode
+
+	if err == nil {
+		fields["message"] = c.name + " exited"
+		logWithMap(LevelInfo, c.ctx, fields)
+		return
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		fields["message"] = c.name + " exited with non-zero status"
+	} else {
+		fields["message"] = c.name + " failed"
+	}
+	fields["error"] = err.Error()
+	logWithMap(LevelError, c.ctx, fields)
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"testing"
+)
+
+func TestCommand_StreamsOutputAndExit(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	var buf bytes.Buffer
+	Init("debug", "json", "", "", false, false, false, nil, nil, &lockedWriter{w: &buf})
+
+	err := Command(context.Background(), "sh", "-c", "echo out; echo err >&2; exit 3").Run()
+	if err == nil {
+		t.Fatalf("expected non-zero exit error")
+	}
+
+	var stdout, stderr, exit map[string]interface{}
+	for _, e := range decodeLogLines(t, &buf) {
+		switch {
+		case e["stream"] == "stdout":
+			stdout = e
+		case e["stream"] == "stderr":
+			stderr = e
+		case e["exit_code"] != nil:
+			exit = e
+		}
+	}
+
+	if stdout == nil || stdout["message"] != "out" || stdout["command"] != "sh" || stdout["level"] != "INFO" {
+		t.Errorf("unexpected stdout entry: %v", stdout)
+	}
+	if stderr == nil || stderr["message"] != "err" || stderr["level"] != "WARNING" {
+		t.Errorf("unexpected stderr entry: %v", stderr)
+	}
+	if exit == nil || exit["exit_code"] != float64(3) || exit["level"] != "ERROR" {
+		t.Errorf("unexpected exit entry: %v", exit)
+	}
+}
+
+func TestCommand_StartFailure(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	if err := Command(context.Background(), "/definitely/not/a/binary").Run(); err == nil {
+		t.Fatalf("expected start error")
+	}
+	entries := decodeLogLines(t, &buf)
+	last := entries[len(entries)-1]
+	if last["exit_code"] != float64(-1) || last["error"] == nil {
+		t.Errorf("unexpected failure entry: %v", last)
+	}
+}
@@ -0,0 +1,331 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+)
+
+// Config is a declarative alternative to the functional options. Validate it
+// before use, or let NewFromConfig and InitFromConfig do so.
+type Config struct {
+	// Level is "trace", "debug", "info", "warn" or "error". Empty logs every
+	// level except trace.
+	Level string `json:"level" yaml:"level"`
+	// NamedLevels overrides Level for the loggers returned by Named, keyed by
+	// logger name.
+	NamedLevels map[string]string `json:"named_levels" yaml:"named_levels"`
+	// Format is "json", "logfmt", "gcp" or "text". Empty means text.
+	Format string `json:"format" yaml:"format"`
+
+	Service     string `json:"service" yaml:"service"`
+	Environment string `json:"environment" yaml:"environment"`
+
+	// MaxEntryBytes truncates entries that encode to more bytes, marking
+	// them with truncated=true. 0 means no limit.
+	MaxEntryBytes int `json:"max_entry_bytes" yaml:"max_entry_bytes"`
+	// Caller adds caller and function fields to structured entries.
+	Caller bool `json:"caller" yaml:"caller"`
+
+	Stdout bool `json:"stdout" yaml:"stdout"`
+	// File enables the rotating file output when non-nil.
+	File *FileConfig `json:"file" yaml:"file"`
+	// Kafka enables the Kafka output when non-nil.
+	Kafka *KafkaConfig `json:"kafka" yaml:"kafka"`
+	// Async enables asynchronous logging when non-nil.
+	Async *AsyncConfig `json:"async" yaml:"async"`
+	// Sampling caps how often the same entry is written when non-nil.
+	Sampling *SamplingConfig `json:"sampling" yaml:"sampling"`
+	// Throttle suppresses and summarizes repeated entries when non-nil.
+	Throttle *ThrottleConfig `json:"throttle" yaml:"throttle"`
+	// Redact masks sensitive fields and values when non-nil.
+	Redact *RedactConfig `json:"redact" yaml:"redact"`
+	// DropRules are filter expressions whose matching entries are discarded,
+	// as with SetDropRules. They apply to every logger, so they are installed
+	// by InitFromConfig and WatchConfigFile but not by NewFromConfig; when
+	// unset, rules installed by other means are kept.
+	DropRules []string `json:"drop_rules" yaml:"drop_rules"`
+	// Outputs are additional writers that receive every entry.
+	Outputs []io.Writer `json:"-" yaml:"-"`
+	// Scrubbers rewrite every entry's fields before redaction and encoding.
+	Scrubbers []Scrubber `json:"-" yaml:"-"`
+}
+
+// FileConfig configures the rotating file output.
+type FileConfig struct {
+	// Path defaults to app.log.
+	Path string `json:"path" yaml:"path"`
+	// MaxSizeMB is the size at which the file is rotated. Defaults to 10.
+	MaxSizeMB int `json:"max_size_mb" yaml:"max_size_mb"`
+	// MaxBackups is the number of rotated files kept. Defaults to 5.
+	MaxBackups int `json:"max_backups" yaml:"max_backups"`
+	// MaxAgeDays is how long rotated files are kept. Defaults to 28.
+	MaxAgeDays int `json:"max_age_days" yaml:"max_age_days"`
+	// DisableCompression keeps rotated files uncompressed.
+	DisableCompression bool `json:"disable_compression" yaml:"disable_compression"`
+}
+
+// KafkaConfig configures the Kafka output.
+type KafkaConfig struct {
+	Brokers []string `json:"brokers" yaml:"brokers"`
+	Topic   string   `json:"topic" yaml:"topic"`
+	// LevelTopics routes entries of the listed levels to their own topic,
+	// e.g. {"error": "app-errors", "fatal": "app-errors"}; other levels go
+	// to Topic.
+	LevelTopics map[string]string `json:"level_topics" yaml:"level_topics"`
+	// Acks is the acknowledgement required from the brokers: "none" (the
+	// default, fire and forget), "one" (the partition leader) or "all" (every
+	// in-sync replica).
+	Acks string `json:"acks" yaml:"acks"`
+	// MaxRetries is how many times a failed write is retried. Defaults to 9.
+	MaxRetries int `json:"max_retries" yaml:"max_retries"`
+	// WriteTimeoutMS bounds each write to the brokers. Defaults to 10000.
+	WriteTimeoutMS int `json:"write_timeout_ms" yaml:"write_timeout_ms"`
+	// BatchSize, BatchBytes and BatchTimeoutMS control batching: a batch is
+	// sent when it holds BatchSize messages (default 100) or BatchBytes bytes
+	// (default 1MB), or BatchTimeoutMS after its first message (default 1000).
+	BatchSize      int   `json:"batch_size" yaml:"batch_size"`
+	BatchBytes     int64 `json:"batch_bytes" yaml:"batch_bytes"`
+	BatchTimeoutMS int   `json:"batch_timeout_ms" yaml:"batch_timeout_ms"`
+	// Compression is the codec batches are compressed with: "gzip",
+	// "snappy", "lz4" or "zstd". Defaults to none.
+	Compression string `json:"compression" yaml:"compression"`
+	// PartitionKey selects the message key, so that related entries land on
+	// the same partition and keep their order: "service", "trace_id" or
+	// "host". Keyed messages are spread by a hash of the key; entries without
+	// one (no trace_id, say) and the default "" spread messages by load.
+	PartitionKey string `json:"partition_key" yaml:"partition_key"`
+	// SpoolDir enables a disk fallback: messages the brokers don't accept
+	// are appended to a file in this directory and replayed, in order, once
+	// the brokers recover. Replays are at least once: a restart may resend
+	// messages that were already delivered. SpoolMaxSizeMB bounds the file
+	// (default 100); entries that don't fit are dropped.
+	SpoolDir       string `json:"spool_dir" yaml:"spool_dir"`
+	SpoolMaxSizeMB int    `json:"spool_max_size_mb" yaml:"spool_max_size_mb"`
+	// BreakerThreshold enables a circuit breaker: after that many consecutive
+	// failed writes, the Kafka output stops trying for BreakerCooldownMS
+	// (default 30000) and fails fast, or spools, while the other outputs
+	// keep receiving entries. 0 disables it.
+	BreakerThreshold  int `json:"breaker_threshold" yaml:"breaker_threshold"`
+	BreakerCooldownMS int `json:"breaker_cooldown_ms" yaml:"breaker_cooldown_ms"`
+	// TLS encrypts the connections to the brokers; nil connects in plain
+	// text.
+	TLS *KafkaTLSConfig `json:"tls" yaml:"tls"`
+	// SASL authenticates the producer; nil connects unauthenticated.
+	SASL *KafkaSASLConfig `json:"sasl" yaml:"sasl"`
+}
+
+// KafkaSASLConfig configures SASL authentication for the Kafka output.
+type KafkaSASLConfig struct {
+	// Mechanism is "PLAIN", "SCRAM-SHA-256" or "SCRAM-SHA-512". PLAIN sends
+	// the password as is, so pair it with TLS.
+	Mechanism string `json:"mechanism" yaml:"mechanism"`
+	Username  string `json:"username" yaml:"username"`
+	Password  string `json:"password" yaml:"password"`
+}
+
+// KafkaTLSConfig configures TLS for the Kafka output.
+type KafkaTLSConfig struct {
+	// CAFile is a PEM bundle of the authorities that sign the broker
+	// certificates; the system roots are used when it is empty.
+	CAFile string `json:"ca_file" yaml:"ca_file"`
+	// CertFile and KeyFile are a PEM client certificate and key, for brokers
+	// that require mutual TLS.
+	CertFile string `json:"cert_file" yaml:"cert_file"`
+	KeyFile  string `json:"key_file" yaml:"key_file"`
+	// InsecureSkipVerify disables verification of the broker certificates.
+	// Use it only against test clusters.
+	InsecureSkipVerify bool `json:"insecure_skip_verify" yaml:"insecure_skip_verify"`
+}
+
+func (fc FileConfig) withDefaults() FileConfig {
+	if fc.Path == "" {
+		fc.Path = "app.log"
+	}
+	if fc.MaxSizeMB == 0 {
+		fc.MaxSizeMB = 10
+	}
+	if fc.MaxBackups == 0 {
+		fc.MaxBackups = 5
+	}
+	if fc.MaxAgeDays == 0 {
+		fc.MaxAgeDays = 28
+	}
+	return fc
+}
+
+// Validate reports every problem with c in a single error.
+func (c Config) Validate() error {
+	var errs []error
+
+	if err := checkLevelName(c.Level); err != nil {
+		errs = append(errs, err)
+	}
+	for name, level := range c.NamedLevels {
+		if err := checkLevelName(level); err != nil {
+			errs = append(errs, fmt.Errorf("logger %q: %w", name, err))
+		}
+	}
+
+	switch c.Format {
+	case "", "json", "logfmt", "gcp", "text":
+	default:
+		errs = append(errs, fmt.Errorf("unknown format %q (want json, logfmt, gcp or text)", c.Format))
+	}
+
+	if c.MaxEntryBytes < 0 {
+		errs = append(errs, errors.New("max_entry_bytes must not be negative"))
+	}
+
+	if f := c.File; f != nil {
+		if f.MaxSizeMB < 0 || f.MaxBackups < 0 || f.MaxAgeDays < 0 {
+			errs = append(errs, errors.New("file rotation settings must not be negative"))
+		}
+	}
+
+	if k := c.Kafka; k != nil {
+		if len(k.Brokers) == 0 {
+			errs = append(errs, errors.New("kafka output requires at least one broker"))
+		}
+		for _, b := range k.Brokers {
+			if _, _, err := net.SplitHostPort(b); err != nil {
+				errs = append(errs, fmt.Errorf("kafka broker %q: want host:port", b))
+			}
+		}
+		if k.Topic == "" {
+			errs = append(errs, errors.New("kafka output requires a topic"))
+		}
+		if _, err := kafkaRequiredAcks(k.Acks); err != nil {
+			errs = append(errs, err)
+		}
+		if _, err := kafkaCompression(k.Compression); err != nil {
+			errs = append(errs, err)
+		}
+		for level, topic := range k.LevelTopics {
+			if _, ok := parseLevelName(level); !ok {
+				errs = append(errs, fmt.Errorf("kafka level_topics: unknown level %q", level))
+			}
+			if topic == "" {
+				errs = append(errs, fmt.Errorf("kafka level_topics: empty topic for level %q", level))
+			}
+		}
+		switch k.PartitionKey {
+		case "", "service", "trace_id", "host":
+		default:
+			errs = append(errs, fmt.Errorf("unknown kafka partition_key %q (want service, trace_id or host)", k.PartitionKey))
+		}
+		if k.MaxRetries < 0 || k.WriteTimeoutMS < 0 || k.BatchSize < 0 || k.BatchBytes < 0 || k.BatchTimeoutMS < 0 || k.SpoolMaxSizeMB < 0 ||
+			k.BreakerThreshold < 0 || k.BreakerCooldownMS < 0 {
+			errs = append(errs, errors.New("kafka producer settings must not be negative"))
+		}
+		if k.TLS != nil {
+			if _, err := k.TLS.tlsConfig(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if k.SASL != nil {
+			if _, err := k.SASL.mechanism(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if c.Async != nil {
+		if err := c.Async.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if c.Sampling != nil {
+		if err := c.Sampling.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.Throttle != nil {
+		if err := c.Throttle.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.Redact != nil {
+		if err := c.Redact.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, expr := range c.DropRules {
+		if _, err := CompileFilter(expr); err != nil {
+			errs = append(errs, fmt.Errorf("drop rule %q: %w", expr, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("logger: invalid config: %w", err)
+	}
+	return nil
+}
+
+// Options converts c into the equivalent functional options.
+func (c Config) Options() []Option {
+	opts := []Option{
+		WithLevel(c.Level),
+		WithFormat(c.Format),
+		WithService(c.Service, c.Environment),
+		WithOutputs(c.Outputs...),
+		WithScrubbers(c.Scrubbers...),
+		WithMaxEntrySize(c.MaxEntryBytes),
+	}
+	for name, level := range c.NamedLevels {
+		opts = append(opts, WithNamedLevel(name, level))
+	}
+	if c.Caller {
+		opts = append(opts, WithCaller())
+	}
+	if c.Stdout {
+		opts = append(opts, WithStdout())
+	}
+	if c.File != nil {
+		opts = append(opts, WithFileConfig(*c.File))
+	}
+	if c.Kafka != nil {
+		opts = append(opts, WithKafkaConfig(*c.Kafka))
+	}
+	if c.Async != nil {
+		opts = append(opts, WithAsync(*c.Async))
+	}
+	if c.Sampling != nil {
+		opts = append(opts, WithSampling(*c.Sampling))
+	}
+	if c.Throttle != nil {
+		opts = append(opts, WithThrottle(*c.Throttle))
+	}
+	if c.Redact != nil {
+		opts = append(opts, WithRedaction(*c.Redact))
+	}
+	return opts
+}
+
+// NewFromConfig validates c and returns a Logger configured by it.
+func NewFromConfig(c Config) (*Logger, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return New(c.Options()...), nil
+}
+
+// InitFromConfig validates c and configures the package-level logger with it.
+// The current configuration is kept when c is invalid.
+func InitFromConfig(c Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	InitWithOptions(c.Options()...)
+	c.installDropRules()
+	return nil
+}
+
+// installDropRules replaces the drop rules with c's, if c sets any.
+func (c Config) installDropRules() {
+	if c.DropRules != nil {
+		_ = SetDropRules(c.DropRules...)
+	}
+}
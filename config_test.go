@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	err := Config{
+		Level:  "verbose",
+		Format: "xml",
+		File:   &FileConfig{MaxBackups: -1},
+		Kafka:  &KafkaConfig{Brokers: []string{"localhost"}},
+	}.Validate()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{`level "verbose"`, `format "xml"`, "must not be negative", `broker "localhost"`, "requires a topic"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %q", want, err)
+		}
+	}
+
+	if err := (Config{Kafka: &KafkaConfig{}}).Validate(); err == nil || !strings.Contains(err.Error(), "at least one broker") {
+		t.Errorf("expected missing brokers to be reported, got %v", err)
+	}
+	err = Config{Kafka: &KafkaConfig{Brokers: []string{"k:9092"}, Topic: "logs", Acks: "some", BatchSize: -1, Compression: "brotli", PartitionKey: "user",
+		LevelTopics: map[string]string{"critical": "alerts", "error": ""}}}.Validate()
+	for _, want := range []string{`acks "some"`, "producer settings must not be negative", `partition_key "user"`, `unknown level "critical"`, `empty topic for level "error"`, `compression "brotli"`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+	if err := (Config{Level: "INFO", Format: "json", Kafka: &KafkaConfig{Brokers: []string{"k:9092"}, Topic: "logs"}}).Validate(); err != nil {
+		t.Errorf("expected valid config, got %v", err)
+	}
+}
+
+func TestInitFromConfig(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	if err := InitFromConfig(Config{Format: "yaml"}); err == nil {
+		t.Fatal("expected InitFromConfig to reject an invalid config")
+	}
+	Debug("still configured")
+	checkLogJSON(t, buf.String(), "DEBUG", "still configured")
+
+	buf.Reset()
+	if err := InitFromConfig(Config{Level: "warn", Format: "json", Service: "api", Outputs: []io.Writer{&buf}}); err != nil {
+		t.Fatal(err)
+	}
+	defer SetMetadata("", "")
+	Info("filtered")
+	WarningfMap(nil, nil)
+	if entries := decodeLogLines(t, &buf); len(entries) != 1 || entries[0]["service"] != "api" {
+		t.Errorf("unexpected entries: %v", entries)
+	}
+}
@@ -0,0 +1,222 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// InitFromFile configures the package-level logger from a YAML or JSON file.
+// See LoadConfigFile.
+func InitFromFile(path string) error {
+	cfg, err := LoadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	return InitFromConfig(cfg)
+}
+
+// LoadConfigFile reads a Config from a .yaml, .yml or .json file and
+// validates it. Keys are the snake_case field names:
+//
+//	level: info
+//	format: json
+//	service: billing
+//	stdout: true
+//	named_levels:
+//	  db: debug
+//	file:
+//	  path: /var/log/billing.log
+//	  max_size_mb: 50
+//	kafka:
+//	  brokers: [kafka-1:9092, kafka-2:9092]
+//	  topic: logs
+//	  acks: all
+//	  max_retries: 5
+//
+// Unknown keys are reported with their path and the keys allowed there, so a
+// typo doesn't silently disable an output.
+func LoadConfigFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var (
+		raw       map[string]interface{}
+		unmarshal func([]byte, interface{}) error
+	)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		unmarshal = yaml.Unmarshal
+	case ".json":
+		unmarshal = func(b []byte, v interface{}) error {
+			dec := json.NewDecoder(bytes.NewReader(b))
+			dec.DisallowUnknownFields()
+			return dec.Decode(v)
+		}
+	default:
+		return Config{}, fmt.Errorf("logger: %s: unsupported config file extension %q (want .yaml, .yml or .json)", path, ext)
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return Config{}, fmt.Errorf("logger: %s: config file is empty", path)
+	}
+	if err := unmarshal(data, &raw); err != nil {
+		return Config{}, fmt.Errorf("logger: %s: %w", path, err)
+	}
+	if errs := checkConfigKeys("", raw, reflect.TypeOf(Config{})); len(errs) > 0 {
+		return Config{}, fmt.Errorf("logger: %s: %w", path, errors.Join(errs...))
+	}
+
+	var cfg Config
+	if err := unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("logger: %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("%w (in %s)", err, path)
+	}
+	return cfg, nil
+}
+
+// checkConfigKeys compares the keys of a decoded document with the yaml tags
+// of t, recursing into nested structs.
+func checkConfigKeys(prefix string, raw map[string]interface{}, t reflect.Type) []error {
+	fields := map[string]reflect.Type{}
+	var known []string
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = t.Field(i).Type
+		known = append(known, name)
+	}
+	sort.Strings(known)
+
+	keys := make([]string, 0, len(raw))
+	for k := range raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, k := range keys {
+		ft, ok := fields[k]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown key %q (known keys: %s)", prefix+k, strings.Join(known, ", ")))
+			continue
+		}
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if nested, ok := raw[k].(map[string]interface{}); ok && ft.Kind() == reflect.Struct {
+			errs = append(errs, checkConfigKeys(prefix+k+".", nested, ft)...)
+		}
+	}
+	return errs
+}
+
+// WatchConfigFile configures the package-level logger from the file at path
+// and reloads it whenever its modification time changes, polling every
+// interval until ctx is done. Level, named-level and metadata changes are
+// applied in place; format and output changes build the new outputs, switch
+// to them atomically and close the old ones after a grace period. Invalid
+// files are reported as warnings and leave the current configuration in
+// place. The file is the whole configuration: runtime SetLevel and
+// SetNamedLevel changes are replaced on reload.
+func WatchConfigFile(ctx context.Context, path string, interval time.Duration) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	current, err := LoadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	InitWithOptions(current.Options()...)
+	current.installDropRules()
+
+	go func() {
+		lastMod := info.ModTime()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				info, err := os.Stat(path)
+				if err != nil || info.ModTime().Equal(lastMod) {
+					continue
+				}
+				lastMod = info.ModTime()
+				next, err := LoadConfigFile(path)
+				if err != nil {
+					logWithMap(LevelWarn, nil, map[string]interface{}{
+						"message": "failed to reload log configuration",
+						"path":    path,
+						"error":   err.Error(),
+					})
+					continue
+				}
+				reopened := applyConfig(current, next)
+				current = next
+				logWithMap(LevelInfo, nil, map[string]interface{}{
+					"message":          "log configuration reloaded",
+					"path":             path,
+					"outputs_reopened": reopened,
+				})
+			}
+		}
+	}()
+	return nil
+}
+
+// applyConfig switches the package-level logger from prev to next and
+// reports whether the outputs had to be rebuilt.
+func applyConfig(prev, next Config) bool {
+	if !reflect.DeepEqual(prev.DropRules, next.DropRules) {
+		// Both have been validated. Removing the key removes the rules.
+		_ = SetDropRules(next.DropRules...)
+	}
+	if sameOutputs(prev, next) {
+		std.update(func(st *loggerState) {
+			st.level = next.Level
+			st.namedLevels = next.NamedLevels
+			st.service = next.Service
+			st.environment = next.Environment
+			st.maxEntryBytes = next.MaxEntryBytes
+			st.caller = next.Caller
+			if !reflect.DeepEqual(prev.Sampling, next.Sampling) {
+				st.sampler = newSampler(next.Sampling)
+			}
+			if !reflect.DeepEqual(prev.Throttle, next.Throttle) {
+				st.throttle = newThrottle(next.Throttle, std.writeThrottleSummary)
+			}
+			if !reflect.DeepEqual(prev.Redact, next.Redact) {
+				st.redactor = mustRedactor(next.Redact)
+			}
+		})
+		return false
+	}
+
+	InitWithOptions(next.Options()...)
+	return true
+}
+
+func sameOutputs(a, b Config) bool {
+	return a.Format == b.Format && a.Stdout == b.Stdout &&
+		reflect.DeepEqual(a.File, b.File) && reflect.DeepEqual(a.Kafka, b.Kafka) &&
+		reflect.DeepEqual(a.Async, b.Async)
+}
@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigFile_YAMLAndJSON(t *testing.T) {
+	yamlPath := writeConfigFile(t, "log.yaml", `
+level: warn
+format: json
+service: billing
+named_levels:
+  db: debug
+file:
+  path: /tmp/billing.log
+  max_size_mb: 50
+kafka:
+  brokers: [k1:9092, k2:9092]
+  topic: logs
+`)
+	cfg, err := LoadConfigFile(yamlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != "warn" || cfg.Service != "billing" || cfg.NamedLevels["db"] != "debug" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if cfg.File.MaxSizeMB != 50 || len(cfg.Kafka.Brokers) != 2 {
+		t.Errorf("unexpected nested config: %+v %+v", cfg.File, cfg.Kafka)
+	}
+
+	jsonPath := writeConfigFile(t, "log.json", `{"level":"debug","stdout":true,"file":{"path":"app.log","disable_compression":true}}`)
+	cfg, err = LoadConfigFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != "debug" || !cfg.Stdout || !cfg.File.DisableCompression {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadConfigFile_Errors(t *testing.T) {
+	cases := map[string]struct{ name, content, want string }{
+		"unknown key":        {"a.yaml", "levle: info\n", `unknown key "levle"`},
+		"unknown nested key": {"b.json", `{"kafka":{"brokrs":["k:9092"],"topic":"t"}}`, `unknown key "kafka.brokrs" (known keys: acks, batch_bytes, batch_size, batch_timeout_ms, breaker_cooldown_ms, breaker_threshold, brokers, compression, level_topics, max_retries, partition_key, sasl, spool_dir, spool_max_size_mb, tls, topic, write_timeout_ms)`},
+		"invalid level":      {"c.yml", "level: loud\n", `unknown level "loud"`},
+		"wrong type":         {"d.yaml", "file:\n  max_size_mb: big\n", "cannot unmarshal"},
+		"extension":          {"e.toml", "level = 'info'", "unsupported config file extension"},
+		"empty":              {"f.yaml", "\n", "empty"},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadConfigFile(writeConfigFile(t, c.name, c.content))
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("expected error containing %q, got %v", c.want, err)
+			}
+		})
+	}
+}
+
+func TestWatchConfigFile_Reload(t *testing.T) {
+	prevGrace := retireGrace
+	retireGrace = 0
+	defer func() { retireGrace = prevGrace }()
+
+	dir := t.TempDir()
+	path := writeConfigFile(t, "log.yaml", "level: info\nformat: json\nfile:\n  path: "+filepath.Join(dir, "a.log")+"\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := WatchConfigFile(ctx, path, 5*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor := func(cond func() bool) {
+		deadline := time.Now().Add(2 * time.Second)
+		for !cond() && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	rewrite := func(content string) {
+		mod := time.Now().Add(time.Second)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_ = os.Chtimes(path, mod, mod)
+	}
+
+	firstFanout := std.load().fanout
+	rewrite("level: debug\nformat: json\nfile:\n  path: " + filepath.Join(dir, "a.log") + "\n")
+	waitFor(func() bool { return GetLevel() == "debug" })
+	if GetLevel() != "debug" || std.load().fanout != firstFanout {
+		t.Fatalf("expected a level-only change to keep the outputs, level=%q", GetLevel())
+	}
+
+	rewrite("level: debug\nformat: json\nfile:\n  path: " + filepath.Join(dir, "b.log") + "\n")
+	waitFor(func() bool { return std.load().fanout != firstFanout })
+	Info("to the new file")
+	_ = Flush()
+	waitFor(func() bool {
+		data, _ := os.ReadFile(filepath.Join(dir, "b.log"))
+		return strings.Contains(string(data), "to the new file")
+	})
+	if data, _ := os.ReadFile(filepath.Join(dir, "b.log")); !strings.Contains(string(data), "to the new file") {
+		t.Errorf("expected entries in the new file, got %q", data)
+	}
+
+	rewrite("level: [nope]\n")
+	time.Sleep(30 * time.Millisecond)
+	if GetLevel() != "debug" {
+		t.Errorf("expected an invalid file to keep the configuration")
+	}
+	cancel()
+	_ = Close()
+}
+
+func TestConfigFile_DropRules(t *testing.T) {
+	defer SetDropRules()
+	path := writeConfigFile(t, "drop.yaml", "level: info\ndrop_rules:\n  - fields.path == \"/healthz\"\n")
+	cfg, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := InitFromConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := DropRules(); len(got) != 1 || got[0] != `fields.path == "/healthz"` {
+		t.Fatalf("expected the file's drop rule, got %v", got)
+	}
+
+	next := cfg
+	next.DropRules = []string{`message == "noisy"`, `level == "DEBUG"`}
+	applyConfig(cfg, next)
+	if got := DropRules(); len(got) != 2 {
+		t.Errorf("expected a reload to replace the drop rules, got %v", got)
+	}
+	last := next
+	last.DropRules = nil
+	applyConfig(next, last)
+	if got := DropRules(); len(got) != 0 {
+		t.Errorf("expected removing drop_rules to clear them, got %v", got)
+	}
+
+	if _, err := LoadConfigFile(writeConfigFile(t, "bad.yaml", "drop_rules: [\"level ==\"]\n")); err == nil || !strings.Contains(err.Error(), "drop rule") {
+		t.Errorf("expected an invalid drop rule to fail validation, got %v", err)
+	}
+	_ = Close()
+}
@@ -0,0 +1,216 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ContextExtractor maps values carried by a context, such as typed keys, an
+// OpenTelemetry span context or a request ID, to log fields.
+type ContextExtractor func(ctx context.Context) map[string]interface{}
+
+var (
+	contextExtractorsMu sync.Mutex
+	contextExtractors   atomic.Pointer[[]ContextExtractor]
+)
+
+// RegisterContextExtractor adds fn to the extractors run for every entry
+// logged with a non-nil context. Its fields are added after the built-in
+// ones (trace_id, worker_id, profile labels) and override them. fn must be
+// cheap and safe for concurrent use; it may return nil.
+//
+//	logger.RegisterContextExtractor(func(ctx context.Context) map[string]interface{} {
+//		sc := trace.SpanContextFromContext(ctx)
+//		if !sc.IsValid() {
+//			return nil
+//		}
+//		return map[string]interface{}{"trace_id": sc.TraceID().String(), "span_id": sc.SpanID().String()}
+//	})
+func RegisterContextExtractor(fn ContextExtractor) {
+	contextExtractorsMu.Lock()
+	defer contextExtractorsMu.Unlock()
+
+	var next []ContextExtractor
+	if cur := contextExtractors.Load(); cur != nil {
+		next = append(next, *cur...)
+	}
+	next = append(next, fn)
+	contextExtractors.Store(&next)
+}
+
+func resetContextExtractors() {
+	contextExtractorsMu.Lock()
+	defer contextExtractorsMu.Unlock()
+	contextExtractors.Store(nil)
+}
+
+// InfoCtx is Info with a context, like the other *Ctx functions: the entry
+// carries the correlation fields found in ctx (trace_id, worker_id, profile
+// labels and those of registered extractors). Per-request debug overrides
+// made with WithDebug are honoured. JSON entries are written as structured
+// entries; text entries get the fields appended as key=value pairs.
+func InfoCtx(ctx context.Context, msg string) {
+	if std.shouldLogCtx(ctx, LevelInfo) {
+		std.logPlain(ctx, LevelInfo, msg)
+	}
+}
+func WarningCtx(ctx context.Context, msg string) {
+	if std.shouldLogCtx(ctx, LevelWarn) {
+		std.logPlain(ctx, LevelWarn, msg)
+	}
+}
+func ErrorCtx(ctx context.Context, msg string) {
+	if std.shouldLogCtx(ctx, LevelError) {
+		std.logPlain(ctx, LevelError, msg)
+	}
+}
+func DebugCtx(ctx context.Context, msg string) {
+	if std.shouldLogCtx(ctx, LevelDebug) {
+		std.logPlain(ctx, LevelDebug, msg)
+	}
+}
+func TraceCtx(ctx context.Context, msg string) {
+	if std.shouldLogCtx(ctx, LevelTrace) {
+		std.logPlain(ctx, LevelTrace, msg)
+	}
+}
+func FatalCtx(ctx context.Context, msg string) {
+	if std.shouldLogCtx(ctx, LevelFatal) {
+		std.logPlain(ctx, LevelFatal, msg)
+		std.exitFatal()
+	}
+}
+
+func InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	if std.shouldLogCtx(ctx, LevelInfo) {
+		std.logPlainf(ctx, LevelInfo, format, args)
+	}
+}
+func WarningfCtx(ctx context.Context, format string, args ...interface{}) {
+	if std.shouldLogCtx(ctx, LevelWarn) {
+		std.logPlainf(ctx, LevelWarn, format, args)
+	}
+}
+func ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	if std.shouldLogCtx(ctx, LevelError) {
+		std.logPlainf(ctx, LevelError, format, args)
+	}
+}
+func DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	if std.shouldLogCtx(ctx, LevelDebug) {
+		std.logPlainf(ctx, LevelDebug, format, args)
+	}
+}
+func TracefCtx(ctx context.Context, format string, args ...interface{}) {
+	if std.shouldLogCtx(ctx, LevelTrace) {
+		std.logPlainf(ctx, LevelTrace, format, args)
+	}
+}
+func FatalfCtx(ctx context.Context, format string, args ...interface{}) {
+	if std.shouldLogCtx(ctx, LevelFatal) {
+		std.logPlain(ctx, LevelFatal, fmt.Sprintf("FATAL: "+format, args...))
+		std.exitFatal()
+	}
+}
+
+func (l *Logger) InfoCtx(ctx context.Context, msg string) {
+	if l.shouldLogCtx(ctx, LevelInfo) {
+		l.logPlain(ctx, LevelInfo, msg)
+	}
+}
+func (l *Logger) WarningCtx(ctx context.Context, msg string) {
+	if l.shouldLogCtx(ctx, LevelWarn) {
+		l.logPlain(ctx, LevelWarn, msg)
+	}
+}
+func (l *Logger) ErrorCtx(ctx context.Context, msg string) {
+	if l.shouldLogCtx(ctx, LevelError) {
+		l.logPlain(ctx, LevelError, msg)
+	}
+}
+func (l *Logger) DebugCtx(ctx context.Context, msg string) {
+	if l.shouldLogCtx(ctx, LevelDebug) {
+		l.logPlain(ctx, LevelDebug, msg)
+	}
+}
+func (l *Logger) TraceCtx(ctx context.Context, msg string) {
+	if l.shouldLogCtx(ctx, LevelTrace) {
+		l.logPlain(ctx, LevelTrace, msg)
+	}
+}
+func (l *Logger) FatalCtx(ctx context.Context, msg string) {
+	if l.shouldLogCtx(ctx, LevelFatal) {
+		l.logPlain(ctx, LevelFatal, msg)
+		l.exitFatal()
+	}
+}
+
+func (l *Logger) InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	if l.shouldLogCtx(ctx, LevelInfo) {
+		l.logPlainf(ctx, LevelInfo, format, args)
+	}
+}
+func (l *Logger) WarningfCtx(ctx context.Context, format string, args ...interface{}) {
+	if l.shouldLogCtx(ctx, LevelWarn) {
+		l.logPlainf(ctx, LevelWarn, format, args)
+	}
+}
+func (l *Logger) ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	if l.shouldLogCtx(ctx, LevelError) {
+		l.logPlainf(ctx, LevelError, format, args)
+	}
+}
+func (l *Logger) DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	if l.shouldLogCtx(ctx, LevelDebug) {
+		l.logPlainf(ctx, LevelDebug, format, args)
+	}
+}
+func (l *Logger) TracefCtx(ctx context.Context, format string, args ...interface{}) {
+	if l.shouldLogCtx(ctx, LevelTrace) {
+		l.logPlainf(ctx, LevelTrace, format, args)
+	}
+}
+func (l *Logger) FatalfCtx(ctx context.Context, format string, args ...interface{}) {
+	if l.shouldLogCtx(ctx, LevelFatal) {
+		l.logPlain(ctx, LevelFatal, fmt.Sprintf("FATAL: "+format, args...))
+		l.exitFatal()
+	}
+}
+
+// addContextFields copies the correlation fields carried by ctx into fields.
+func addContextFields(ctx context.Context, fields map[string]interface{}) {
+	if ctx != nil {
+		if traceID := ctx.Value("trace_id"); traceID != nil {
+			fields["trace_id"] = traceID
+		}
+	}
+	addConcurrencyFields(ctx, fields)
+	addProfileLabelFields(ctx, fields)
+
+	if ctx == nil {
+		return
+	}
+	if extractors := contextExtractors.Load(); extractors != nil {
+		for _, fn := range *extractors {
+			for k, v := range fn(ctx) {
+				fields[k] = v
+			}
+		}
+	}
+}
+
+// plainFields returns the fields appended to a text-format plain entry: the
+// logger's bound fields and, when ctx is set, its correlation fields.
+func (l *Logger) plainFields(ctx context.Context) map[string]interface{} {
+	if ctx == nil {
+		return l.fields
+	}
+	fields := make(map[string]interface{}, len(l.fields)+2)
+	for k, v := range l.fields {
+		fields[k] = v
+	}
+	addContextFields(ctx, fields)
+	return fields
+}
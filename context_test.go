@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCtxVariants_CarryCorrelationFields(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "info")
+
+	ctx := WithWorkerID(context.WithValue(context.Background(), "trace_id", "t-1"), 3)
+	InfofCtx(ctx, "processing %d items", 5)
+	DebugCtx(ctx, "filtered")
+	DebugCtx(WithDebug(ctx), "forced by request")
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if e := entries[0]; e["message"] != "processing 5 items" || e["trace_id"] != "t-1" || e["worker_id"] != float64(3) {
+		t.Errorf("expected correlation fields, got %v", e)
+	}
+	if e := entries[1]; e["level"] != "DEBUG" || e["message"] != "forced by request" {
+		t.Errorf("expected debug override to apply, got %v", e)
+	}
+}
+
+func TestCtxVariants_TextFormat(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "text", "debug")
+
+	WarningCtx(context.WithValue(context.Background(), "trace_id", "t-2"), "slow")
+	ErrorCtx(nil, "no context")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !strings.HasSuffix(lines[0], "slow trace_id=t-2") || !strings.Contains(lines[0], "context_test.go") {
+		t.Errorf("unexpected text entry: %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "no context") {
+		t.Errorf("unexpected text entry: %q", lines[1])
+	}
+}
+
+func TestFatalCtx_Exits(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+	code := stubExit(t)
+
+	FatalfCtx(context.Background(), "giving up after %d tries", 3)
+
+	if *code != 1 {
+		t.Errorf("expected exit code 1, got %d", *code)
+	}
+	checkLogJSON(t, buf.String(), "FATAL", "giving up after 3 tries")
+}
+
+type requestIDKey struct{}
+
+func TestRegisterContextExtractor(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+	defer resetContextExtractors()
+
+	RegisterContextExtractor(func(ctx context.Context) map[string]interface{} {
+		if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+			return map[string]interface{}{"request_id": id}
+		}
+		return nil
+	})
+	RegisterContextExtractor(func(ctx context.Context) map[string]interface{} {
+		return map[string]interface{}{"trace_id": "from-extractor"}
+	})
+
+	ctx := context.WithValue(context.WithValue(context.Background(), "trace_id", "builtin"), requestIDKey{}, "req-9")
+	InfofMap(ctx, map[string]interface{}{"message": "structured"})
+	InfoCtx(ctx, "plain")
+	Info("no context")
+
+	entries := decodeLogLines(t, &buf)
+	for _, e := range entries[:2] {
+		if e["request_id"] != "req-9" || e["trace_id"] != "from-extractor" {
+			t.Errorf("expected extractor fields, got %v", e)
+		}
+	}
+	if _, ok := entries[2]["request_id"]; ok {
+		t.Errorf("extractors must not run without a context")
+	}
+}
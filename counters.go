@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// levelCounters counts the entries a root Logger wrote, per level.
+type levelCounters struct {
+	m sync.Map // logLevel -> *atomic.Uint64
+}
+
+func (c *levelCounters) inc(level logLevel) {
+	v, ok := c.m.Load(level)
+	if !ok {
+		v, _ = c.m.LoadOrStore(level, new(atomic.Uint64))
+	}
+	v.(*atomic.Uint64).Add(1)
+}
+
+func (c *levelCounters) snapshot() map[logLevel]uint64 {
+	out := make(map[logLevel]uint64)
+	c.m.Range(func(k, v interface{}) bool {
+		out[k.(logLevel)] = v.(*atomic.Uint64).Load()
+		return true
+	})
+	return out
+}
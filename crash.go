@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// recentEntries keeps the last written entries for crash reports. It is
+// attached to the outputs by Init and holds nothing until KeepRecentEntries
+// gives it a capacity.
+var recentEntries = &ringBuffer{}
+
+type ringBuffer struct {
+	mu      sync.Mutex
+	entries []string
+	next    int
+	full    bool
+}
+
+// KeepRecentEntries keeps the last n written entries in memory so they can be
+// included in crash reports. Zero disables the buffer.
+func KeepRecentEntries(n int) {
+	recentEntries.resize(n)
+}
+
+// RecentEntries returns the buffered recent entries, oldest first.
+func RecentEntries() []string {
+	return recentEntries.snapshot()
+}
+
+func (r *ringBuffer) resize(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n <= 0 {
+		r.entries, r.next, r.full = nil, 0, false
+		return
+	}
+	r.entries, r.next, r.full = make([]string, n), 0, false
+}
+
+func (r *ringBuffer) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) == 0 {
+		return len(p), nil
+	}
+	r.entries[r.next] = string(p)
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+	return len(p), nil
+}
+
+func (r *ringBuffer) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]string(nil), r.entries[:r.next]...)
+	}
+	out := make([]string, 0, len(r.entries))
+	out = append(out, r.entries[r.next:]...)
+	return append(out, r.entries[:r.next]...)
+}
+
+// RecoverAndReport is meant to be deferred at the top of main (and of any
+// goroutine whose crash should be reported). On panic it logs the panic, writes
+// a crash report with the panic value, the stack, the recent entries and the
+// build info to the file at path, flushes the outputs and re-panics.
+//
+//	defer logger.RecoverAndReport("crash.log")
+func RecoverAndReport(path string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	stack := debug.Stack()
+
+	logWithMap(LevelError, nil, map[string]interface{}{
+		"message": fmt.Sprintf("unrecovered panic: %v", r),
+		"panic":   fmt.Sprint(r),
+		"stack":   CaptureStack(0),
+	})
+	if err := writeCrashReport(path, r, stack); err != nil {
+		diagnose("crash", err, "failed to write crash report")
+	}
+	_ = Flush()
+
+	panic(r)
+}
+
+func writeCrashReport(path string, value interface{}, stack []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fmt.Fprintf(f, "=== crash report %s ===\n", time.Now().Format(time.RFC3339))
+	st := std.load()
+	fmt.Fprintf(f, "service: %s\nenvironment: %s\npid: %d\n", st.service, st.environment, os.Getpid())
+	fmt.Fprintf(f, "\npanic: %v\n\n%s\n", value, stack)
+
+	if entries := RecentEntries(); len(entries) > 0 {
+		fmt.Fprintf(f, "--- last %d log entries ---\n", len(entries))
+		for _, e := range entries {
+			_, _ = f.WriteString(e)
+			if len(e) == 0 || e[len(e)-1] != '\n' {
+				_, _ = f.WriteString("\n")
+			}
+		}
+		_, _ = f.WriteString("\n")
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		fmt.Fprintf(f, "--- build info ---\n%s\n", info.String())
+	}
+	return f.Sync()
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRingBuffer_KeepsLastEntries(t *testing.T) {
+	KeepRecentEntries(2)
+	defer KeepRecentEntries(0)
+
+	for _, e := range []string{"a\n", "b\n", "c\n"} {
+		_, _ = recentEntries.Write([]byte(e))
+	}
+
+	got := RecentEntries()
+	if len(got) != 2 || got[0] != "b\n" || got[1] != "c\n" {
+		t.Errorf("expected last two entries oldest first, got %q", got)
+	}
+}
+
+func TestRecoverAndReport_WritesReportAndRepanics(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+	KeepRecentEntries(10)
+	defer KeepRecentEntries(0)
+
+	InfofMap(nil, map[string]interface{}{"event": "before_crash"})
+
+	path := filepath.Join(t.TempDir(), "crash.log")
+	func() {
+		defer func() {
+			if r := recover(); r != "kaboom" {
+				t.Errorf("expected re-panic with original value, got %v", r)
+			}
+		}()
+		defer RecoverAndReport(path)
+		panic("kaboom")
+	}()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected crash report: %v", err)
+	}
+	report := string(data)
+	for _, want := range []string{"panic: kaboom", "before_crash", "goroutine "} {
+		if !strings.Contains(report, want) {
+			t.Errorf("crash report missing %q:\n%s", want, report)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDatadogWriter_Batch(t *testing.T) {
+	got := make(chan []map[string]interface{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("DD-API-KEY") != "key" || r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var logs []map[string]interface{}
+		if err := json.NewDecoder(zr).Decode(&logs); err != nil {
+			t.Error(err)
+		}
+		got <- logs
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	w, err := NewDatadogWriter(DatadogConfig{
+		APIKey: "key", URL: srv.URL, Tags: []string{"env:prod", "team:billing"},
+		Service: "fallback", Hostname: "node-1", BatchInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte(`{"level":"ERROR","service":"billing","message":"charge failed","order":"o-1"}` + "\n"))
+	_, _ = w.Write([]byte("DEBUG: 2025/05/11 main.go:1: cache miss\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	logs := <-got
+	if len(logs) != 2 {
+		t.Fatalf("unexpected batch %v", logs)
+	}
+	first := logs[0]
+	if first["status"] != "error" || first["service"] != "billing" || first["ddsource"] != "go" ||
+		first["ddtags"] != "env:prod,team:billing" || first["hostname"] != "node-1" || first["order"] != "o-1" {
+		t.Errorf("unexpected log %v", first)
+	}
+	if logs[1]["status"] != "debug" || logs[1]["service"] != "fallback" {
+		t.Errorf("unexpected text log %v", logs[1])
+	}
+}
+
+func TestDatadogStatus(t *testing.T) {
+	for level, want := range map[logLevel]string{
+		LevelTrace: "debug",
+		LevelInfo:  "info",
+		LevelWarn:  "warning",
+		LevelError: "error",
+		LevelFatal: "critical",
+	} {
+		if got := datadogStatus(level); got != want {
+			t.Errorf("datadogStatus(%s) = %s, want %s", level, got, want)
+		}
+	}
+}
+
+func TestNewDatadogWriter_Site(t *testing.T) {
+	w, err := NewDatadogWriter(DatadogConfig{APIKey: "k", Site: "datadoghq.eu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if w.url != "https://http-intake.logs.datadoghq.eu/api/v2/logs" {
+		t.Errorf("unexpected intake URL %s", w.url)
+	}
+	if _, err := NewDatadogWriter(DatadogConfig{}); err == nil {
+		t.Errorf("expected an error without an API key")
+	}
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrSinkTimeout is reported for a sink write that exceeded its deadline.
+var ErrSinkTimeout = errors.New("sink write deadline exceeded")
+
+var (
+	sinkTimeoutsMu     sync.RWMutex
+	sinkTimeouts       = map[string]time.Duration{}
+	defaultSinkTimeout time.Duration
+)
+
+// SetSinkWriteTimeout sets the time budget for a single write to the named
+// output ("file", "stdout", "kafka", ...). A write that exceeds it is
+// abandoned and reported as ErrSinkTimeout, so one stalled destination can't
+// hold up the others. The abandoned write keeps running in the background;
+// until it returns, further writes to that sink fail fast with the same
+// error. Zero removes the limit.
+func SetSinkWriteTimeout(sink string, d time.Duration) {
+	sinkTimeoutsMu.Lock()
+	if d > 0 {
+		sinkTimeouts[sink] = d
+	} else {
+		delete(sinkTimeouts, sink)
+	}
+	sinkTimeoutsMu.Unlock()
+	applySinkTimeouts()
+}
+
+// SetDefaultSinkWriteTimeout sets the write budget for outputs without a
+// SetSinkWriteTimeout of their own.
+func SetDefaultSinkWriteTimeout(d time.Duration) {
+	sinkTimeoutsMu.Lock()
+	defaultSinkTimeout = d
+	sinkTimeoutsMu.Unlock()
+	applySinkTimeouts()
+}
+
+func sinkTimeoutFor(name string) time.Duration {
+	sinkTimeoutsMu.RLock()
+	defer sinkTimeoutsMu.RUnlock()
+	if d, ok := sinkTimeouts[name]; ok {
+		return d
+	}
+	return defaultSinkTimeout
+}
+
+func applySinkTimeouts() {
+	f := std.load().fanout
+	if f == nil {
+		return
+	}
+	for _, s := range f.sinks {
+		s.timeout.Store(int64(sinkTimeoutFor(s.name)))
+	}
+}
+
+// writeWithDeadline runs the write on a separate goroutine and gives up after
+// timeout. At most one write per sink is in flight, so a hung destination
+// costs a single goroutine and is never written to concurrently.
+func (s *sinkWriter) writeWithDeadline(p []byte, timeout time.Duration) (int, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case s.inflight <- struct{}{}:
+	case <-timer.C:
+		return 0, ErrSinkTimeout
+	}
+
+	type result struct {
+		n   int
+		err error
+	}
+	done := make(chan result, 1)
+	buf := append([]byte(nil), p...)
+	go func() {
+		defer func() { <-s.inflight }()
+		n, err := s.write(buf)
+		done <- result{n, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.n, r.err
+	case <-timer.C:
+		return 0, ErrSinkTimeout
+	}
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSinkWriteTimeout_AbandonsStalledSink(t *testing.T) {
+	SetSinkWriteTimeout("kafka", 20*time.Millisecond)
+	defer SetSinkWriteTimeout("kafka", 0)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	var stdout bytes.Buffer
+	f := newFanout([]*sinkWriter{
+		newSinkWriter("kafka", blockingWriter{release}),
+		newSinkWriter("stdout", &stdout),
+	})
+
+	start := time.Now()
+	_, err := f.Write([]byte("one\n"))
+	_, err2 := f.Write([]byte("two\n"))
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, ErrSinkTimeout) || !errors.Is(err2, ErrSinkTimeout) {
+		t.Fatalf("expected timeouts for the stalled sink, got %v / %v", err, err2)
+	}
+	if elapsed > time.Second {
+		t.Errorf("writes must not wait for the stalled sink, took %v", elapsed)
+	}
+	if stdout.String() != "one\ntwo\n" {
+		t.Errorf("healthy sink must still receive entries, got %q", stdout.String())
+	}
+}
+
+func TestSinkWriteTimeout_FastWritesPass(t *testing.T) {
+	SetDefaultSinkWriteTimeout(time.Second)
+	defer SetDefaultSinkWriteTimeout(0)
+
+	var buf bytes.Buffer
+	s := newSinkWriter("file", &buf)
+	if _, err := s.Write([]byte("ok\n")); err != nil || buf.String() != "ok\n" {
+		t.Errorf("expected write within the deadline to succeed, got %v %q", err, buf.String())
+	}
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+)
+
+// DebugHeader is the request header checked by DebugMiddleware.
+const DebugHeader = "X-Debug-Log"
+
+// WithDebug returns a context for which context-aware log calls emit every
+// level, regardless of the global level.
+func WithDebug(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, debugOverrideKey, true)
+}
+
+// DebugEnabled reports whether ctx was marked with WithDebug.
+func DebugEnabled(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	enabled, _ := ctx.Value(debugOverrideKey).(bool)
+	return enabled
+}
+
+// DebugMiddleware enables debug output for a single request when it carries
+// the X-Debug-Log header with the given token. Only entries logged with that
+// request's context bypass the global level. An empty token disables the
+// middleware.
+func DebugMiddleware(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" {
+			if got := r.Header.Get(DebugHeader); got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+				r = r.WithContext(WithDebug(r.Context()))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func shouldLogCtx(ctx context.Context, level logLevel) bool {
+	return std.shouldLogCtx(ctx, level)
+}
+
+func (l *Logger) shouldLogCtx(ctx context.Context, level logLevel) bool {
+	return DebugEnabled(ctx) || l.shouldLog(level)
+}
@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDebugMiddleware_EnablesDebugForRequest(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "error")
+
+	handler := DebugMiddleware("s3cret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		DebugfMap(r.Context(), map[string]interface{}{"message": "handler detail", "path": r.URL.Path})
+	}))
+
+	plain := httptest.NewRequest(http.MethodGet, "/users", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), plain)
+
+	wrongToken := httptest.NewRequest(http.MethodGet, "/users", nil)
+	wrongToken.Header.Set(DebugHeader, "guess")
+	handler.ServeHTTP(httptest.NewRecorder(), wrongToken)
+
+	if buf.Len() != 0 {
+		t.Fatalf("debug entries must stay filtered without a valid token, got %s", buf.String())
+	}
+
+	debugged := httptest.NewRequest(http.MethodGet, "/users", nil)
+	debugged.Header.Set(DebugHeader, "s3cret")
+	handler.ServeHTTP(httptest.NewRecorder(), debugged)
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 1 || entries[0]["level"] != "DEBUG" {
+		t.Fatalf("expected the debug entry for the flagged request, got %v", entries)
+	}
+}
+
+func TestDebugOverride_DoesNotLeakToOtherCalls(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "error")
+
+	DebugfMap(WithDebug(nil), map[string]interface{}{"event": "scoped"})
+	DebugfMap(nil, map[string]interface{}{"event": "global"})
+	Debug("plain")
+
+	if entries := decodeLogLines(t, &buf); len(entries) != 1 || entries[0]["event"] != "scoped" {
+		t.Errorf("expected only the scoped entry, got %v", entries)
+	}
+}
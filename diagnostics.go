@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Diagnostic is a problem the logger reports about itself, such as an
+// output that couldn't be opened, dropped entries or a Kafka broker that
+// stopped accepting messages. Diagnostics don't go through the logger, so a
+// failing output can't hide them or make the logger recurse into itself.
+type Diagnostic struct {
+	Time time.Time
+	// Component is the part of the logger reporting: "kafka", "async",
+	// "redact", "hook", "shutdown", ...
+	Component string
+	Message   string
+	// Err is the underlying error, if any.
+	Err error
+}
+
+func (d Diagnostic) String() string {
+	s := "logger: " + d.Component + ": " + d.Message
+	if d.Err != nil {
+		s += ": " + d.Err.Error()
+	}
+	return s
+}
+
+var diagnosticsHandler atomic.Pointer[func(Diagnostic)]
+
+// SetDiagnostics sends the logger's diagnostics to fn instead of stderr, e.g.
+// to count them or forward them to an operator channel; a no-op fn discards
+// them and nil restores stderr. fn may be called from any goroutine,
+// including while an entry is being written, so it must not log through the
+// logger.
+func SetDiagnostics(fn func(Diagnostic)) {
+	if fn == nil {
+		diagnosticsHandler.Store(nil)
+		return
+	}
+	diagnosticsHandler.Store(&fn)
+}
+
+// diagnose reports a diagnostic.
+func diagnose(component string, err error, format string, args ...interface{}) {
+	d := Diagnostic{Time: time.Now(), Component: component, Message: fmt.Sprintf(format, args...), Err: err}
+	if fn := diagnosticsHandler.Load(); fn != nil {
+		(*fn)(d)
+		return
+	}
+	fmt.Fprintln(os.Stderr, d.String())
+}
+
+// diagnosticInterval spaces out diagnostics for events that can happen on
+// every entry, such as drops.
+const diagnosticInterval = 10 * time.Second
+
+// diagnosticLimiter lets one diagnostic through per diagnosticInterval.
+type diagnosticLimiter struct {
+	last atomic.Int64 // unix nanoseconds
+}
+
+func (d *diagnosticLimiter) allow() bool {
+	now := time.Now().UnixNano()
+	last := d.last.Load()
+	if last != 0 && now-last < int64(diagnosticInterval) {
+		return false
+	}
+	return d.last.CompareAndSwap(last, now)
+}
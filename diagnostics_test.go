@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// captureDiagnostics collects diagnostics until the test ends.
+func captureDiagnostics(t *testing.T) func() []Diagnostic {
+	var (
+		mu  sync.Mutex
+		got []Diagnostic
+	)
+	SetDiagnostics(func(d Diagnostic) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, d)
+	})
+	t.Cleanup(func() { SetDiagnostics(nil) })
+	return func() []Diagnostic {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]Diagnostic(nil), got...)
+	}
+}
+
+func TestDiagnostics_SinkFailuresAreReportedOncePerInterval(t *testing.T) {
+	diags := captureDiagnostics(t)
+	l := New(WithOutputs(failingWriter{}))
+	for i := 0; i < 5; i++ {
+		l.Info("entry")
+	}
+	got := diags()
+	if len(got) != 1 {
+		t.Fatalf("expected one diagnostic, got %+v", got)
+	}
+	if d := got[0]; d.Component != "custom-0" || d.Err == nil || d.Err.Error() != "loki unreachable" ||
+		d.String() != "logger: custom-0: write failed (1 failures so far): loki unreachable" {
+		t.Errorf("unexpected diagnostic %+v", d)
+	}
+}
+
+func TestDiagnostics_AsyncDropsAndHooks(t *testing.T) {
+	diags := captureDiagnostics(t)
+	out := &gatedWriter{release: make(chan struct{})}
+	l := New(WithOutputs(out), WithAsync(AsyncConfig{QueueSize: 1, Overflow: "drop_newest"}))
+	l.AddHook(nil, func(*Entry) error { return errors.New("no flags") })
+	for i := 0; i < 5; i++ {
+		l.Info("entry")
+	}
+	close(out.release)
+	l.Close()
+
+	var async, hook bool
+	for _, d := range diags() {
+		async = async || d.Component == "async" && strings.Contains(d.Message, "entries dropped")
+		hook = hook || d.Component == "hook" && d.Err != nil && d.Err.Error() == "no flags"
+	}
+	if !async || !hook {
+		t.Errorf("expected async drop and hook diagnostics, got %+v", diags())
+	}
+}
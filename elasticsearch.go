@@ -0,0 +1,311 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ElasticsearchConfig configures the Elasticsearch sink.
+type ElasticsearchConfig struct {
+	// URL is the cluster's base URL ("https://es:9200").
+	URL string
+	// Index is the target index or data stream. {field} is replaced by the
+	// entry's field and {yyyy.MM.dd} style patterns (yyyy, MM, dd, HH) by
+	// its timestamp in UTC. Defaults to "logs-{service}-{yyyy.MM.dd}".
+	Index string
+	// Username and Password enable basic auth; APIKey is sent as
+	// "Authorization: ApiKey <key>".
+	Username string
+	Password string
+	APIKey   string
+	// BatchSize and BatchInterval control batching; defaults are 500 entries
+	// and 1 second.
+	BatchSize     int
+	BatchInterval time.Duration
+	// MaxRetries bounds the retries of entries rejected with 429 or of
+	// requests failing with 5xx; defaults to 5. RetryBackoff is the first
+	// delay, doubled on every retry up to 30 seconds; defaults to 200ms.
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// Client sends the requests; defaults to a client with a 30 second
+	// timeout.
+	Client *http.Client
+}
+
+const (
+	defaultElasticsearchIndex = "logs-{service}-{yyyy.MM.dd}"
+	maxElasticsearchBackoff   = 30 * time.Second
+)
+
+// ElasticsearchWriter ships entries to Elasticsearch with the _bulk API.
+// Writes do not block on the network, even while failed requests are
+// retried; delivery errors are reported by LastError and by the next Write.
+type ElasticsearchWriter struct {
+	cfg      ElasticsearchConfig
+	endpoint string
+	client   *http.Client
+	batch    *batcher[[]byte]
+}
+
+// NewElasticsearchWriter validates cfg and returns a sink that can be passed
+// to Init as an additional output.
+func NewElasticsearchWriter(cfg ElasticsearchConfig) (*ElasticsearchWriter, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("elasticsearch: URL is required")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("elasticsearch: invalid URL %q", cfg.URL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/_bulk"
+	if cfg.APIKey != "" && cfg.Username != "" {
+		return nil, errors.New("elasticsearch: set either basic auth or APIKey, not both")
+	}
+	if cfg.Index == "" {
+		cfg.Index = defaultElasticsearchIndex
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 5
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 200 * time.Millisecond
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	interval := cfg.BatchInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	w := &ElasticsearchWriter{cfg: cfg, endpoint: u.String(), client: client}
+	w.batch = newBatcher(batchSize, interval, w.ship)
+	return w, nil
+}
+
+// Write queues each line of p as one document.
+func (w *ElasticsearchWriter) Write(p []byte) (int, error) {
+	var err error
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		action, err2 := w.action(line, time.Now())
+		if err2 == nil {
+			err2 = w.batch.add(action)
+		}
+		if err2 != nil && err == nil {
+			err = err2
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("elasticsearch: %w", err)
+	}
+	return len(p), nil
+}
+
+// action returns the bulk create action and document for line. Documents get
+// an @timestamp, which data streams require; text entries become a document
+// with the line as the message.
+func (w *ElasticsearchWriter) action(line []byte, now time.Time) ([]byte, error) {
+	var doc map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if len(line) < 2 || line[0] != '{' || dec.Decode(&doc) != nil {
+		level, _ := syslogEntryMeta(line)
+		doc = map[string]interface{}{"level": level, "message": string(line)}
+	}
+	at := now
+	if v, ok := doc["timestamp"]; ok {
+		if t, ok := parsePassthroughTime(v); ok {
+			at = t
+		}
+	}
+	if _, ok := doc["@timestamp"]; !ok {
+		doc["@timestamp"] = at.UTC().Format(time.RFC3339Nano)
+	}
+
+	meta, err := json.Marshal(map[string]interface{}{
+		"create": map[string]string{"_index": expandIndexTemplate(w.cfg.Index, doc, at)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	action := append(meta, '\n')
+	action = append(action, body...)
+	return append(action, '\n'), nil
+}
+
+// ship sends actions with the _bulk API, retrying the actions rejected with
+// 429 and requests failing with 5xx with exponential backoff.
+func (w *ElasticsearchWriter) ship(actions [][]byte) error {
+	backoff := w.cfg.RetryBackoff
+	var rejected []string
+	for attempt := 0; ; attempt++ {
+		retry, failures, err := w.bulk(actions)
+		rejected = append(rejected, failures...)
+		if len(retry) == 0 {
+			if err != nil {
+				return err
+			}
+			break
+		}
+		if attempt >= w.cfg.MaxRetries {
+			if err == nil {
+				err = fmt.Errorf("%d entries still throttled after %d retries", len(retry), attempt)
+			}
+			return err
+		}
+		actions = retry
+		time.Sleep(backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1)))
+		backoff *= 2
+		if backoff > maxElasticsearchBackoff {
+			backoff = maxElasticsearchBackoff
+		}
+	}
+	if len(rejected) > 0 {
+		return fmt.Errorf("%d entries rejected, first: %s", len(rejected), rejected[0])
+	}
+	return nil
+}
+
+// bulk sends one _bulk request. It returns the actions to retry, the reasons
+// of the actions rejected for good, and the error of a failed request, which
+// is retried when retry is set.
+func (w *ElasticsearchWriter) bulk(actions [][]byte) (retry [][]byte, rejected []string, err error) {
+	req, err := http.NewRequest(http.MethodPost, w.endpoint, bytes.NewReader(bytes.Join(actions, nil)))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	switch {
+	case w.cfg.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+w.cfg.APIKey)
+	case w.cfg.Username != "":
+		req.SetBasicAuth(w.cfg.Username, w.cfg.Password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return actions, nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return actions, nil, fmt.Errorf("bulk request failed: %s", resp.Status)
+	case resp.StatusCode/100 != 2:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, nil, fmt.Errorf("bulk request rejected: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, nil, fmt.Errorf("decoding bulk response: %w", err)
+	}
+	if !result.Errors {
+		return nil, nil, nil
+	}
+	for i, item := range result.Items {
+		for _, r := range item {
+			switch {
+			case r.Status == http.StatusTooManyRequests && i < len(actions):
+				retry = append(retry, actions[i])
+			case r.Status/100 != 2:
+				rejected = append(rejected, string(r.Error))
+			}
+		}
+	}
+	return retry, rejected, nil
+}
+
+// Flush ships the pending entries.
+func (w *ElasticsearchWriter) Flush() error {
+	if err := w.batch.flush(); err != nil {
+		return fmt.Errorf("elasticsearch: %w", err)
+	}
+	return nil
+}
+
+// LastError returns the most recent asynchronous delivery error.
+func (w *ElasticsearchWriter) LastError() error {
+	return w.batch.lastError()
+}
+
+// Close ships the pending entries and stops the background shipping.
+func (w *ElasticsearchWriter) Close() error {
+	if err := w.batch.close(); err != nil {
+		return fmt.Errorf("elasticsearch: %w", err)
+	}
+	return nil
+}
+
+var _ io.WriteCloser = (*ElasticsearchWriter)(nil)
+
+// expandIndexTemplate replaces {field} and date patterns in tmpl. Index
+// names must be lower case, and missing fields expand to "unknown".
+func expandIndexTemplate(tmpl string, doc map[string]interface{}, at time.Time) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(tmpl, '{')
+		if start < 0 {
+			b.WriteString(tmpl)
+			break
+		}
+		end := strings.IndexByte(tmpl[start:], '}')
+		if end < 0 {
+			b.WriteString(tmpl)
+			break
+		}
+		b.WriteString(tmpl[:start])
+		token := tmpl[start+1 : start+end]
+		if layout, ok := indexDateLayout(token); ok {
+			b.WriteString(at.UTC().Format(layout))
+		} else if v, ok := doc[token]; ok && fmt.Sprint(v) != "" {
+			b.WriteString(fmt.Sprint(v))
+		} else {
+			b.WriteString("unknown")
+		}
+		tmpl = tmpl[start+end+1:]
+	}
+	return strings.ToLower(b.String())
+}
+
+// indexDateLayout converts a Joda-style date pattern made of yyyy, MM, dd and
+// HH into a Go time layout.
+func indexDateLayout(token string) (string, bool) {
+	r := strings.NewReplacer("yyyy", "2006", "MM", "01", "dd", "02", "HH", "15")
+	layout := r.Replace(token)
+	if layout == token {
+		return "", false
+	}
+	for _, c := range layout {
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+			return "", false
+		}
+	}
+	return layout, true
+}
@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExpandIndexTemplate(t *testing.T) {
+	at := time.Date(2025, 5, 11, 19, 30, 0, 0, time.UTC)
+	doc := map[string]interface{}{"service": "Billing"}
+	if got := expandIndexTemplate("logs-{service}-{yyyy.MM.dd}", doc, at); got != "logs-billing-2025.05.11" {
+		t.Errorf("unexpected index %q", got)
+	}
+	if got := expandIndexTemplate("{environment}-{yyyy-MM-dd-HH}", doc, at); got != "unknown-2025-05-11-19" {
+		t.Errorf("unexpected index %q", got)
+	}
+}
+
+func TestElasticsearchWriter_BulkWithRetryOn429(t *testing.T) {
+	var mu sync.Mutex
+	var requests [][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" || r.Header.Get("Authorization") != "ApiKey k" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Header)
+		}
+		var lines []string
+		sc := bufio.NewScanner(r.Body)
+		for sc.Scan() {
+			lines = append(lines, sc.Text())
+		}
+		mu.Lock()
+		requests = append(requests, lines)
+		n := len(requests)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if n == 1 {
+			// Throttle the second document only.
+			_, _ = w.Write([]byte(`{"errors":true,"items":[{"create":{"status":201}},{"create":{"status":429,"error":{"type":"es_rejected_execution_exception"}}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errors":false,"items":[{"create":{"status":201}}]}`))
+	}))
+	defer srv.Close()
+
+	w, err := NewElasticsearchWriter(ElasticsearchConfig{URL: srv.URL, APIKey: "k", BatchInterval: time.Hour, RetryBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte(`{"timestamp":"2025-05-11T19:30:12Z","level":"INFO","service":"billing","message":"a"}` + "\n"))
+	_, _ = w.Write([]byte(`{"timestamp":"2025-05-11T19:30:13Z","level":"INFO","service":"billing","message":"b"}` + "\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(requests) != 2 || len(requests[0]) != 4 || len(requests[1]) != 2 {
+		t.Fatalf("expected the throttled document to be retried alone, got %v", requests)
+	}
+	var meta map[string]map[string]string
+	_ = json.Unmarshal([]byte(requests[0][0]), &meta)
+	if meta["create"]["_index"] != "logs-billing-2025.05.11" {
+		t.Errorf("unexpected action %s", requests[0][0])
+	}
+	if !strings.Contains(requests[1][1], `"message":"b"`) || !strings.Contains(requests[1][1], `"@timestamp":"2025-05-11T19:30:13Z"`) {
+		t.Errorf("unexpected retried document %s", requests[1][1])
+	}
+}
+
+func TestElasticsearchWriter_ReportsRejectedDocuments(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{"errors":true,"items":[{"create":{"status":400,"error":{"type":"mapper_parsing_exception"}}}]}`))
+	}))
+	defer srv.Close()
+
+	w, err := NewElasticsearchWriter(ElasticsearchConfig{URL: srv.URL, BatchInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte("ERROR: boom\n"))
+	if err := w.Flush(); err == nil || !strings.Contains(err.Error(), "mapper_parsing_exception") {
+		t.Errorf("expected the rejection to be reported, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected rejected documents not to be retried, got %d calls", calls)
+	}
+}
+
+func TestElasticsearchWriter_GivesUpAfterMaxRetries(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	w, err := NewElasticsearchWriter(ElasticsearchConfig{URL: srv.URL, BatchInterval: time.Hour, MaxRetries: 2, RetryBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte(`{"message":"a"}` + "\n"))
+	if err := w.Flush(); err == nil {
+		t.Errorf("expected an error after the retries")
+	}
+	if calls != 3 {
+		t.Errorf("expected 1 attempt and 2 retries, got %d", calls)
+	}
+}
+
+func TestElasticsearchWriter_RetriesDontBlockWrites(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	w, err := NewElasticsearchWriter(ElasticsearchConfig{URL: srv.URL, BatchSize: 1, BatchInterval: time.Hour, MaxRetries: 1, RetryBackoff: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, _ = w.Write([]byte(`{"message":"while down"}` + "\n"))
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("writes of full batches waited %s for the retries", elapsed)
+	}
+	_ = w.Close()
+}
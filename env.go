@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Environment variables read by ConfigFromEnv.
+const (
+	EnvLevel        = "LOG_LEVEL"
+	EnvFormat       = "LOG_FORMAT"
+	EnvFile         = "LOG_FILE"
+	EnvStdout       = "LOG_STDOUT"
+	EnvKafkaBrokers = "LOG_KAFKA_BROKERS"
+	EnvKafkaTopic   = "LOG_KAFKA_TOPIC"
+	EnvServiceName  = "SERVICE_NAME"
+	EnvEnvironment  = "ENVIRONMENT"
+)
+
+// InitFromEnv configures the package-level logger from the environment. See
+// ConfigFromEnv for the variables it reads.
+func InitFromEnv() error {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	return InitFromConfig(cfg)
+}
+
+// ConfigFromEnv builds a Config from the environment:
+//
+//	LOG_LEVEL          trace, debug, info, warn or error
+//	LOG_FORMAT         json, logfmt, gcp or text
+//	LOG_FILE           path of a rotating log file; unset disables the file
+//	LOG_STDOUT         false to disable standard output, which is on by default
+//	LOG_KAFKA_BROKERS  comma-separated host:port list
+//	LOG_KAFKA_TOPIC    Kafka topic; required with LOG_KAFKA_BROKERS
+//	SERVICE_NAME       service attached to structured entries
+//	ENVIRONMENT        environment attached to structured entries
+//
+// The result can be adjusted, e.g. to add Outputs, before InitFromConfig.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Level:       os.Getenv(EnvLevel),
+		Format:      os.Getenv(EnvFormat),
+		Service:     os.Getenv(EnvServiceName),
+		Environment: os.Getenv(EnvEnvironment),
+		Stdout:      true,
+	}
+
+	if v := os.Getenv(EnvStdout); v != "" {
+		stdout, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("logger: %s=%q: want true or false", EnvStdout, v)
+		}
+		cfg.Stdout = stdout
+	}
+	if path := os.Getenv(EnvFile); path != "" {
+		cfg.File = &FileConfig{Path: path}
+	}
+
+	brokers, topic := os.Getenv(EnvKafkaBrokers), os.Getenv(EnvKafkaTopic)
+	if brokers != "" || topic != "" {
+		k := &KafkaConfig{Topic: topic}
+		for _, b := range strings.Split(brokers, ",") {
+			if b = strings.TrimSpace(b); b != "" {
+				k.Brokers = append(k.Brokers, b)
+			}
+		}
+		cfg.Kafka = k
+	}
+	return cfg, cfg.Validate()
+}
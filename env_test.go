@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv(EnvLevel, "warn")
+	t.Setenv(EnvFormat, "json")
+	t.Setenv(EnvFile, "/var/log/app.log")
+	t.Setenv(EnvStdout, "false")
+	t.Setenv(EnvKafkaBrokers, "k1:9092, k2:9092")
+	t.Setenv(EnvKafkaTopic, "logs")
+	t.Setenv(EnvServiceName, "billing")
+	t.Setenv(EnvEnvironment, "prod")
+
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != "warn" || cfg.Format != "json" || cfg.Stdout || cfg.Service != "billing" || cfg.Environment != "prod" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if cfg.File == nil || cfg.File.Path != "/var/log/app.log" {
+		t.Errorf("expected file output, got %+v", cfg.File)
+	}
+	if cfg.Kafka == nil || len(cfg.Kafka.Brokers) != 2 || cfg.Kafka.Brokers[1] != "k2:9092" || cfg.Kafka.Topic != "logs" {
+		t.Errorf("unexpected kafka config: %+v", cfg.Kafka)
+	}
+}
+
+func TestConfigFromEnv_Errors(t *testing.T) {
+	t.Setenv(EnvKafkaBrokers, "k1:9092")
+	if _, err := ConfigFromEnv(); err == nil || !strings.Contains(err.Error(), "requires a topic") {
+		t.Errorf("expected missing topic to be reported, got %v", err)
+	}
+
+	t.Setenv(EnvKafkaBrokers, "")
+	t.Setenv(EnvStdout, "maybe")
+	if err := InitFromEnv(); err == nil || !strings.Contains(err.Error(), EnvStdout) {
+		t.Errorf("expected invalid LOG_STDOUT to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// EventCode describes a stable, documented event or error code. Alerting
+// rules and runbooks can key on the code instead of on message text.
+type EventCode struct {
+	Code        string   `json:"code"`
+	Description string   `json:"description,omitempty"`
+	Level       logLevel `json:"level,omitempty"`
+	RunbookURL  string   `json:"runbook_url,omitempty"`
+}
+
+var (
+	eventCodesMu sync.RWMutex
+	eventCodes   = map[string]EventCode{}
+)
+
+// RegisterEventCodes adds codes to the registry. Registering the same code
+// twice is an error.
+func RegisterEventCodes(codes ...EventCode) error {
+	eventCodesMu.Lock()
+	defer eventCodesMu.Unlock()
+
+	normalized := make([]EventCode, 0, len(codes))
+	for _, c := range codes {
+		if c.Code == "" {
+			return fmt.Errorf("event code: empty code")
+		}
+		if _, exists := eventCodes[c.Code]; exists {
+			return fmt.Errorf("event code %q already registered", c.Code)
+		}
+		if c.Level != "" {
+			level, ok := parseLevelName(string(c.Level))
+			if !ok {
+				return fmt.Errorf("event code %q: invalid level %q", c.Code, c.Level)
+			}
+			c.Level = level
+		}
+		normalized = append(normalized, c)
+	}
+	for _, c := range normalized {
+		eventCodes[c.Code] = c
+	}
+	return nil
+}
+
+// LoadEventCodes registers the codes listed in a JSON file containing an
+// array of EventCode objects.
+func LoadEventCodes(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var codes []EventCode
+	if err := json.Unmarshal(data, &codes); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return RegisterEventCodes(codes...)
+}
+
+// LookupEventCode returns the registered code.
+func LookupEventCode(code string) (EventCode, bool) {
+	eventCodesMu.RLock()
+	defer eventCodesMu.RUnlock()
+	c, ok := eventCodes[code]
+	return c, ok
+}
+
+// EventCodes returns every registered code, sorted.
+func EventCodes() []EventCode {
+	eventCodesMu.RLock()
+	defer eventCodesMu.RUnlock()
+	out := make([]EventCode, 0, len(eventCodes))
+	for _, c := range eventCodes {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out
+}
+
+func resetEventCodes() {
+	eventCodesMu.Lock()
+	defer eventCodesMu.Unlock()
+	eventCodes = map[string]EventCode{}
+}
+
+// LogEvent logs a structured entry tagged with event_id=code at the code's
+// registered level (INFO for codes without a level, ERROR for unknown codes).
+func LogEvent(ctx context.Context, code string, fields map[string]interface{}) {
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	fields["event_id"] = code
+
+	level := LevelError
+	if c, ok := LookupEventCode(code); ok {
+		level = LevelInfo
+		if c.Level != "" {
+			level = c.Level
+		}
+		if _, ok := fields["message"]; !ok && c.Description != "" {
+			fields["message"] = c.Description
+		}
+	}
+	logWithMap(level, ctx, fields)
+}
+
+// annotateEventCode adds the runbook link of a registered event_id or
+// error_code field, and flags codes missing from a non-empty registry.
+func annotateEventCode(fields map[string]interface{}) {
+	for _, key := range []string{"event_id", "error_code"} {
+		code, ok := fields[key].(string)
+		if !ok {
+			continue
+		}
+		eventCodesMu.RLock()
+		c, known := eventCodes[code]
+		empty := len(eventCodes) == 0
+		eventCodesMu.RUnlock()
+
+		if known {
+			if c.RunbookURL != "" {
+				fields["runbook_url"] = c.RunbookURL
+			}
+		} else if !empty {
+			fields["unknown_code"] = true
+		}
+	}
+}
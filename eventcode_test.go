@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestLogEvent_RegisteredCode(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+	defer resetEventCodes()
+
+	err := RegisterEventCodes(EventCode{
+		Code:        "PAY-001",
+		Description: "card declined",
+		Level:       "warn",
+		RunbookURL:  "https://runbooks.local/pay-001",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	LogEvent(context.Background(), "PAY-001", map[string]interface{}{"order": 7})
+	ErrorfMap(nil, map[string]interface{}{"error_code": "PAY-999"})
+
+	entries := decodeLogLines(t, &buf)
+	if entries[0]["level"] != "WARNING" || entries[0]["event_id"] != "PAY-001" || entries[0]["message"] != "card declined" {
+		t.Errorf("unexpected event entry: %v", entries[0])
+	}
+	if entries[0]["runbook_url"] != "https://runbooks.local/pay-001" {
+		t.Errorf("expected runbook link, got %v", entries[0]["runbook_url"])
+	}
+	if entries[1]["unknown_code"] != true {
+		t.Errorf("expected unregistered code to be flagged, got %v", entries[1])
+	}
+}
+
+func TestRegisterEventCodes_Duplicate(t *testing.T) {
+	defer resetEventCodes()
+	if err := RegisterEventCodes(EventCode{Code: "A"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterEventCodes(EventCode{Code: "A"}); err == nil {
+		t.Errorf("expected duplicate registration to fail")
+	}
+}
@@ -0,0 +1,190 @@
+package logger
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+// EventHubsConfig configures the Azure Event Hubs sink. It talks to the
+// namespace's Kafka-compatible endpoint on port 9093, authenticating either
+// with a SAS connection string or with Azure AD tokens.
+type EventHubsConfig struct {
+	// Namespace is the Event Hubs namespace ("myns") or its fully qualified
+	// host name. It is derived from ConnectionString when empty.
+	Namespace string
+	// EventHub is the event hub (Kafka topic) name. It is derived from the
+	// EntityPath of ConnectionString when empty.
+	EventHub string
+	// ConnectionString is a SAS connection string
+	// ("Endpoint=sb://myns.servicebus.windows.net/;SharedAccessKeyName=...;SharedAccessKey=...").
+	ConnectionString string
+	// TokenProvider returns Azure AD access tokens for the
+	// https://<namespace>/.default scope. It is used instead of
+	// ConnectionString when set.
+	TokenProvider func(ctx context.Context) (string, error)
+	// BatchSize and BatchTimeout control batching; defaults are 100 entries
+	// and 1 second.
+	BatchSize    int
+	BatchTimeout time.Duration
+}
+
+// EventHubsWriter publishes entries to Azure Event Hubs in batches. Writes do
+// not block on the network; delivery errors are reported by LastError and by
+// the next Write.
+type EventHubsWriter struct {
+	writer *kafka.Writer
+
+	mu      sync.Mutex
+	lastErr error
+}
+
+// NewEventHubsWriter validates cfg and returns a sink that can be passed to
+// Init as an additional output.
+func NewEventHubsWriter(cfg EventHubsConfig) (*EventHubsWriter, error) {
+	host, hub, err := cfg.endpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	var mechanism sasl.Mechanism
+	switch {
+	case cfg.TokenProvider != nil:
+		mechanism = oauthBearer{token: cfg.TokenProvider}
+	case cfg.ConnectionString != "":
+		mechanism = plain.Mechanism{Username: "$ConnectionString", Password: cfg.ConnectionString}
+	default:
+		return nil, errors.New("event hubs: ConnectionString or TokenProvider is required")
+	}
+
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	batchTimeout := cfg.BatchTimeout
+	if batchTimeout <= 0 {
+		batchTimeout = time.Second
+	}
+
+	w := &EventHubsWriter{}
+	w.writer = &kafka.Writer{
+		Addr:         kafka.TCP(host + ":9093"),
+		Topic:        hub,
+		Balancer:     &kafka.RoundRobin{},
+		BatchSize:    batchSize,
+		BatchTimeout: batchTimeout,
+		RequiredAcks: kafka.RequireOne,
+		Async:        true,
+		Transport: &kafka.Transport{
+			TLS:  &tls.Config{MinVersion: tls.VersionTLS12, ServerName: host},
+			SASL: mechanism,
+		},
+		Completion: func(messages []kafka.Message, err error) {
+			if err != nil {
+				w.mu.Lock()
+				w.lastErr = err
+				w.mu.Unlock()
+			}
+		},
+	}
+	return w, nil
+}
+
+// Write queues a copy of p for delivery. It returns the error of an earlier
+// asynchronous delivery, if any, after queueing p.
+func (w *EventHubsWriter) Write(p []byte) (int, error) {
+	msg := kafka.Message{Value: append([]byte(nil), p...)}
+	if err := w.writer.WriteMessages(context.Background(), msg); err != nil {
+		return 0, err
+	}
+	if err := w.takeError(); err != nil {
+		return 0, fmt.Errorf("event hubs: %w", err)
+	}
+	return len(p), nil
+}
+
+// LastError returns the most recent asynchronous delivery error.
+func (w *EventHubsWriter) LastError() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastErr
+}
+
+func (w *EventHubsWriter) takeError() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := w.lastErr
+	w.lastErr = nil
+	return err
+}
+
+// Close flushes pending batches and closes the connection.
+func (w *EventHubsWriter) Close() error {
+	return w.writer.Close()
+}
+
+func (cfg EventHubsConfig) endpoint() (host, hub string, err error) {
+	host, hub = cfg.Namespace, cfg.EventHub
+
+	if cfg.ConnectionString != "" {
+		for _, part := range strings.Split(cfg.ConnectionString, ";") {
+			k, v, ok := strings.Cut(part, "=")
+			if !ok {
+				continue
+			}
+			switch strings.ToLower(strings.TrimSpace(k)) {
+			case "endpoint":
+				if host == "" {
+					v = strings.TrimPrefix(v, "sb://")
+					host = strings.TrimSuffix(v, "/")
+				}
+			case "entitypath":
+				if hub == "" {
+					hub = v
+				}
+			}
+		}
+	}
+
+	if host == "" {
+		return "", "", errors.New("event hubs: Namespace is required")
+	}
+	if !strings.Contains(host, ".") {
+		host += ".servicebus.windows.net"
+	}
+	if hub == "" {
+		return "", "", errors.New("event hubs: EventHub is required")
+	}
+	return host, hub, nil
+}
+
+// oauthBearer implements the SASL OAUTHBEARER mechanism (RFC 7628) used by
+// Event Hubs for Azure AD authentication.
+type oauthBearer struct {
+	token func(ctx context.Context) (string, error)
+}
+
+func (m oauthBearer) Name() string { return "OAUTHBEARER" }
+
+func (m oauthBearer) Start(ctx context.Context) (sasl.StateMachine, []byte, error) {
+	token, err := m.token(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("event hubs: fetching token: %w", err)
+	}
+	return m, []byte("n,,\x01auth=Bearer " + token + "\x01\x01"), nil
+}
+
+func (m oauthBearer) Next(ctx context.Context, challenge []byte) (bool, []byte, error) {
+	if len(challenge) > 0 {
+		return false, nil, fmt.Errorf("event hubs: authentication failed: %s", challenge)
+	}
+	return true, nil, nil
+}
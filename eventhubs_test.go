@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestEventHubsConfig_FromConnectionString(t *testing.T) {
+	cfg := EventHubsConfig{
+		ConnectionString: "Endpoint=sb://logs-ns.servicebus.windows.net/;SharedAccessKeyName=send;SharedAccessKey=abc=;EntityPath=app-logs",
+	}
+	host, hub, err := cfg.endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host != "logs-ns.servicebus.windows.net" || hub != "app-logs" {
+		t.Errorf("unexpected endpoint %s / %s", host, hub)
+	}
+
+	w, err := NewEventHubsWriter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.writer.Addr.String() != "logs-ns.servicebus.windows.net:9093" || w.writer.Topic != "app-logs" {
+		t.Errorf("unexpected writer target %s / %s", w.writer.Addr, w.writer.Topic)
+	}
+}
+
+func TestEventHubsConfig_Validation(t *testing.T) {
+	if _, err := NewEventHubsWriter(EventHubsConfig{Namespace: "ns", EventHub: "hub"}); err == nil {
+		t.Errorf("expected an error without credentials")
+	}
+	if _, err := NewEventHubsWriter(EventHubsConfig{ConnectionString: "SharedAccessKey=x"}); err == nil {
+		t.Errorf("expected an error without a namespace")
+	}
+	host, _, err := EventHubsConfig{Namespace: "ns", EventHub: "hub"}.endpoint()
+	if err != nil || host != "ns.servicebus.windows.net" {
+		t.Errorf("expected short namespace to be expanded, got %q, %v", host, err)
+	}
+}
+
+func TestOAuthBearer_InitialResponse(t *testing.T) {
+	m := oauthBearer{token: func(context.Context) (string, error) { return "tok", nil }}
+	_, ir, err := m.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(ir) != "n,,\x01auth=Bearer tok\x01\x01" {
+		t.Errorf("unexpected initial response %q", ir)
+	}
+	if done, _, err := m.Next(context.Background(), nil); !done || err != nil {
+		t.Errorf("expected empty server response to complete authentication")
+	}
+}
+
+func TestEventHubsWriter_QueuesEntryBeforeReportingEarlierError(t *testing.T) {
+	w, err := NewEventHubsWriter(EventHubsConfig{Namespace: "ns", EventHub: "hub", ConnectionString: "SharedAccessKey=x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.writer.Addr = kafka.TCP("127.0.0.1:1")
+	w.writer.Transport = nil
+	defer w.Close()
+
+	// The entry is handed to the writer first; its own failure is returned
+	// and the earlier one is kept for the next Write.
+	w.lastErr = errors.New("earlier failure")
+	if _, err := w.Write([]byte("unrelated entry")); err == nil || strings.Contains(err.Error(), "earlier failure") {
+		t.Errorf("expected the entry to be sent before the earlier failure is reported, got %v", err)
+	}
+	if err := w.LastError(); err == nil || err.Error() != "earlier failure" {
+		t.Errorf("expected the earlier failure to be kept, got %v", err)
+	}
+}
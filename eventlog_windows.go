@@ -0,0 +1,133 @@
+//go:build windows
+
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// EventLogConfig configures the Windows Event Log sink.
+type EventLogConfig struct {
+	// Source is the event source name shown in Event Viewer. Register it
+	// once at install time, e.g. with New-EventLog -LogName Application
+	// -Source <name>, so the viewer can render the messages.
+	Source string
+	// EventID is reported with every entry; defaults to 1.
+	EventID uint32
+}
+
+// Event types accepted by ReportEventW.
+const (
+	eventlogErrorType       = 0x0001
+	eventlogWarningType     = 0x0002
+	eventlogInformationType = 0x0004
+)
+
+var (
+	advapi32                  = syscall.NewLazyDLL("advapi32.dll")
+	procRegisterEventSourceW  = advapi32.NewProc("RegisterEventSourceW")
+	procDeregisterEventSource = advapi32.NewProc("DeregisterEventSource")
+	procReportEventW          = advapi32.NewProc("ReportEventW")
+)
+
+// EventLogWriter writes entries to the Windows Event Log. Pass it to Init as
+// an additional output. ERROR and FATAL entries are reported as errors,
+// WARNING as warnings and everything else as information; the entry itself,
+// JSON or text, is the event message.
+type EventLogWriter struct {
+	eventID uint32
+
+	mu     sync.Mutex
+	handle uintptr
+}
+
+// NewEventLogWriter opens the event source.
+func NewEventLogWriter(cfg EventLogConfig) (*EventLogWriter, error) {
+	if cfg.Source == "" {
+		return nil, errors.New("eventlog: Source is required")
+	}
+	source, err := syscall.UTF16PtrFromString(cfg.Source)
+	if err != nil {
+		return nil, fmt.Errorf("eventlog: %w", err)
+	}
+	h, _, err := procRegisterEventSourceW.Call(0, uintptr(unsafe.Pointer(source)))
+	if h == 0 {
+		return nil, fmt.Errorf("eventlog: registering source %q: %w", cfg.Source, err)
+	}
+	eventID := cfg.EventID
+	if eventID == 0 {
+		eventID = 1
+	}
+	return &EventLogWriter{eventID: eventID, handle: h}, nil
+}
+
+// Write reports each line of p as one event.
+func (w *EventLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.handle == 0 {
+		return 0, errors.New("eventlog: writer is closed")
+	}
+
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		level, _ := syslogEntryMeta(line)
+		msg, err := syscall.UTF16PtrFromString(string(bytes.ReplaceAll(line, []byte{0}, nil)))
+		if err != nil {
+			return 0, fmt.Errorf("eventlog: %w", err)
+		}
+		strs := []*uint16{msg}
+		ok, _, err := procReportEventW.Call(
+			w.handle,
+			uintptr(eventLogType(level)),
+			0, // category
+			uintptr(w.eventID),
+			0, // user SID
+			1, // number of strings
+			0, // raw data size
+			uintptr(unsafe.Pointer(&strs[0])),
+			0, // raw data
+		)
+		if ok == 0 {
+			return 0, fmt.Errorf("eventlog: %w", err)
+		}
+	}
+	return len(p), nil
+}
+
+// Close deregisters the event source.
+func (w *EventLogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.handle == 0 {
+		return nil
+	}
+	ok, _, err := procDeregisterEventSource.Call(w.handle)
+	w.handle = 0
+	if ok == 0 {
+		return fmt.Errorf("eventlog: %w", err)
+	}
+	return nil
+}
+
+var _ io.WriteCloser = (*EventLogWriter)(nil)
+
+// eventLogType maps a level to an event type by its severity number.
+func eventLogType(level logLevel) uint16 {
+	switch sev := SeverityNumber(level); {
+	case sev >= 17:
+		return eventlogErrorType
+	case sev >= 13:
+		return eventlogWarningType
+	default:
+		return eventlogInformationType
+	}
+}
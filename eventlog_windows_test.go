@@ -0,0 +1,26 @@
+//go:build windows
+
+package logger
+
+import "testing"
+
+func TestEventLogType(t *testing.T) {
+	for level, want := range map[logLevel]uint16{
+		LevelTrace: eventlogInformationType,
+		LevelDebug: eventlogInformationType,
+		LevelInfo:  eventlogInformationType,
+		LevelWarn:  eventlogWarningType,
+		LevelError: eventlogErrorType,
+		LevelFatal: eventlogErrorType,
+	} {
+		if got := eventLogType(level); got != want {
+			t.Errorf("eventLogType(%s) = %d, want %d", level, got, want)
+		}
+	}
+}
+
+func TestNewEventLogWriter_RequiresSource(t *testing.T) {
+	if _, err := NewEventLogWriter(EventLogConfig{}); err == nil {
+		t.Errorf("expected an error without a source")
+	}
+}
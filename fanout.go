@@ -0,0 +1,224 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var parallelFanout atomic.Bool
+
+// EnableParallelFanout makes every entry be written to all outputs
+// concurrently instead of one after another. It helps when one output (e.g.
+// Kafka) is much slower than the others.
+func EnableParallelFanout(enabled bool) {
+	parallelFanout.Store(enabled)
+}
+
+// SinkError is the failure of a single output.
+type SinkError struct {
+	Sink string
+	Err  error
+}
+
+func (e SinkError) Error() string { return e.Sink + ": " + e.Err.Error() }
+
+func (e SinkError) Unwrap() error { return e.Err }
+
+// FanoutError reports the outputs that failed to receive an entry. The
+// remaining outputs received it.
+type FanoutError struct {
+	Errors []SinkError
+}
+
+func (e *FanoutError) Error() string {
+	parts := make([]string, len(e.Errors))
+	for i, se := range e.Errors {
+		parts[i] = se.Error()
+	}
+	return "log fan-out: " + strings.Join(parts, "; ")
+}
+
+func (e *FanoutError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, se := range e.Errors {
+		errs[i] = se
+	}
+	return errs
+}
+
+// SinkStats are the delivery counters of one output.
+type SinkStats struct {
+	Name        string
+	Writes      uint64
+	Errors      uint64
+	Bytes       uint64
+	LastError   string
+	LastErrorAt time.Time
+}
+
+type sinkWriter struct {
+	name string
+	w    io.Writer
+	// owned is set for outputs the logger opened, or was handed with
+	// WithOwnedOutputs; only those are closed by Close.
+	owned bool
+
+	writes      atomic.Uint64
+	errors      atomic.Uint64
+	bytes       atomic.Uint64
+	lastSuccess atomic.Int64 // unix nanoseconds, see SinkHealth
+
+	timeout  atomic.Int64 // time.Duration, see SetSinkWriteTimeout
+	inflight chan struct{}
+
+	// writeMu serializes writes so that outputs which aren't safe for
+	// concurrent use, such as bytes.Buffer, can be passed to Init.
+	writeMu sync.Mutex
+
+	mu          sync.Mutex
+	lastError   string
+	lastErrorAt time.Time
+
+	errorReports diagnosticLimiter
+}
+
+func newSinkWriter(name string, w io.Writer) *sinkWriter {
+	s := &sinkWriter{name: name, w: w, inflight: make(chan struct{}, 1)}
+	s.timeout.Store(int64(sinkTimeoutFor(name)))
+	return s
+}
+
+func (s *sinkWriter) Write(p []byte) (int, error) {
+	var (
+		n   int
+		err error
+	)
+	if timeout := time.Duration(s.timeout.Load()); timeout > 0 {
+		n, err = s.writeWithDeadline(p, timeout)
+	} else {
+		n, err = s.write(p)
+	}
+	if err != nil {
+		s.errors.Add(1)
+		s.mu.Lock()
+		s.lastError = err.Error()
+		s.lastErrorAt = time.Now()
+		s.mu.Unlock()
+		reportSinkError(s.name, err, p)
+		if s.errorReports.allow() {
+			diagnose(s.name, err, "write failed (%d failures so far)", s.errors.Load())
+		}
+		return n, err
+	}
+	s.writes.Add(1)
+	s.bytes.Add(uint64(n))
+	s.lastSuccess.Store(time.Now().UnixNano())
+	return n, nil
+}
+
+func (s *sinkWriter) write(p []byte) (n int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			n, err = 0, fmt.Errorf("panic: %v", r)
+		}
+	}()
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	n, err = s.w.Write(p)
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	return n, err
+}
+
+func (s *sinkWriter) stats() SinkStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return SinkStats{
+		Name:        s.name,
+		Writes:      s.writes.Load(),
+		Errors:      s.errors.Load(),
+		Bytes:       s.bytes.Load(),
+		LastError:   s.lastError,
+		LastErrorAt: s.lastErrorAt,
+	}
+}
+
+// fanoutWriter writes every entry to each sink independently, unlike
+// io.MultiWriter, which stops at the first failing writer. Taps are internal
+// writers (recent entries, shadow sinks) that never fail.
+type fanoutWriter struct {
+	sinks []*sinkWriter
+	taps  []io.Writer
+}
+
+func newFanout(sinks []*sinkWriter, taps ...io.Writer) *fanoutWriter {
+	return &fanoutWriter{sinks: sinks, taps: taps}
+}
+
+func (f *fanoutWriter) Write(p []byte) (int, error) {
+	var errs []SinkError
+	if parallelFanout.Load() && len(f.sinks) > 1 {
+		errs = f.writeParallel(p)
+	} else {
+		for _, s := range f.sinks {
+			if _, err := s.Write(p); err != nil {
+				errs = append(errs, SinkError{Sink: s.name, Err: err})
+			}
+		}
+	}
+	for _, t := range f.taps {
+		_, _ = t.Write(p)
+	}
+
+	if len(errs) == 0 {
+		return len(p), nil
+	}
+	if len(errs) == len(f.sinks) {
+		return 0, &FanoutError{Errors: errs}
+	}
+	return len(p), &FanoutError{Errors: errs}
+}
+
+func (f *fanoutWriter) writeParallel(p []byte) []SinkError {
+	results := make([]error, len(f.sinks))
+	var wg sync.WaitGroup
+	for i, s := range f.sinks {
+		wg.Add(1)
+		go func(i int, s *sinkWriter) {
+			defer wg.Done()
+			_, results[i] = s.Write(p)
+		}(i, s)
+	}
+	wg.Wait()
+
+	var errs []SinkError
+	for i, err := range results {
+		if err != nil {
+			errs = append(errs, SinkError{Sink: f.sinks[i].name, Err: err})
+		}
+	}
+	return errs
+}
+
+// SinkStatistics returns the delivery counters of every configured output.
+func SinkStatistics() []SinkStats {
+	return std.SinkStatistics()
+}
+
+// SinkStatistics returns the delivery counters of every output of l.
+func (l *Logger) SinkStatistics() []SinkStats {
+	f := l.load().fanout
+	if f == nil {
+		return nil
+	}
+	out := make([]SinkStats, len(f.sinks))
+	for i, s := range f.sinks {
+		out[i] = s.stats()
+	}
+	return out
+}
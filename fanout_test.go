@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestFanout_IsolatesFailingSink(t *testing.T) {
+	var first, last bytes.Buffer
+	f := newFanout([]*sinkWriter{
+		newSinkWriter("file", &first),
+		newSinkWriter("kafka", failingWriter{}),
+		newSinkWriter("stdout", &last),
+	})
+
+	n, err := f.Write([]byte("entry\n"))
+	if n != len("entry\n") {
+		t.Errorf("expected full write count when some sinks succeed, got %d", n)
+	}
+	var fanErr *FanoutError
+	if !errors.As(err, &fanErr) || len(fanErr.Errors) != 1 || fanErr.Errors[0].Sink != "kafka" {
+		t.Fatalf("expected a per-sink error for kafka, got %v", err)
+	}
+	if first.String() != "entry\n" || last.String() != "entry\n" {
+		t.Errorf("healthy sinks must receive the entry despite the failure: %q / %q", first.String(), last.String())
+	}
+}
+
+func TestFanout_ParallelAndStats(t *testing.T) {
+	EnableParallelFanout(true)
+	defer EnableParallelFanout(false)
+
+	var a, b bytes.Buffer
+	f := newFanout([]*sinkWriter{
+		newSinkWriter("a", &a),
+		newSinkWriter("b", &b),
+		newSinkWriter("broken", failingWriter{}),
+	})
+	std.state.Store(&loggerState{fanout: f})
+	defer std.state.Store(nil)
+
+	for i := 0; i < 3; i++ {
+		_, _ = f.Write([]byte("x\n"))
+	}
+
+	if a.Len() != 6 || b.Len() != 6 {
+		t.Errorf("expected every entry in every healthy sink, got %d / %d bytes", a.Len(), b.Len())
+	}
+	stats := SinkStatistics()
+	if len(stats) != 3 || stats[0].Writes != 3 || stats[2].Errors != 3 || stats[2].LastError != "loki unreachable" {
+		t.Errorf("unexpected sink stats: %+v", stats)
+	}
+}
+
+func TestFanout_AllSinksFailing(t *testing.T) {
+	f := newFanout([]*sinkWriter{newSinkWriter("kafka", failingWriter{})})
+	if n, err := f.Write([]byte("x")); n != 0 || err == nil {
+		t.Errorf("expected zero bytes and an error when every sink fails, got %d, %v", n, err)
+	}
+}
+
+func TestSinkWriter_SerializesWrites(t *testing.T) {
+	var buf bytes.Buffer
+	s := newSinkWriter("buf", &buf)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_, _ = s.Write([]byte("0123456789\n"))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if buf.Len() != 8*50*11 {
+		t.Errorf("expected every write to land intact, got %d bytes", buf.Len())
+	}
+}
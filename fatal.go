@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+var (
+	fatalDumpMu      sync.Mutex
+	fatalDumpEnabled bool
+	fatalDumpPath    string
+
+	// exitFunc is swapped out in tests.
+	exitFunc = os.Exit
+)
+
+// EnableFatalStackDump makes Fatal, Fatalf and fatal structured entries
+// capture a dump of all goroutine stacks before the process exits. The dump
+// is appended to the file at path, or written as a structured entry to the
+// configured outputs when path is empty.
+func EnableFatalStackDump(path string) {
+	fatalDumpMu.Lock()
+	defer fatalDumpMu.Unlock()
+	fatalDumpEnabled = true
+	fatalDumpPath = path
+}
+
+// DisableFatalStackDump turns off goroutine dumps on Fatal.
+func DisableFatalStackDump() {
+	fatalDumpMu.Lock()
+	defer fatalDumpMu.Unlock()
+	fatalDumpEnabled = false
+	fatalDumpPath = ""
+}
+
+// exitFatal optionally dumps the goroutine stacks, closes l's outputs so
+// buffered entries aren't lost, and exits.
+func (l *Logger) exitFatal() {
+	fatalDumpMu.Lock()
+	enabled, path := fatalDumpEnabled, fatalDumpPath
+	fatalDumpMu.Unlock()
+
+	if enabled {
+		l.writeGoroutineDump(path)
+	}
+	if err := l.Close(); err != nil {
+		diagnose("shutdown", err, "closing outputs")
+	}
+	exitFunc(1)
+}
+
+func (l *Logger) writeGoroutineDump(path string) {
+	st := l.load()
+	dump := allGoroutineStacks()
+	now := time.Now()
+
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err == nil {
+			fmt.Fprintf(f, "=== goroutine dump %s service=%s environment=%s ===\n", now.Format(time.RFC3339), st.service, st.environment)
+			_, _ = f.Write(dump)
+			_, _ = f.WriteString("\n")
+			_ = f.Close()
+			return
+		}
+		// Fall through to the configured outputs when the file can't be opened.
+	}
+
+	if st.structuredWriter == nil {
+		_, _ = os.Stderr.Write(dump)
+		return
+	}
+	data, err := st.encode(map[string]interface{}{
+		"timestamp":   now.Format(time.RFC3339),
+		"level":       LevelFatal,
+		"service":     st.service,
+		"environment": st.environment,
+		"message":     "goroutine dump",
+		"goroutines":  string(dump),
+	})
+	if err != nil {
+		return
+	}
+	_, _ = st.structuredWriter.Write(append(data, '\n'))
+}
+
+func allGoroutineStacks() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+}
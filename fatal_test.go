@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func stubExit(t *testing.T) *int {
+	t.Helper()
+	code := -1
+	exitFunc = func(c int) { code = c }
+	t.Cleanup(func() { exitFunc = os.Exit })
+	return &code
+}
+
+func TestFatal_GoroutineDumpToFile(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+	code := stubExit(t)
+
+	path := filepath.Join(t.TempDir(), "fatal.dump")
+	EnableFatalStackDump(path)
+	defer DisableFatalStackDump()
+
+	Fatal("could not connect to redis")
+
+	if *code != 1 {
+		t.Fatalf("expected exit code 1, got %d", *code)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected dump file: %v", err)
+	}
+	if !strings.Contains(string(data), "goroutine ") || !strings.Contains(string(data), "TestFatal_GoroutineDumpToFile") {
+		t.Errorf("dump does not contain goroutine stacks:\n%s", data)
+	}
+}
+
+func TestFatal_GoroutineDumpToSink(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+	stubExit(t)
+
+	EnableFatalStackDump("")
+	defer DisableFatalStackDump()
+
+	Fatalf("boom %d", 1)
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected fatal entry and dump entry, got %d", len(entries))
+	}
+	if dump, _ := entries[1]["goroutines"].(string); !strings.Contains(dump, "goroutine ") {
+		t.Errorf("expected goroutine dump entry, got %v", entries[1])
+	}
+}
@@ -0,0 +1,560 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+)
+
+// Drop rules are boolean expressions evaluated against every entry before it
+// is written; an entry matching any rule is discarded. The expression
+// language supports:
+//
+//	level, message          the entry level (e.g. "DEBUG") and message
+//	fields.<key>[.<key>]    a structured field, nested maps via dots
+//	"str", 'str', 42, 1.5   string and number literals, true, false, null
+//	== != < <= > >=         comparisons (numbers numerically, strings lexically)
+//	=~                      regular expression match against a string literal
+//	&& || ! ( )             boolean logic
+//
+// Example:
+//
+//	level == "DEBUG" && fields.path == "/healthz"
+var dropRules atomic.Pointer[[]*FilterRule]
+
+// FilterRule is a compiled drop rule.
+type FilterRule struct {
+	expr string
+	root filterNode
+}
+
+// CompileFilter parses a drop rule expression.
+func CompileFilter(expr string) (*FilterRule, error) {
+	p := &filterParser{src: expr}
+	if err := p.lex(); err != nil {
+		return nil, fmt.Errorf("filter %q: %w", expr, err)
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("filter %q: %w", expr, err)
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("filter %q: unexpected %q", expr, p.tokens[p.pos].text)
+	}
+	return &FilterRule{expr: expr, root: root}, nil
+}
+
+// String returns the source expression.
+func (r *FilterRule) String() string { return r.expr }
+
+// Match reports whether an entry with the given level, message and fields
+// matches the rule.
+func (r *FilterRule) Match(level, message string, fields map[string]interface{}) bool {
+	v, _ := r.root.eval(&filterEnv{level: level, message: message, fields: fields}).(bool)
+	return v
+}
+
+// SetDropRules compiles and atomically installs the given drop rules,
+// replacing any previous ones. On error the current rules are kept.
+func SetDropRules(exprs ...string) error {
+	rules := make([]*FilterRule, 0, len(exprs))
+	for _, expr := range exprs {
+		rule, err := CompileFilter(expr)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		dropRules.Store(nil)
+		return nil
+	}
+	dropRules.Store(&rules)
+	return nil
+}
+
+// DropRules returns the source expressions of the installed drop rules.
+func DropRules() []string {
+	rules := dropRules.Load()
+	if rules == nil {
+		return nil
+	}
+	out := make([]string, len(*rules))
+	for i, r := range *rules {
+		out[i] = r.expr
+	}
+	return out
+}
+
+// LoadDropRules reads drop rules from a file with one expression per line.
+// Blank lines and lines starting with # are ignored.
+func LoadDropRules(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var exprs []string
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, err := CompileFilter(line); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+		exprs = append(exprs, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return SetDropRules(exprs...)
+}
+
+// WatchDropRules reloads the drop rules file whenever its modification time
+// changes, polling every interval until ctx is done. Invalid files are
+// reported as warnings and leave the previous rules in place.
+func WatchDropRules(ctx context.Context, path string, interval time.Duration) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := LoadDropRules(path); err != nil {
+		return err
+	}
+
+	go func() {
+		lastMod := info.ModTime()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				info, err := os.Stat(path)
+				if err != nil || info.ModTime().Equal(lastMod) {
+					continue
+				}
+				lastMod = info.ModTime()
+				if err := LoadDropRules(path); err != nil {
+					logWithMap(LevelWarn, nil, map[string]interface{}{
+						"message": "failed to reload drop rules",
+						"path":    path,
+						"error":   err.Error(),
+					})
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func dropEntry(level logLevel, message string, fields map[string]interface{}) bool {
+	rules := dropRules.Load()
+	if rules == nil {
+		return false
+	}
+	for _, r := range *rules {
+		if r.Match(string(level), message, fields) {
+			return true
+		}
+	}
+	return false
+}
+
+type filterEnv struct {
+	level   string
+	message string
+	fields  map[string]interface{}
+}
+
+type filterNode interface {
+	eval(env *filterEnv) interface{}
+}
+
+type filterLiteral struct{ value interface{} }
+
+func (n filterLiteral) eval(*filterEnv) interface{} { return n.value }
+
+type filterIdent struct{ path []string }
+
+func (n filterIdent) eval(env *filterEnv) interface{} {
+	switch n.path[0] {
+	case "level":
+		return env.level
+	case "message", "msg":
+		if env.message == "" && env.fields != nil {
+			return normalizeFilterValue(env.fields["message"])
+		}
+		return env.message
+	}
+
+	var cur interface{} = env.fields
+	for _, key := range n.path[1:] {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = m[key]
+	}
+	return normalizeFilterValue(cur)
+}
+
+type filterNot struct{ operand filterNode }
+
+func (n filterNot) eval(env *filterEnv) interface{} {
+	v, _ := n.operand.eval(env).(bool)
+	return !v
+}
+
+type filterLogical struct {
+	op          string
+	left, right filterNode
+}
+
+func (n filterLogical) eval(env *filterEnv) interface{} {
+	l, _ := n.left.eval(env).(bool)
+	if n.op == "&&" {
+		if !l {
+			return false
+		}
+	} else if l {
+		return true
+	}
+	r, _ := n.right.eval(env).(bool)
+	return r
+}
+
+type filterCompare struct {
+	op          string
+	left, right filterNode
+}
+
+func (n filterCompare) eval(env *filterEnv) interface{} {
+	l, r := n.left.eval(env), n.right.eval(env)
+	switch n.op {
+	case "==":
+		return filterEqual(l, r)
+	case "!=":
+		return !filterEqual(l, r)
+	}
+
+	if lf, ok := l.(float64); ok {
+		if rf, ok := r.(float64); ok {
+			return compareOrdered(n.op, lf, rf)
+		}
+		return false
+	}
+	if ls, ok := l.(string); ok {
+		if rs, ok := r.(string); ok {
+			return compareOrdered(n.op, ls, rs)
+		}
+	}
+	return false
+}
+
+func filterEqual(l, r interface{}) bool {
+	switch l.(type) {
+	case nil, string, float64, bool:
+	default:
+		return false
+	}
+	switch r.(type) {
+	case nil, string, float64, bool:
+	default:
+		return false
+	}
+	return l == r
+}
+
+func compareOrdered[T float64 | string](op string, l, r T) bool {
+	switch op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	case ">=":
+		return l >= r
+	}
+	return false
+}
+
+type filterMatch struct {
+	left filterNode
+	re   *regexp.Regexp
+}
+
+func (n filterMatch) eval(env *filterEnv) interface{} {
+	s, ok := n.left.eval(env).(string)
+	return ok && n.re.MatchString(s)
+}
+
+func normalizeFilterValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case logLevel:
+		return string(t)
+	case int:
+		return float64(t)
+	case int8:
+		return float64(t)
+	case int16:
+		return float64(t)
+	case int32:
+		return float64(t)
+	case int64:
+		return float64(t)
+	case uint:
+		return float64(t)
+	case uint8:
+		return float64(t)
+	case uint16:
+		return float64(t)
+	case uint32:
+		return float64(t)
+	case uint64:
+		return float64(t)
+	case float32:
+		return float64(t)
+	case json.Number:
+		f, _ := t.Float64()
+		return f
+	case fmt.Stringer:
+		return t.String()
+	}
+	return v
+}
+
+type filterToken struct {
+	kind string // "ident", "string", "number", "op"
+	text string
+}
+
+type filterParser struct {
+	src    string
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) lex() error {
+	src := p.src
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && rune(src[j]) != c {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return fmt.Errorf("unterminated string at offset %d", i)
+			}
+			raw := src[i+1 : j]
+			if c == '\'' {
+				raw = strings.ReplaceAll(raw, `"`, `\"`)
+				raw = strings.ReplaceAll(raw, `\'`, `'`)
+			}
+			s, err := strconv.Unquote(`"` + raw + `"`)
+			if err != nil {
+				return fmt.Errorf("invalid string at offset %d: %w", i, err)
+			}
+			p.tokens = append(p.tokens, filterToken{"string", s})
+			i = j + 1
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(src) && unicode.IsDigit(rune(src[i+1]))):
+			j := i + 1
+			for j < len(src) && (unicode.IsDigit(rune(src[j])) || src[j] == '.') {
+				j++
+			}
+			p.tokens = append(p.tokens, filterToken{"number", src[i:j]})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i + 1
+			for j < len(src) && (unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j])) || src[j] == '_' || src[j] == '.') {
+				j++
+			}
+			p.tokens = append(p.tokens, filterToken{"ident", src[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "<", ">", "!", "(", ")"} {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+			p.tokens = append(p.tokens, filterToken{"op", op})
+			i += len(op)
+		}
+	}
+	return nil
+}
+
+func (p *filterParser) peek() (filterToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return filterToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *filterParser) acceptOp(ops ...string) (string, bool) {
+	tok, ok := p.peek()
+	if !ok || tok.kind != "op" {
+		return "", false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.acceptOp("||"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = filterLogical{op: "||", left: left, right: right}
+	}
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.acceptOp("&&"); !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = filterLogical{op: "&&", left: left, right: right}
+	}
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	if _, ok := p.acceptOp("!"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{operand}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *filterParser) parseCompare() (filterNode, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.acceptOp("==", "!=", "<=", ">=", "<", ">", "=~")
+	if !ok {
+		return left, nil
+	}
+
+	if op == "=~" {
+		tok, ok := p.peek()
+		if !ok || tok.kind != "string" {
+			return nil, fmt.Errorf("=~ requires a string literal pattern")
+		}
+		p.pos++
+		re, err := regexp.Compile(tok.text)
+		if err != nil {
+			return nil, err
+		}
+		return filterMatch{left: left, re: re}, nil
+	}
+
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	return filterCompare{op: op, left: left, right: right}, nil
+}
+
+func (p *filterParser) parsePrimary() (filterNode, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+
+	switch tok.kind {
+	case "string":
+		return filterLiteral{tok.text}, nil
+	case "number":
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", tok.text)
+		}
+		return filterLiteral{f}, nil
+	case "ident":
+		switch tok.text {
+		case "true":
+			return filterLiteral{true}, nil
+		case "false":
+			return filterLiteral{false}, nil
+		case "null", "nil":
+			return filterLiteral{nil}, nil
+		}
+		path := strings.Split(tok.text, ".")
+		switch path[0] {
+		case "level", "message", "msg":
+			if len(path) != 1 {
+				return nil, fmt.Errorf("unknown identifier %q", tok.text)
+			}
+		case "fields":
+			if len(path) < 2 {
+				return nil, fmt.Errorf("fields requires a key, e.g. fields.path")
+			}
+		default:
+			return nil, fmt.Errorf("unknown identifier %q (use level, message or fields.<key>)", tok.text)
+		}
+		return filterIdent{path: path}, nil
+	case "op":
+		if tok.text == "(" {
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := p.acceptOp(")"); !ok {
+				return nil, fmt.Errorf("missing closing parenthesis")
+			}
+			return inner, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q", tok.text)
+}
@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompileFilter_Expressions(t *testing.T) {
+	fields := map[string]interface{}{
+		"path":   "/healthz",
+		"status": 200,
+		"http":   map[string]interface{}{"method": "GET"},
+	}
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{`level == "DEBUG" && fields.path == "/healthz"`, true},
+		{`level == "INFO" && fields.path == "/healthz"`, false},
+		{`fields.status >= 200 && fields.status < 300`, true},
+		{`fields.http.method == 'GET'`, true},
+		{`!(fields.path == "/healthz") || fields.missing == null`, true},
+		{`message =~ "^ping"`, true},
+		{`fields.http == "GET"`, false},
+	}
+	for _, c := range cases {
+		rule, err := CompileFilter(c.expr)
+		if err != nil {
+			t.Fatalf("compile %q: %v", c.expr, err)
+		}
+		if got := rule.Match("DEBUG", "ping ok", fields); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.expr, c.want, got)
+		}
+	}
+}
+
+func TestCompileFilter_Errors(t *testing.T) {
+	for _, expr := range []string{`level ==`, `(level == "INFO"`, `user == "x"`, `message =~ level`, `level == "x`} {
+		if _, err := CompileFilter(expr); err == nil {
+			t.Errorf("expected compile error for %q", expr)
+		}
+	}
+}
+
+func TestDropRules_SuppressEntries(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	if err := SetDropRules(`level == "DEBUG" && fields.path == "/healthz"`, `message == "noisy"`); err != nil {
+		t.Fatal(err)
+	}
+	defer SetDropRules()
+
+	DebugfMap(context.Background(), map[string]interface{}{"path": "/healthz"})
+	DebugfMap(context.Background(), map[string]interface{}{"path": "/users"})
+	Info("noisy")
+	Info("useful")
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 2 || entries[0]["path"] != "/users" || entries[1]["message"] != "useful" {
+		t.Errorf("unexpected entries after drop rules: %v", entries)
+	}
+}
+
+func TestLoadDropRules_FromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "drop.rules")
+	content := "# noisy probes\nfields.path == \"/healthz\"\n\nlevel == \"DEBUG\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defer SetDropRules()
+
+	if err := LoadDropRules(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := DropRules(); len(got) != 2 {
+		t.Errorf("expected 2 rules, got %v", got)
+	}
+
+	if err := os.WriteFile(path, []byte("level ==\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadDropRules(path); err == nil {
+		t.Errorf("expected an error for an invalid rules file")
+	}
+	if got := DropRules(); len(got) != 2 {
+		t.Errorf("invalid file must keep the previous rules, got %v", got)
+	}
+}
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+const fingerprintFrames = 3
+
+var (
+	fingerprintEnabled atomic.Bool
+
+	fingerprintUUID   = regexp.MustCompile(UUIDPattern)
+	fingerprintHex    = regexp.MustCompile(`\b(0x)?[0-9a-fA-F]{8,}\b`)
+	fingerprintNumber = regexp.MustCompile(`\d+`)
+	fingerprintQuoted = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+)
+
+// EnableErrorFingerprint adds a fingerprint field to ERROR and FATAL entries:
+// a stable hash of the normalized message (IDs, numbers and quoted values
+// removed) and the top stack frames of the call site, so recurring errors can
+// be grouped and deduplicated downstream.
+func EnableErrorFingerprint(enabled bool) {
+	fingerprintEnabled.Store(enabled)
+}
+
+// Fingerprint returns the fingerprint of a message logged from the given
+// function names, outermost last.
+func Fingerprint(message string, frames []string) string {
+	h := sha1.New()
+	h.Write([]byte(normalizeForFingerprint(message)))
+	for _, f := range frames {
+		h.Write([]byte{'\n'})
+		h.Write([]byte(f))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func normalizeForFingerprint(msg string) string {
+	msg = fingerprintQuoted.ReplaceAllString(msg, "?")
+	msg = fingerprintUUID.ReplaceAllString(msg, "<id>")
+	msg = fingerprintHex.ReplaceAllString(msg, "<hex>")
+	msg = fingerprintNumber.ReplaceAllString(msg, "#")
+	return strings.ToLower(strings.Join(strings.Fields(msg), " "))
+}
+
+func addFingerprint(level logLevel, fields map[string]interface{}) {
+	if !fingerprintEnabled.Load() || (level != LevelError && level != LevelFatal) {
+		return
+	}
+	msg := fmt.Sprint(fields["message"])
+	if err, ok := fields["error"]; ok {
+		msg += " " + fmt.Sprint(err)
+	}
+	fields["fingerprint"] = Fingerprint(msg, callerFunctions(fingerprintFrames))
+}
+
+// callerFunctions returns the names of the first n functions on the stack
+// outside this package and the log package.
+func callerFunctions(n int) []string {
+	pcs := make([]uintptr, 32)
+	count := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:count])
+
+	out := make([]string, 0, n)
+	for len(out) < n {
+		frame, more := frames.Next()
+		if !isLoggerFrame(frame) {
+			out = append(out, frame.Function)
+		}
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+func isLoggerFrame(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+	fn := frame.Function
+	return strings.HasPrefix(fn, "github.com/paaavkata/go-logger.") ||
+		strings.HasPrefix(fn, "log.") ||
+		strings.HasPrefix(fn, "runtime.")
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func logDBError(id int) {
+	Errorf("query %d failed for user 3f2b8c1e-9d4a-4b7e-8f00-1a2b3c4d5e6f", id)
+}
+
+func logOtherError(id int) {
+	Errorf("query %d failed for user 3f2b8c1e-9d4a-4b7e-8f00-1a2b3c4d5e6f", id)
+}
+
+func TestErrorFingerprint_StableAcrossVariableParts(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+	EnableErrorFingerprint(true)
+	defer EnableErrorFingerprint(false)
+
+	logDBError(1)
+	logDBError(2)
+	logOtherError(1)
+	Warning("warnings are not fingerprinted")
+
+	entries := decodeLogLines(t, &buf)
+	first, second, other := entries[0]["fingerprint"], entries[1]["fingerprint"], entries[2]["fingerprint"]
+	if first == nil || first != second {
+		t.Errorf("expected identical fingerprints for the same error site, got %v and %v", first, second)
+	}
+	if first == other {
+		t.Errorf("expected different call sites to produce different fingerprints")
+	}
+	if _, ok := entries[3]["fingerprint"]; ok {
+		t.Errorf("only error entries should be fingerprinted")
+	}
+}
+
+func TestNormalizeForFingerprint(t *testing.T) {
+	got := normalizeForFingerprint(`Timeout after 30s calling "billing" at 0xdeadbeef12`)
+	if got != "timeout after #s calling ? at <hex>" {
+		t.Errorf("unexpected normalization: %q", got)
+	}
+}
@@ -0,0 +1,62 @@
+package logger
+
+import "sync/atomic"
+
+type flattenConfig struct {
+	separator string
+	maxDepth  int
+}
+
+var flattenSettings atomic.Pointer[flattenConfig]
+
+// EnableFlatten makes structured entries flatten nested objects into keys
+// joined by separator ("." when empty), so {"http": {"method": "GET"}} is
+// written as {"http.method": "GET"}. maxDepth limits how many levels are
+// flattened; zero means all. Flattening takes precedence over
+// EnableDotExpansion.
+func EnableFlatten(separator string, maxDepth int) {
+	if separator == "" {
+		separator = "."
+	}
+	flattenSettings.Store(&flattenConfig{separator: separator, maxDepth: maxDepth})
+}
+
+// DisableFlatten turns off flattening.
+func DisableFlatten() {
+	flattenSettings.Store(nil)
+}
+
+func flattening() bool {
+	return flattenSettings.Load() != nil
+}
+
+func flattenFields(fields map[string]interface{}) {
+	cfg := flattenSettings.Load()
+	if cfg == nil {
+		return
+	}
+	// Collect the nested values first: keys added while ranging over a map
+	// may be visited by the same loop and would be flattened past maxDepth.
+	var nestedKeys []string
+	for key, value := range fields {
+		if _, ok := value.(map[string]interface{}); ok {
+			nestedKeys = append(nestedKeys, key)
+		}
+	}
+	for _, key := range nestedKeys {
+		nested := fields[key].(map[string]interface{})
+		delete(fields, key)
+		flattenInto(fields, key, nested, cfg, 1)
+	}
+}
+
+func flattenInto(dst map[string]interface{}, prefix string, src map[string]interface{}, cfg *flattenConfig, depth int) {
+	for k, v := range src {
+		key := prefix + cfg.separator + k
+		if nested, ok := v.(map[string]interface{}); ok && (cfg.maxDepth == 0 || depth < cfg.maxDepth) {
+			flattenInto(dst, key, nested, cfg, depth+1)
+			continue
+		}
+		dst[key] = v
+	}
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFlatten_AllLevels(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+	EnableFlatten("", 0)
+	defer DisableFlatten()
+
+	InfofMap(nil, map[string]interface{}{
+		"http": map[string]interface{}{
+			"method":  "GET",
+			"request": map[string]interface{}{"bytes": 10},
+		},
+	})
+
+	e := decodeLogLines(t, &buf)[0]
+	if e["http.method"] != "GET" || e["http.request.bytes"] != float64(10) || e["http"] != nil {
+		t.Errorf("expected fully flattened keys, got %v", e)
+	}
+}
+
+func TestFlatten_SeparatorAndDepth(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+	EnableFlatten("_", 1)
+	defer DisableFlatten()
+
+	InfofMap(nil, map[string]interface{}{
+		"db": map[string]interface{}{"pool": map[string]interface{}{"idle": 3}, "name": "users"},
+	})
+
+	e := decodeLogLines(t, &buf)[0]
+	if e["db_name"] != "users" {
+		t.Errorf("expected custom separator, got %v", e)
+	}
+	if pool, _ := e["db_pool"].(map[string]interface{}); pool["idle"] != float64(3) {
+		t.Errorf("expected flattening to stop at depth 1, got %v", e["db_pool"])
+	}
+}
@@ -0,0 +1,274 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// FluentdConfig configures the Fluentd forward-protocol sink.
+type FluentdConfig struct {
+	// Addr is the forward input's host:port; the port defaults to 24224.
+	Addr string
+	// Tag is the Fluentd tag of every entry; defaults to "go-logger".
+	Tag string
+	// TLS enables TLS with the given configuration.
+	TLS *tls.Config
+	// SharedKey enables the forward protocol's shared-key handshake;
+	// Username and Password are sent when the input requires user auth.
+	SharedKey string
+	Username  string
+	Password  string
+	// Hostname identifies this client in the handshake; defaults to
+	// os.Hostname.
+	Hostname string
+	// RequireAck waits for the aggregator to acknowledge every entry.
+	RequireAck bool
+	// Timeout bounds connecting, the handshake and acknowledgements;
+	// defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+const defaultFluentdPort = "24224"
+
+// FluentdWriter sends entries to fluentd or fluent-bit with the forward
+// protocol. Pass it to Init as an additional output. JSON entries are sent as
+// records with their fields; other lines become the record's message.
+type FluentdWriter struct {
+	cfg  FluentdConfig
+	addr string
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// NewFluentdWriter validates cfg and connects to the forward input.
+func NewFluentdWriter(cfg FluentdConfig) (*FluentdWriter, error) {
+	if cfg.Addr == "" {
+		return nil, errors.New("fluentd: Addr is required")
+	}
+	addr := cfg.Addr
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, defaultFluentdPort)
+	}
+	if cfg.Tag == "" {
+		cfg.Tag = "go-logger"
+	}
+	if cfg.Hostname == "" {
+		cfg.Hostname, _ = os.Hostname()
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.SharedKey == "" && cfg.Username != "" {
+		return nil, errors.New("fluentd: user auth requires SharedKey")
+	}
+
+	w := &FluentdWriter{cfg: cfg, addr: addr}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *FluentdWriter) connect() error {
+	dialer := &net.Dialer{Timeout: w.cfg.Timeout}
+	var conn net.Conn
+	var err error
+	if w.cfg.TLS != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", w.addr, w.cfg.TLS)
+	} else {
+		conn, err = dialer.Dial("tcp", w.addr)
+	}
+	if err != nil {
+		return fmt.Errorf("fluentd: %w", err)
+	}
+	w.conn = conn
+	w.r = bufio.NewReader(conn)
+
+	if w.cfg.SharedKey != "" {
+		if err := w.handshake(); err != nil {
+			conn.Close()
+			w.conn = nil
+			return fmt.Errorf("fluentd: handshake: %w", err)
+		}
+	}
+	return nil
+}
+
+// handshake performs the HELO / PING / PONG exchange of the forward
+// protocol's shared-key authentication.
+func (w *FluentdWriter) handshake() error {
+	_ = w.conn.SetDeadline(time.Now().Add(w.cfg.Timeout))
+	defer w.conn.SetDeadline(time.Time{})
+
+	helo, err := readMsgpack(w.r)
+	if err != nil {
+		return err
+	}
+	msg, ok := helo.([]interface{})
+	if !ok || len(msg) < 2 || msg[0] != "HELO" {
+		return fmt.Errorf("unexpected message %v", helo)
+	}
+	opts, _ := msg[1].(map[string]interface{})
+	nonce, _ := opts["nonce"].(string)
+	authSalt, _ := opts["auth"].(string)
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	sharedSalt := hex.EncodeToString(salt)
+
+	ping := []interface{}{
+		"PING",
+		w.cfg.Hostname,
+		sharedSalt,
+		sha512Hex(sharedSalt, w.cfg.Hostname, nonce, w.cfg.SharedKey),
+		"",
+		"",
+	}
+	if authSalt != "" {
+		ping[4] = w.cfg.Username
+		ping[5] = sha512Hex(authSalt, w.cfg.Username, w.cfg.Password)
+	}
+	data, err := appendMsgpack(nil, ping)
+	if err != nil {
+		return err
+	}
+	if _, err := w.conn.Write(data); err != nil {
+		return err
+	}
+
+	pong, err := readMsgpack(w.r)
+	if err != nil {
+		return err
+	}
+	msg, ok = pong.([]interface{})
+	if !ok || len(msg) < 5 || msg[0] != "PONG" {
+		return fmt.Errorf("unexpected message %v", pong)
+	}
+	if authorized, _ := msg[1].(bool); !authorized {
+		return fmt.Errorf("rejected: %v", msg[2])
+	}
+	serverHost, _ := msg[3].(string)
+	if msg[4] != sha512Hex(sharedSalt, serverHost, nonce, w.cfg.SharedKey) {
+		return errors.New("server failed shared key verification")
+	}
+	return nil
+}
+
+func sha512Hex(parts ...string) string {
+	h := sha512.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Write sends each line of p as one record.
+func (w *FluentdWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if w.conn == nil {
+			if err := w.connect(); err != nil {
+				return 0, err
+			}
+		}
+		if err := w.send(line); err != nil {
+			w.conn.Close()
+			w.conn = nil
+			return 0, fmt.Errorf("fluentd: %w", err)
+		}
+	}
+	return len(p), nil
+}
+
+// send writes line in the forward protocol's Message mode:
+// [tag, time, record, option].
+func (w *FluentdWriter) send(line []byte) error {
+	record, at := fluentdRecord(line)
+	option := map[string]interface{}{}
+	var chunk string
+	if w.cfg.RequireAck {
+		id := make([]byte, 16)
+		if _, err := rand.Read(id); err != nil {
+			return err
+		}
+		chunk = base64.StdEncoding.EncodeToString(id)
+		option["chunk"] = chunk
+	}
+
+	data, err := appendMsgpack(nil, []interface{}{w.cfg.Tag, msgpackEventTime(at), record, option})
+	if err != nil {
+		return err
+	}
+	if _, err := w.conn.Write(data); err != nil {
+		return err
+	}
+	if chunk == "" {
+		return nil
+	}
+
+	_ = w.conn.SetReadDeadline(time.Now().Add(w.cfg.Timeout))
+	defer w.conn.SetReadDeadline(time.Time{})
+	resp, err := readMsgpack(w.r)
+	if err != nil {
+		return fmt.Errorf("waiting for ack: %w", err)
+	}
+	if m, ok := resp.(map[string]interface{}); !ok || m["ack"] != chunk {
+		return fmt.Errorf("unexpected ack %v", resp)
+	}
+	return nil
+}
+
+// fluentdRecord returns the record and time of an entry written by this
+// package.
+func fluentdRecord(line []byte) (map[string]interface{}, time.Time) {
+	var record map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if len(line) < 2 || line[0] != '{' || dec.Decode(&record) != nil {
+		level, at := syslogEntryMeta(line)
+		return map[string]interface{}{"level": level, "message": string(line)}, at
+	}
+	at := time.Now()
+	if v, ok := record["timestamp"]; ok {
+		if t, ok := parsePassthroughTime(v); ok {
+			at = t
+		}
+	}
+	return record, at
+}
+
+// Close closes the connection to the aggregator.
+func (w *FluentdWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+var _ io.WriteCloser = (*FluentdWriter)(nil)
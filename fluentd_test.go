@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeFluentd accepts one connection, optionally authenticates it with key
+// and acknowledges the records it receives.
+func fakeFluentd(t *testing.T, key string) (addr string, records <-chan []interface{}) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	ch := make(chan []interface{}, 4)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+
+		if key != "" {
+			helo, _ := appendMsgpack(nil, []interface{}{"HELO", map[string]interface{}{"nonce": "n0nce", "auth": "", "keepalive": true}})
+			_, _ = conn.Write(helo)
+			v, err := readMsgpack(r)
+			if err != nil {
+				return
+			}
+			ping := v.([]interface{})
+			salt, host := ping[2].(string), ping[1].(string)
+			ok := ping[0] == "PING" && ping[3] == sha512Hex(salt, host, "n0nce", key)
+			reason := ""
+			if !ok {
+				reason = "shared key mismatch"
+			}
+			pong, _ := appendMsgpack(nil, []interface{}{"PONG", ok, reason, "aggregator", sha512Hex(salt, "aggregator", "n0nce", key)})
+			_, _ = conn.Write(pong)
+			if !ok {
+				return
+			}
+		}
+
+		for {
+			v, err := readMsgpack(r)
+			if err != nil {
+				return
+			}
+			msg := v.([]interface{})
+			ch <- msg
+			if opt, _ := msg[3].(map[string]interface{}); opt["chunk"] != nil {
+				ack, _ := appendMsgpack(nil, map[string]interface{}{"ack": opt["chunk"]})
+				_, _ = conn.Write(ack)
+			}
+		}
+	}()
+	return ln.Addr().String(), ch
+}
+
+func TestFluentdWriter_SharedKeyAndAck(t *testing.T) {
+	addr, records := fakeFluentd(t, "s3cret")
+
+	w, err := NewFluentdWriter(FluentdConfig{Addr: addr, Tag: "app.billing", SharedKey: "s3cret", RequireAck: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if _, err := w.Write([]byte(`{"timestamp":"2025-05-11T19:30:12Z","level":"INFO","message":"hi","n":3}` + "\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-records:
+		if msg[0] != "app.billing" || !time.Time(msg[1].(msgpackEventTime)).Equal(time.Date(2025, 5, 11, 19, 30, 12, 0, time.UTC)) {
+			t.Errorf("unexpected message %v", msg)
+		}
+		record := msg[2].(map[string]interface{})
+		if record["message"] != "hi" || record["n"] != int64(3) {
+			t.Errorf("unexpected record %v", record)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the record")
+	}
+}
+
+func TestFluentdWriter_RejectedSharedKey(t *testing.T) {
+	addr, _ := fakeFluentd(t, "s3cret")
+	if _, err := NewFluentdWriter(FluentdConfig{Addr: addr, SharedKey: "wrong"}); err == nil {
+		t.Errorf("expected the handshake to fail")
+	}
+}
+
+func TestFluentdWriter_TextLine(t *testing.T) {
+	addr, records := fakeFluentd(t, "")
+	w, err := NewFluentdWriter(FluentdConfig{Addr: addr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte("WARNING: 2025/05/11 main.go:3: disk low\n"))
+
+	msg := <-records
+	if record := msg[2].(map[string]interface{}); record["level"] != "WARNING" || msg[0] != "go-logger" {
+		t.Errorf("unexpected message %v", msg)
+	}
+}
@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Special fields recognised by the Cloud Logging agents on GKE and Cloud Run.
+const (
+	gcpTraceKey  = "logging.googleapis.com/trace"
+	gcpSpanKey   = "logging.googleapis.com/spanId"
+	gcpLabelsKey = "logging.googleapis.com/labels"
+)
+
+// gcpLogger is the Cloud Logging counterpart of jsonLogger for plain entries.
+// Its log.Logger is created with log.Llongfile, and the file:line prefix is
+// turned into sourceLocation.
+type gcpLogger struct {
+	logType string
+	writer  io.Writer
+	project string
+}
+
+func (j *gcpLogger) Write(p []byte) (n int, err error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+	file, line, msg := splitCallerPrefix(msg)
+	msg = strings.ReplaceAll(msg, "\n", " ")
+
+	logEntry := map[string]interface{}{
+		"timestamp": time.Now().Format(time.RFC3339),
+		"level":     j.logType,
+		"message":   msg,
+	}
+	if file != "" {
+		logEntry["sourceLocation"] = map[string]interface{}{"file": file, "line": line}
+	}
+	addConcurrencyFields(nil, logEntry)
+	addSeverityNumber(logLevel(j.logType), logEntry)
+	addFingerprint(logLevel(j.logType), logEntry)
+
+	jsonData, err := json.Marshal(gcpEntry(logEntry, j.project))
+	if err != nil {
+		return 0, err
+	}
+	return j.writer.Write(append(jsonData, '\n'))
+}
+
+// splitCallerPrefix removes the "/path/file.go:42: " prefix written by
+// log.Llongfile. The line number is a string, as Cloud Logging expects.
+func splitCallerPrefix(s string) (file, line, rest string) {
+	i := strings.Index(s, ".go:")
+	if i < 0 {
+		return "", "", s
+	}
+	j := strings.Index(s[i:], ": ")
+	if j < 0 {
+		return "", "", s
+	}
+	file, line = s[:i+3], s[i+4:i+j]
+	if _, err := strconv.Atoi(line); err != nil {
+		return "", "", s
+	}
+	return file, line, s[i+j+2:]
+}
+
+// gcpEntry rewrites an entry into the Cloud Logging structured format: level
+// becomes severity, timestamp becomes time, trace_id and span_id become the
+// trace correlation fields, caller and function (see WithCaller) become
+// sourceLocation and service and environment become labels. The trace is
+// qualified with project when it is known.
+func gcpEntry(fields map[string]interface{}, project string) map[string]interface{} {
+	if v, ok := fields["level"]; ok {
+		delete(fields, "level")
+		fields["severity"] = gcpSeverity(logLevel(fmt.Sprint(v)))
+	}
+	if v, ok := fields["timestamp"]; ok {
+		delete(fields, "timestamp")
+		fields["time"] = v
+	}
+	if v, ok := fields["trace_id"]; ok {
+		delete(fields, "trace_id")
+		trace := fmt.Sprint(v)
+		if project != "" && !strings.HasPrefix(trace, "projects/") {
+			trace = "projects/" + project + "/traces/" + trace
+		}
+		fields[gcpTraceKey] = trace
+	}
+	if v, ok := fields["span_id"]; ok {
+		delete(fields, "span_id")
+		fields[gcpSpanKey] = fmt.Sprint(v)
+	}
+	if _, ok := fields["sourceLocation"]; !ok {
+		if loc := gcpSourceLocation(fields); loc != nil {
+			fields["sourceLocation"] = loc
+		}
+	}
+
+	labels := make(map[string]string, 2)
+	for _, k := range []string{"service", "environment"} {
+		if v, ok := fields[k]; ok {
+			delete(fields, k)
+			if s := fmt.Sprint(v); s != "" {
+				labels[k] = s
+			}
+		}
+	}
+	if len(labels) > 0 {
+		fields[gcpLabelsKey] = labels
+	}
+	return fields
+}
+
+// gcpSourceLocation takes the "file.go:42" caller and function fields of a
+// structured entry as a sourceLocation. It returns nil without a caller.
+func gcpSourceLocation(fields map[string]interface{}) map[string]interface{} {
+	caller, _ := fields["caller"].(string)
+	i := strings.LastIndexByte(caller, ':')
+	if i <= 0 {
+		return nil
+	}
+	delete(fields, "caller")
+	loc := map[string]interface{}{"file": caller[:i], "line": caller[i+1:]}
+	if fn, ok := fields["function"].(string); ok {
+		delete(fields, "function")
+		loc["function"] = fn
+	}
+	return loc
+}
+
+// gcpSeverity maps a level to a Cloud Logging LogSeverity by its severity
+// number, so custom levels get the nearest Cloud Logging severity.
+func gcpSeverity(level logLevel) string {
+	switch sev := SeverityNumber(level); {
+	case sev == 0:
+		return "DEFAULT"
+	case sev < 9:
+		return "DEBUG"
+	case sev < 11:
+		return "INFO"
+	case sev < 13:
+		return "NOTICE"
+	case sev < 17:
+		return "WARNING"
+	case sev < 21:
+		return "ERROR"
+	default:
+		return "CRITICAL"
+	}
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGCPFormat_StructuredEntry(t *testing.T) {
+	var buf bytes.Buffer
+	InitWithOptions(WithFormat("gcp"), WithGCPProject("my-project"), WithService("billing", "prod"), WithOutputs(&buf))
+
+	ctx := context.WithValue(context.Background(), "trace_id", "0af7651916cd43dd8448eb211c80319c")
+	WarningfMap(ctx, map[string]interface{}{"message": "quota low", "span_id": "b7ad6b7169203331"})
+
+	e := decodeLogLines(t, &buf)[0]
+	if e["severity"] != "WARNING" || e["message"] != "quota low" || e["time"] == nil {
+		t.Errorf("unexpected entry: %v", e)
+	}
+	if _, ok := e["level"]; ok {
+		t.Errorf("expected level to be replaced by severity, got %v", e)
+	}
+	if e[gcpTraceKey] != "projects/my-project/traces/0af7651916cd43dd8448eb211c80319c" || e[gcpSpanKey] != "b7ad6b7169203331" {
+		t.Errorf("unexpected trace correlation fields: %v", e)
+	}
+	labels, _ := e[gcpLabelsKey].(map[string]interface{})
+	if labels["service"] != "billing" || labels["environment"] != "prod" {
+		t.Errorf("unexpected labels: %v", e[gcpLabelsKey])
+	}
+}
+
+func TestGCPFormat_PlainEntrySourceLocation(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "gcp", "debug")
+
+	Error("payment failed")
+
+	e := decodeLogLines(t, &buf)[0]
+	if e["severity"] != "ERROR" || e["message"] != "payment failed" {
+		t.Errorf("unexpected entry: %v", e)
+	}
+	loc, _ := e["sourceLocation"].(map[string]interface{})
+	if file, _ := loc["file"].(string); !strings.HasSuffix(file, "gcp_test.go") || loc["line"] == "" {
+		t.Errorf("unexpected sourceLocation: %v", e["sourceLocation"])
+	}
+}
+
+func TestGCPFormat_StructuredEntrySourceLocation(t *testing.T) {
+	var buf bytes.Buffer
+	InitWithOptions(WithFormat("gcp"), WithCaller(), WithOutputs(&buf))
+
+	InfofMap(nil, map[string]interface{}{"message": "charged"})
+	line := strconv.Itoa(thisLine() - 1)
+
+	e := decodeLogLines(t, &buf)[0]
+	loc, _ := e["sourceLocation"].(map[string]interface{})
+	if loc["file"] != "gcp_test.go" || loc["line"] != line {
+		t.Errorf("sourceLocation = %v, want gcp_test.go:%s", e["sourceLocation"], line)
+	}
+	if fn, _ := loc["function"].(string); !strings.HasSuffix(fn, ".TestGCPFormat_StructuredEntrySourceLocation") {
+		t.Errorf("sourceLocation function = %q", fn)
+	}
+	if _, ok := e["caller"]; ok {
+		t.Errorf("expected caller to be replaced by sourceLocation, got %v", e)
+	}
+}
+
+func TestGCPSeverity(t *testing.T) {
+	for level, want := range map[logLevel]string{
+		LevelTrace: "DEBUG",
+		LevelDebug: "DEBUG",
+		LevelInfo:  "INFO",
+		LevelWarn:  "WARNING",
+		LevelError: "ERROR",
+		LevelFatal: "CRITICAL",
+		"UNKNOWN":  "DEFAULT",
+	} {
+		if got := gcpSeverity(level); got != want {
+			t.Errorf("gcpSeverity(%s) = %s, want %s", level, got, want)
+		}
+	}
+}
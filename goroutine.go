@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+type contextKey int
+
+const (
+	workerIDKey contextKey = iota
+	profileLabelsKey
+	debugOverrideKey
+)
+
+var goroutineIDEnabled atomic.Bool
+
+// EnableGoroutineID toggles the opt-in goroutine_id field on every entry.
+// Reading the goroutine ID costs a short runtime.Stack call per entry.
+func EnableGoroutineID(enabled bool) {
+	goroutineIDEnabled.Store(enabled)
+}
+
+// WithWorkerID returns a context carrying an application-assigned worker ID,
+// which context-aware log calls attach as the worker_id field.
+func WithWorkerID(ctx context.Context, id interface{}) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, workerIDKey, id)
+}
+
+func addConcurrencyFields(ctx context.Context, fields map[string]interface{}) {
+	if goroutineIDEnabled.Load() {
+		fields["goroutine_id"] = goroutineID()
+	}
+	if ctx != nil {
+		if id := ctx.Value(workerIDKey); id != nil {
+			fields["worker_id"] = id
+		}
+	}
+}
+
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	// The first line looks like "goroutine 123 [running]:".
+	b := bytes.TrimPrefix(buf[:n], []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
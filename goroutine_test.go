@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestGoroutineID_OptIn(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	Info("without id")
+	EnableGoroutineID(true)
+	defer EnableGoroutineID(false)
+	Info("with id")
+
+	entries := decodeLogLines(t, &buf)
+	if _, ok := entries[0]["goroutine_id"]; ok {
+		t.Errorf("goroutine_id must be opt-in")
+	}
+	if id, ok := entries[1]["goroutine_id"].(float64); !ok || id <= 0 {
+		t.Errorf("expected a positive goroutine_id, got %v", entries[1]["goroutine_id"])
+	}
+}
+
+func TestWorkerIDFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	ctx := WithWorkerID(context.Background(), "worker-7")
+	InfofMap(ctx, map[string]interface{}{"event": "job_done"})
+
+	entries := decodeLogLines(t, &buf)
+	if entries[0]["worker_id"] != "worker-7" {
+		t.Errorf("expected worker_id=worker-7, got %v", entries[0]["worker_id"])
+	}
+}
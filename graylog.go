@@ -0,0 +1,313 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GraylogConfig configures the Graylog sink.
+type GraylogConfig struct {
+	// Host and Port address a GELF input; Port defaults to 12201.
+	Host string
+	Port int
+	// Protocol is "udp" (the default) or "tcp".
+	Protocol string
+	// Compression is "gzip" (the default), "zlib" or "none". It only applies
+	// to UDP; GELF over TCP is always uncompressed.
+	Compression string
+	// ChunkSize is the largest UDP datagram sent; larger messages are split
+	// into GELF chunks. Defaults to 1420 bytes.
+	ChunkSize int
+	// Hostname is reported as the GELF host; defaults to os.Hostname.
+	Hostname string
+}
+
+const (
+	defaultGELFPort      = 12201
+	defaultGELFChunkSize = 1420
+	gelfChunkHeaderSize  = 12
+	gelfMaxChunks        = 128
+)
+
+// GraylogWriter sends entries to Graylog as GELF 1.1 messages. Pass it to
+// Init as an additional output; JSON entries keep their fields as GELF
+// additional fields, other lines become the short_message.
+type GraylogWriter struct {
+	network, addr string
+	compression   string
+	chunkSize     int
+	hostname      string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewGraylogWriter validates cfg and connects to the GELF input.
+func NewGraylogWriter(cfg GraylogConfig) (*GraylogWriter, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("graylog: Host is required")
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = defaultGELFPort
+	}
+	network := strings.ToLower(cfg.Protocol)
+	switch network {
+	case "":
+		network = "udp"
+	case "udp", "tcp":
+	default:
+		return nil, fmt.Errorf("graylog: unknown protocol %q (want udp or tcp)", cfg.Protocol)
+	}
+	compression := strings.ToLower(cfg.Compression)
+	switch compression {
+	case "":
+		compression = "gzip"
+	case "gzip", "zlib", "none":
+	default:
+		return nil, fmt.Errorf("graylog: unknown compression %q (want gzip, zlib or none)", cfg.Compression)
+	}
+	chunkSize := cfg.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultGELFChunkSize
+	}
+	if chunkSize <= gelfChunkHeaderSize {
+		return nil, fmt.Errorf("graylog: ChunkSize %d is too small", chunkSize)
+	}
+	hostname := cfg.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+
+	w := &GraylogWriter{
+		network:     network,
+		addr:        net.JoinHostPort(cfg.Host, strconv.Itoa(port)),
+		compression: compression,
+		chunkSize:   chunkSize,
+		hostname:    hostname,
+	}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *GraylogWriter) connect() error {
+	conn, err := net.DialTimeout(w.network, w.addr, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("graylog: %w", err)
+	}
+	w.conn = conn
+	return nil
+}
+
+// Write sends each line of p as one GELF message.
+func (w *GraylogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		msg, err := encodeGELF(line, w.hostname)
+		if err != nil {
+			return 0, fmt.Errorf("graylog: %w", err)
+		}
+		if w.conn == nil {
+			if err := w.connect(); err != nil {
+				return 0, err
+			}
+		}
+		if err := w.send(msg); err != nil {
+			w.conn.Close()
+			w.conn = nil
+			return 0, fmt.Errorf("graylog: %w", err)
+		}
+	}
+	return len(p), nil
+}
+
+func (w *GraylogWriter) send(msg []byte) error {
+	if w.network == "tcp" {
+		// GELF TCP frames are null-byte delimited.
+		_, err := w.conn.Write(append(msg, 0))
+		return err
+	}
+
+	msg, err := compressGELF(msg, w.compression)
+	if err != nil {
+		return err
+	}
+	if len(msg) <= w.chunkSize {
+		_, err := w.conn.Write(msg)
+		return err
+	}
+	chunks, err := chunkGELF(msg, w.chunkSize)
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the connection to Graylog.
+func (w *GraylogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+var _ io.WriteCloser = (*GraylogWriter)(nil)
+
+// encodeGELF converts an entry written by this package into a GELF 1.1
+// message. The level, message and timestamp map onto the GELF fields and the
+// remaining fields become additional fields.
+func encodeGELF(line []byte, hostname string) ([]byte, error) {
+	line = bytes.TrimSpace(line)
+
+	var fields map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if len(line) < 2 || line[0] != '{' || dec.Decode(&fields) != nil {
+		fields = map[string]interface{}{"message": string(line)}
+	}
+
+	level := LevelInfo
+	if v, _, ok := takeFirst(fields, []string{"level", "severity"}); ok {
+		level = logLevel(fmt.Sprint(v))
+	}
+	at := time.Now()
+	if v, _, ok := takeFirst(fields, passthroughTimestampKeys); ok {
+		if t, ok := parsePassthroughTime(v); ok {
+			at = t
+		}
+	}
+	short := ""
+	if v, _, ok := takeFirst(fields, passthroughMessageKeys); ok {
+		short = fmt.Sprint(v)
+	}
+	if short == "" {
+		// short_message is required.
+		short = "-"
+	}
+
+	msg := map[string]interface{}{
+		"version":       "1.1",
+		"host":          hostname,
+		"short_message": short,
+		"timestamp":     math.Round(float64(at.UnixNano())/1e6) / 1e3,
+		"level":         syslogSeverity(level),
+		"_level_name":   string(level),
+	}
+	for k, v := range fields {
+		msg[gelfFieldName(k)] = gelfValue(v)
+	}
+	return json.Marshal(msg)
+}
+
+// gelfFieldName turns a key into a GELF additional field name, which must
+// start with an underscore, match [\w.-]+ and not be "_id".
+func gelfFieldName(k string) string {
+	name := "_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, k)
+	if name == "_id" {
+		return "_id_"
+	}
+	return name
+}
+
+// gelfValue returns v as a string or number; GELF has no nested values.
+func gelfValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case string, json.Number, float64, int, int64:
+		return t
+	case bool:
+		return strconv.FormatBool(t)
+	case nil:
+		return ""
+	default:
+		data, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Sprint(t)
+		}
+		return string(data)
+	}
+}
+
+func compressGELF(msg []byte, compression string) ([]byte, error) {
+	var buf bytes.Buffer
+	var zw io.WriteCloser
+	switch compression {
+	case "gzip":
+		zw = gzip.NewWriter(&buf)
+	case "zlib":
+		zw = zlib.NewWriter(&buf)
+	default:
+		return msg, nil
+	}
+	if _, err := zw.Write(msg); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// chunkGELF splits msg into GELF chunks of at most size bytes: each starts
+// with the magic bytes 0x1e 0x0f, an 8-byte message ID, the chunk's sequence
+// number and the chunk count.
+func chunkGELF(msg []byte, size int) ([][]byte, error) {
+	payload := size - gelfChunkHeaderSize
+	count := (len(msg) + payload - 1) / payload
+	if count > gelfMaxChunks {
+		return nil, fmt.Errorf("message of %d bytes needs %d chunks, more than the GELF limit of %d", len(msg), count, gelfMaxChunks)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * payload
+		if end > len(msg) {
+			end = len(msg)
+		}
+		chunk := make([]byte, 0, gelfChunkHeaderSize+end-i*payload)
+		chunk = append(chunk, 0x1e, 0x0f)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, msg[i*payload:end]...)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
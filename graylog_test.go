@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeGELF(t *testing.T) {
+	line := []byte(`{"timestamp":"2025-05-11T19:30:12Z","level":"WARNING","message":"disk low","service":"billing","id":7,"tags":["a"]}`)
+	data, err := encodeGELF(line, "node-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg map[string]interface{}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg["version"] != "1.1" || msg["host"] != "node-1" || msg["short_message"] != "disk low" || msg["level"] != float64(4) {
+		t.Errorf("unexpected GELF message: %v", msg)
+	}
+	if msg["timestamp"] != float64(1746991812) {
+		t.Errorf("unexpected timestamp %v", msg["timestamp"])
+	}
+	if msg["_service"] != "billing" || msg["_id_"] != float64(7) || msg["_tags"] != `["a"]` {
+		t.Errorf("unexpected additional fields: %v", msg)
+	}
+
+	data, _ = encodeGELF([]byte("INFO: 2025/05/11 plain text"), "node-1")
+	if !strings.Contains(string(data), `"short_message":"INFO: 2025/05/11 plain text"`) {
+		t.Errorf("expected text line as short_message, got %s", data)
+	}
+}
+
+func TestGraylogWriter_UDPChunking(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	port := pc.LocalAddr().(*net.UDPAddr).Port
+
+	w, err := NewGraylogWriter(GraylogConfig{Host: "127.0.0.1", Port: port, Compression: "none", ChunkSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	long := strings.Repeat("x", 300)
+	if _, err := w.Write([]byte(`{"level":"ERROR","message":"` + long + `"}` + "\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	var payload []byte
+	count := -1
+	for seen := 0; count < 0 || seen < count; seen++ {
+		buf := make([]byte, 200)
+		_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n > 100 || buf[0] != 0x1e || buf[1] != 0x0f || int(buf[10]) != seen {
+			t.Fatalf("unexpected chunk %d: % x", seen, buf[:12])
+		}
+		count = int(buf[11])
+		payload = append(payload, buf[12:n]...)
+	}
+	if !bytes.Contains(payload, []byte(long)) || !bytes.Contains(payload, []byte(`"level":3`)) {
+		t.Errorf("unexpected reassembled message: %s", payload)
+	}
+}
+
+func TestGraylogWriter_UDPGzip(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	w, err := NewGraylogWriter(GraylogConfig{Host: "127.0.0.1", Port: pc.LocalAddr().(*net.UDPAddr).Port})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte(`{"level":"INFO","message":"hello"}` + "\n"))
+
+	buf := make([]byte, 2048)
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(buf[:n]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(zr)
+	if !bytes.Contains(data, []byte(`"short_message":"hello"`)) {
+		t.Errorf("unexpected message: %s", data)
+	}
+}
+
+func TestGraylogWriter_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	got := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			frame, err := r.ReadString(0)
+			if err != nil {
+				return
+			}
+			got <- strings.TrimSuffix(frame, "\x00")
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+	w, err := NewGraylogWriter(GraylogConfig{Host: "127.0.0.1", Port: p, Protocol: "tcp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte(`{"message":"one"}` + "\n" + `{"message":"two"}` + "\n"))
+
+	for _, want := range []string{"one", "two"} {
+		select {
+		case frame := <-got:
+			if !strings.Contains(frame, `"short_message":"`+want+`"`) {
+				t.Errorf("unexpected frame %s", frame)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for frame")
+		}
+	}
+}
+
+func TestNewGraylogWriter_Validation(t *testing.T) {
+	for _, cfg := range []GraylogConfig{
+		{},
+		{Host: "localhost", Protocol: "http"},
+		{Host: "localhost", Compression: "lz4"},
+		{Host: "localhost", ChunkSize: 8},
+	} {
+		if _, err := NewGraylogWriter(cfg); err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"maps"
+	"strings"
+	"sync/atomic"
+)
+
+var dotExpansionEnabled atomic.Bool
+
+// Group builds a nested field group from alternating keys and values, in the
+// spirit of slog.Group:
+//
+//	logger.InfofMap(ctx, map[string]interface{}{
+//		"http": logger.Group("method", r.Method, "status", 200),
+//	})
+func Group(keysAndValues ...interface{}) map[string]interface{} {
+	group := make(map[string]interface{}, len(keysAndValues)/2)
+	addKeysAndValues(group, keysAndValues)
+	return group
+}
+
+// EnableDotExpansion makes structured entries expand dotted keys into nested
+// objects at encode time, so {"http.method": "GET", "http.status": 200}
+// is written as {"http": {"method": "GET", "status": 200}}, matching
+// semantic-convention schemas. A dotted key whose prefix is already used by a
+// non-object value is left as-is.
+func EnableDotExpansion(enabled bool) {
+	dotExpansionEnabled.Store(enabled)
+}
+
+func expandDottedKeys(fields map[string]interface{}) {
+	if !dotExpansionEnabled.Load() || flattening() {
+		return
+	}
+	for key, value := range fields {
+		if !strings.Contains(key, ".") {
+			continue
+		}
+		parts := strings.Split(key, ".")
+		if !validDottedKey(parts) {
+			continue
+		}
+		if insertNested(fields, parts, value) {
+			delete(fields, key)
+		}
+	}
+}
+
+func validDottedKey(parts []string) bool {
+	for _, p := range parts {
+		if p == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// insertNested stores value under the path, creating intermediate objects.
+// It reports false if a non-object value is in the way.
+func insertNested(m map[string]interface{}, path []string, value interface{}) bool {
+	for _, p := range path[:len(path)-1] {
+		next, exists := m[p]
+		if !exists {
+			child := map[string]interface{}{}
+			m[p] = child
+			m = child
+			continue
+		}
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		// Nested maps belong to the caller; insert into a copy.
+		child = maps.Clone(child)
+		m[p] = child
+		m = child
+	}
+	last := path[len(path)-1]
+	if existing, ok := m[last]; ok {
+		if _, isMap := existing.(map[string]interface{}); isMap {
+			return false
+		}
+	}
+	m[last] = value
+	return true
+}
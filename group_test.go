@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGroup_NestsFields(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	InfofMap(nil, map[string]interface{}{"http": Group("method", "GET", "status", 200)})
+
+	entries := decodeLogLines(t, &buf)
+	http, ok := entries[0]["http"].(map[string]interface{})
+	if !ok || http["method"] != "GET" || http["status"] != float64(200) {
+		t.Errorf("expected nested http group, got %v", entries[0]["http"])
+	}
+}
+
+func TestDotExpansion(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+	EnableDotExpansion(true)
+	defer EnableDotExpansion(false)
+
+	InfofMap(nil, map[string]interface{}{
+		"http.method":        "POST",
+		"http.status":        201,
+		"db.statement":       "SELECT 1",
+		"user":               "alice",
+		"user.id":            7,
+		"http.request.bytes": 512,
+	})
+
+	e := decodeLogLines(t, &buf)[0]
+	http, ok := e["http"].(map[string]interface{})
+	if !ok || http["method"] != "POST" || http["status"] != float64(201) {
+		t.Fatalf("expected expanded http object, got %v", e)
+	}
+	if req, _ := http["request"].(map[string]interface{}); req["bytes"] != float64(512) {
+		t.Errorf("expected deeper nesting, got %v", http["request"])
+	}
+	if db, _ := e["db"].(map[string]interface{}); db["statement"] != "SELECT 1" {
+		t.Errorf("expected expanded db object, got %v", e["db"])
+	}
+	if e["user"] != "alice" || e["user.id"] != float64(7) {
+		t.Errorf("conflicting dotted key must be left as-is, got %v / %v", e["user"], e["user.id"])
+	}
+}
@@ -0,0 +1,69 @@
+package logger
+
+import "time"
+
+// SinkStatus is the delivery health of one output, as returned by
+// SinkHealth.
+type SinkStatus struct {
+	Name string
+	// Healthy is false while the output's most recent write failed, its last
+	// background delivery failed, or its circuit breaker is open.
+	Healthy       bool
+	LastError     string
+	LastErrorAt   time.Time
+	LastSuccessAt time.Time
+	// QueueDepth is the number of entries accepted but not yet delivered,
+	// e.g. spooled Kafka messages.
+	QueueDepth  int
+	CircuitOpen bool
+}
+
+// healthSource is implemented by outputs that know more about their state
+// than the fan-out observes.
+type healthSource interface {
+	sinkHealth(h *SinkStatus)
+}
+
+// SinkHealth returns the health of every configured output, e.g. for a
+// readiness probe that reports degraded log shipping.
+func SinkHealth() []SinkStatus {
+	return std.SinkHealth()
+}
+
+// SinkHealth returns the health of every output of l.
+func (l *Logger) SinkHealth() []SinkStatus {
+	f := l.load().fanout
+	if f == nil {
+		return nil
+	}
+	out := make([]SinkStatus, len(f.sinks))
+	for i, s := range f.sinks {
+		out[i] = s.health()
+	}
+	return out
+}
+
+func (s *sinkWriter) health() SinkStatus {
+	st := s.stats()
+	h := SinkStatus{Name: s.name, LastError: st.LastError, LastErrorAt: st.LastErrorAt}
+	if ns := s.lastSuccess.Load(); ns != 0 {
+		h.LastSuccessAt = time.Unix(0, ns)
+	}
+	h.Healthy = h.LastErrorAt.IsZero() || h.LastSuccessAt.After(h.LastErrorAt)
+
+	// Batching outputs such as WebhookWriter report delivery failures after
+	// their Write has returned.
+	if le, ok := s.w.(interface{ LastError() error }); ok {
+		if err := le.LastError(); err != nil {
+			h.Healthy = false
+			h.LastError = err.Error()
+		}
+	}
+	if q, ok := s.w.(queueSource); ok {
+		h.QueueDepth = q.queueStats().Depth
+	}
+	if hs, ok := s.w.(healthSource); ok {
+		hs.sinkHealth(&h)
+	}
+	return h
+}
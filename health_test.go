@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// asyncFailingWriter accepts writes but reports a background delivery error.
+type asyncFailingWriter struct{ bytes.Buffer }
+
+func (w *asyncFailingWriter) LastError() error { return errors.New("batch rejected") }
+
+func TestSinkHealth(t *testing.T) {
+	var ok bytes.Buffer
+	f := newFanout([]*sinkWriter{
+		newSinkWriter("file", &ok),
+		newSinkWriter("kafka", failingWriter{}),
+		newSinkWriter("webhook", &asyncFailingWriter{}),
+	})
+	std.state.Store(&loggerState{fanout: f})
+	defer std.state.Store(nil)
+
+	before := time.Now()
+	_, _ = f.Write([]byte("x\n"))
+
+	health := SinkHealth()
+	if len(health) != 3 {
+		t.Fatalf("got %d sinks, want 3", len(health))
+	}
+	if h := health[0]; !h.Healthy || h.LastSuccessAt.Before(before) || h.LastError != "" {
+		t.Errorf("unexpected file health %+v", h)
+	}
+	if h := health[1]; h.Healthy || h.LastError != "loki unreachable" || h.LastErrorAt.IsZero() {
+		t.Errorf("unexpected kafka health %+v", h)
+	}
+	if h := health[2]; h.Healthy || h.LastError != "batch rejected" {
+		t.Errorf("expected the background error to make the webhook unhealthy, got %+v", h)
+	}
+}
+
+func TestSinkHealth_KafkaSpoolAndBreaker(t *testing.T) {
+	fake := &fakeProducer{down: true}
+	w := testKafkaWriter(t, KafkaConfig{
+		Brokers: []string{"k:9092"}, Topic: "logs", SpoolDir: t.TempDir(), BreakerThreshold: 1, BreakerCooldownMS: 60000,
+	})
+	defer w.Close()
+	w.produce = fake.produce
+	w.breaker.warn = func(string) {}
+
+	s := newSinkWriter("kafka", w)
+	for i := 0; i < 2; i++ {
+		if _, err := s.Write([]byte(`{"message":"x"}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if h := s.health(); h.Healthy || !h.CircuitOpen || h.QueueDepth != 2 {
+		t.Errorf("unexpected health %+v, want an open breaker and 2 spooled entries", h)
+	}
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrDropEntry is returned by a hook to veto the entry: it is not written and
+// the remaining hooks don't run.
+var ErrDropEntry = errors.New("logger: drop entry")
+
+// Entry is an entry passed to hooks before it is encoded.
+type Entry struct {
+	Level   Level
+	Time    time.Time
+	Message string
+	// Fields are the entry's fields, which hooks may change. For plain
+	// entries they are the bound and context fields. The map may be reused
+	// once the entry is written, so hooks must not keep it.
+	Fields  map[string]interface{}
+	Context context.Context
+}
+
+type entryHook struct {
+	levels map[logLevel]bool // nil means every level
+	fn     func(*Entry) error
+}
+
+// AddHook adds a hook to the package-level logger. See Logger.AddHook.
+func AddHook(levels []Level, fn func(*Entry) error) {
+	std.AddHook(levels, fn)
+}
+
+// AddHook runs fn for each entry at one of levels, or at every level when
+// levels is empty, before it is encoded. A hook can enrich the entry through
+// its Fields and Message, count it, or veto it by returning ErrDropEntry;
+// other errors are reported as diagnostics and the entry is still written. Hooks
+// run in the order they were added, on the goroutine that logs, after drop
+// rules and transforms and before scrubbers and redaction. They are shared
+// with the Logger's children and kept when it is reconfigured.
+func (l *Logger) AddHook(levels []Level, fn func(*Entry) error) {
+	h := entryHook{fn: fn}
+	if len(levels) > 0 {
+		h.levels = make(map[logLevel]bool, len(levels))
+		for _, level := range levels {
+			h.levels[level] = true
+		}
+	}
+	root := l.root()
+	for {
+		old := root.hooks.Load()
+		var next []entryHook
+		if old != nil {
+			next = append(next, *old...)
+		}
+		next = append(next, h)
+		if root.hooks.CompareAndSwap(old, &next) {
+			return
+		}
+	}
+}
+
+// runHooks runs the hooks for e and reports whether it is still written.
+func (l *Logger) runHooks(e *Entry) bool {
+	hooks := l.root().hooks.Load()
+	if hooks == nil {
+		return true
+	}
+	for _, h := range *hooks {
+		if h.levels != nil && !h.levels[e.Level] {
+			continue
+		}
+		if err := h.fn(e); errors.Is(err, ErrDropEntry) {
+			return false
+		} else if err != nil {
+			diagnose("hook", err, "hook failed")
+		}
+	}
+	return true
+}
+
+// hooksStructured runs the hooks for a structured entry and reports whether
+// it is still written.
+func (l *Logger) hooksStructured(ctx context.Context, level logLevel, at time.Time, fields map[string]interface{}) bool {
+	if l.root().hooks.Load() == nil {
+		return true
+	}
+	msg, _ := fields["message"].(string)
+	e := &Entry{Level: level, Time: at, Message: msg, Fields: fields, Context: ctx}
+	if !l.runHooks(e) {
+		return false
+	}
+	if e.Message != msg {
+		fields["message"] = e.Message
+	}
+	return true
+}
+
+// hooksPlain runs the hooks for a plain entry, returning its message and
+// extra fields as the hooks left them, and whether it is still written.
+func (l *Logger) hooksPlain(ctx context.Context, level logLevel, msg string, extra map[string]interface{}) (string, map[string]interface{}, bool) {
+	if l.root().hooks.Load() == nil {
+		return msg, extra, true
+	}
+	fields := make(map[string]interface{}, len(extra))
+	for k, v := range extra {
+		fields[k] = v
+	}
+	e := &Entry{Level: level, Time: time.Now(), Message: msg, Fields: fields, Context: ctx}
+	ok := l.runHooks(e)
+	return e.Message, e.Fields, ok
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHooks_EnrichCountAndVeto(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithFormat("json"), WithOutputs(&out))
+	errorsSeen := 0
+	l.AddHook([]Level{LevelError}, func(e *Entry) error {
+		errorsSeen++
+		return nil
+	})
+	l.AddHook(nil, func(e *Entry) error {
+		if e.Message == "healthz" {
+			return ErrDropEntry
+		}
+		e.Fields["flags"] = "new-checkout"
+		return nil
+	})
+
+	child := l.WithField("component", "api")
+	child.Error("payment failed")
+	l.InfofMap(context.Background(), map[string]interface{}{"message": "healthz"})
+	l.Info("plain entry")
+
+	entries := decodeLogLines(t, &out)
+	if len(entries) != 2 {
+		t.Fatalf("expected the vetoed entry to be dropped, got %d entries", len(entries))
+	}
+	if entries[0]["flags"] != "new-checkout" || entries[0]["component"] != "api" || entries[1]["flags"] != "new-checkout" {
+		t.Errorf("expected hooks to enrich entries, got %v", entries)
+	}
+	if errorsSeen != 1 {
+		t.Errorf("expected the error hook to run once, ran %d times", errorsSeen)
+	}
+}
+
+func TestHooks_TextAndErrors(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithOutputs(&out))
+	l.AddHook(nil, func(e *Entry) error {
+		e.Message = strings.ToUpper(e.Message)
+		e.Fields["via"] = "hook"
+		return errors.New("metrics backend down")
+	})
+	l.Warning("low disk")
+	if got := out.String(); !strings.Contains(got, "LOW DISK via=hook") {
+		t.Errorf("expected a failing hook to keep the entry with its changes, got %q", got)
+	}
+}
@@ -0,0 +1,180 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultDumpMaxBody = 4096
+
+var defaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+var defaultRedactedQuery = []string{
+	"access_token", "refresh_token", "id_token", "token", "api_key", "apikey",
+	"password", "secret", "client_secret", "signature", "sig",
+}
+
+// DumpOptions controls what DumpRequest and DumpResponse log.
+type DumpOptions struct {
+	// IncludeBody logs the body, capped at MaxBodyBytes. The body remains
+	// fully readable by the caller afterwards.
+	IncludeBody bool
+	// MaxBodyBytes caps the logged body size. Defaults to 4KB.
+	MaxBodyBytes int
+	// RedactHeaders lists additional headers whose values are masked.
+	// Authorization, Proxy-Authorization, Cookie and Set-Cookie are always masked.
+	RedactHeaders []string
+	// RedactQuery lists additional query parameters whose values are masked
+	// in the logged URL. Common credential parameters such as access_token,
+	// api_key and signature, and the keys of the logger's redaction
+	// settings, are always masked. User info is never logged.
+	RedactQuery []string
+	// Level is the level of the dump entry. Defaults to debug.
+	Level logLevel
+}
+
+// DumpRequest logs the method, URL, headers and optionally the body of r.
+func DumpRequest(ctx context.Context, r *http.Request, opts *DumpOptions) {
+	if r == nil {
+		return
+	}
+	o := dumpDefaults(opts)
+	if !shouldLogCtx(ctx, o.Level) {
+		return
+	}
+
+	fields := map[string]interface{}{
+		"message":      "http request dump",
+		"http_method":  r.Method,
+		"http_url":     redactURL(r.URL, o.RedactQuery),
+		"http_proto":   r.Proto,
+		"http_headers": redactHeaders(r.Header, o.RedactHeaders),
+	}
+	if r.Host != "" {
+		fields["http_host"] = r.Host
+	}
+	if o.IncludeBody && r.Body != nil && r.Body != http.NoBody {
+		body, truncated, rest := peekBody(r.Body, o.MaxBodyBytes)
+		r.Body = rest
+		fields["http_body"] = body
+		if truncated {
+			fields["http_body_truncated"] = true
+		}
+	}
+
+	logWithMap(o.Level, ctx, fields)
+}
+
+// DumpResponse logs the status, headers and optionally the body of resp.
+func DumpResponse(ctx context.Context, resp *http.Response, opts *DumpOptions) {
+	if resp == nil {
+		return
+	}
+	o := dumpDefaults(opts)
+	if !shouldLogCtx(ctx, o.Level) {
+		return
+	}
+
+	fields := map[string]interface{}{
+		"message":      "http response dump",
+		"http_status":  resp.StatusCode,
+		"http_proto":   resp.Proto,
+		"http_headers": redactHeaders(resp.Header, o.RedactHeaders),
+	}
+	if resp.Request != nil {
+		fields["http_method"] = resp.Request.Method
+		fields["http_url"] = redactURL(resp.Request.URL, o.RedactQuery)
+	}
+	if o.IncludeBody && resp.Body != nil && resp.Body != http.NoBody {
+		body, truncated, rest := peekBody(resp.Body, o.MaxBodyBytes)
+		resp.Body = rest
+		fields["http_body"] = body
+		if truncated {
+			fields["http_body_truncated"] = true
+		}
+	}
+
+	logWithMap(o.Level, ctx, fields)
+}
+
+func dumpDefaults(opts *DumpOptions) DumpOptions {
+	var o DumpOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.MaxBodyBytes <= 0 {
+		o.MaxBodyBytes = defaultDumpMaxBody
+	}
+	if o.Level == "" {
+		o.Level = LevelDebug
+	}
+	return o
+}
+
+func redactHeaders(h http.Header, extra []string) map[string]string {
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		out[name] = strings.Join(values, ", ")
+	}
+	for _, name := range append(defaultRedactedHeaders, extra...) {
+		key := http.CanonicalHeaderKey(name)
+		if _, ok := out[key]; ok {
+			out[key] = "[REDACTED]"
+		}
+	}
+	return out
+}
+
+// redactURL returns u without user info and with the values of sensitive
+// query parameters masked. The order and encoding of the other parameters
+// are kept.
+func redactURL(u *url.URL, extra []string) string {
+	if u == nil {
+		return ""
+	}
+	clean := *u
+	clean.User = nil
+	if clean.RawQuery == "" {
+		return clean.String()
+	}
+	sensitive := make(map[string]bool, len(defaultRedactedQuery)+len(extra))
+	for _, name := range append(defaultRedactedQuery, extra...) {
+		sensitive[strings.ToLower(name)] = true
+	}
+	r := std.load().redactor
+	params := strings.Split(clean.RawQuery, "&")
+	for i, param := range params {
+		rawKey, _, _ := strings.Cut(param, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+		if sensitive[strings.ToLower(key)] || r != nil && r.sensitiveKey(key) {
+			params[i] = rawKey + "=[REDACTED]"
+		}
+	}
+	clean.RawQuery = strings.Join(params, "&")
+	return clean.String()
+}
+
+// peekBody reads up to max bytes of body and returns them together with a
+// replacement ReadCloser that still yields the full, unconsumed body.
+func peekBody(body io.ReadCloser, max int) (string, bool, io.ReadCloser) {
+	buf := make([]byte, max+1)
+	n, _ := io.ReadFull(body, buf)
+	buf = buf[:n]
+
+	rest := struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), body), body}
+
+	if n > max {
+		return string(buf[:max]), true, rest
+	}
+	return string(buf), false, rest
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDumpRequest_RedactsAndPreservesBody(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	r := httptest.NewRequest(http.MethodPost, "http://api.local/charge", strings.NewReader("0123456789"))
+	r.Header.Set("Authorization", "Bearer secret")
+	r.Header.Set("X-Api-Key", "k")
+	r.Header.Set("Content-Type", "text/plain")
+
+	DumpRequest(context.Background(), r, &DumpOptions{IncludeBody: true, MaxBodyBytes: 4, RedactHeaders: []string{"x-api-key"}})
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected one dump entry, got %d", len(entries))
+	}
+	headers := entries[0]["http_headers"].(map[string]interface{})
+	if headers["Authorization"] != "[REDACTED]" || headers["X-Api-Key"] != "[REDACTED]" {
+		t.Errorf("expected sensitive headers to be redacted, got %v", headers)
+	}
+	if headers["Content-Type"] != "text/plain" {
+		t.Errorf("expected other headers to be kept, got %v", headers["Content-Type"])
+	}
+	if entries[0]["http_body"] != "0123" || entries[0]["http_body_truncated"] != true {
+		t.Errorf("expected truncated body, got %v", entries[0]["http_body"])
+	}
+
+	rest, _ := io.ReadAll(r.Body)
+	if string(rest) != "0123456789" {
+		t.Errorf("request body must stay readable, got %q", rest)
+	}
+}
+
+func TestDumpResponse_RespectsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "info")
+
+	resp := &http.Response{StatusCode: 200, Header: http.Header{"Set-Cookie": {"sid=1"}}}
+	DumpResponse(context.Background(), resp, nil)
+	if buf.Len() != 0 {
+		t.Fatalf("debug dump should be filtered at info level")
+	}
+
+	DumpResponse(context.Background(), resp, &DumpOptions{Level: LevelInfo})
+	entries := decodeLogLines(t, &buf)
+	headers := entries[0]["http_headers"].(map[string]interface{})
+	if headers["Set-Cookie"] != "[REDACTED]" || entries[0]["http_status"] != float64(200) {
+		t.Errorf("unexpected response dump: %v", entries[0])
+	}
+}
+
+func TestDumpRequest_RedactsURLCredentials(t *testing.T) {
+	var buf bytes.Buffer
+	InitWithOptions(WithFormat("json"), WithLevel("debug"), WithOutputs(&buf), WithRedaction(RedactConfig{Keys: []string{"session*"}}))
+	defer initTestLogger(&bytes.Buffer{}, "json", "info")
+
+	r := httptest.NewRequest(http.MethodGet, "http://api.local/export?format=csv&access_token=abc&sessionId=s1&tenant=acme&X-Amz-Signature=f00", nil)
+	r.URL.User = url.UserPassword("admin", "hunter2")
+	DumpRequest(context.Background(), r, &DumpOptions{RedactQuery: []string{"x-amz-signature"}})
+
+	got, _ := decodeLogLines(t, &buf)[0]["http_url"].(string)
+	want := "http://api.local/export?format=csv&access_token=[REDACTED]&sessionId=[REDACTED]&tenant=acme&X-Amz-Signature=[REDACTED]"
+	if got != want {
+		t.Errorf("http_url = %s, want %s", got, want)
+	}
+}
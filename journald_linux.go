@@ -0,0 +1,25 @@
+package logger
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// sendJournalFile writes data to an unlinked temporary file and passes its
+// descriptor to the journal, as sd_journal_send does for large entries.
+func sendJournalFile(conn *net.UnixConn, addr *net.UnixAddr, data []byte) error {
+	f, err := os.CreateTemp("/dev/shm", "go-logger-journal-")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := os.Remove(f.Name()); err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	_, _, err = conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), addr)
+	return err
+}
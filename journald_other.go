@@ -0,0 +1,12 @@
+//go:build !linux
+
+package logger
+
+import (
+	"errors"
+	"net"
+)
+
+func sendJournalFile(conn *net.UnixConn, addr *net.UnixAddr, data []byte) error {
+	return errors.New("entry too large for the journal socket")
+}
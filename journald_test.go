@@ -0,0 +1,70 @@
+//go:build linux
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeJournal(t *testing.T) {
+	line := []byte(`{"timestamp":"2025-05-11T19:30:12Z","level":"ERROR","message":"payment failed","user_id":42,"trace-id":"abc","_hidden":1,"stack":"a\nb"}`)
+	got := string(encodeJournal(line, "billing"))
+
+	for _, want := range []string{
+		"MESSAGE=payment failed\n",
+		"PRIORITY=3\n",
+		"SYSLOG_IDENTIFIER=billing\n",
+		"USER_ID=42\n",
+		"TRACE_ID=abc\n",
+		"HIDDEN=1\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in %q", want, got)
+		}
+	}
+	if strings.Contains(got, "TIMESTAMP") {
+		t.Errorf("expected the timestamp to be left to the journal, got %q", got)
+	}
+
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], 3)
+	if !strings.Contains(got, "STACK\n"+string(length[:])+"a\nb\n") {
+		t.Errorf("expected binary encoding for multi-line values, got %q", got)
+	}
+}
+
+func TestJournaldWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	pc, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	w, err := NewJournaldWriter(JournaldConfig{SocketPath: path, Identifier: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte("WARNING: 2025/05/11 19:30:12 main.go:10: disk low\n"))
+
+	buf := make([]byte, 1024)
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buf[:n]; !bytes.Contains(got, []byte("PRIORITY=4\n")) || !bytes.Contains(got, []byte("MESSAGE=WARNING: 2025/05/11 19:30:12 main.go:10: disk low\n")) {
+		t.Errorf("unexpected journal entry %q", got)
+	}
+
+	if _, err := NewJournaldWriter(JournaldConfig{SocketPath: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Errorf("expected an error without a journal socket")
+	}
+}
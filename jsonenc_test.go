@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+type upperName string
+
+func (n upperName) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.ToUpper(string(n)))
+}
+
+// marshalerError implements both error and json.Marshaler; MarshalJSON wins.
+type marshalerError struct{}
+
+func (marshalerError) Error() string                { return "plain" }
+func (marshalerError) MarshalJSON() ([]byte, error) { return []byte(`{"code":7}`), nil }
+
+type pathError struct{ path string }
+
+func (e *pathError) Error() string { return "open " + e.path }
+
+func TestWriteJSON_MatchesEncodingJSON(t *testing.T) {
+	fields := map[string]interface{}{
+		"message":   "a \"quoted\" <tag> & \\ \n\t\r\b\f \x01 \u2028 \u2029 é 日本 \xff",
+		"level":     LevelInfo,
+		"count":     42,
+		"neg":       int64(-7),
+		"u8":        uint8(255),
+		"big":       uint64(1 << 63),
+		"ratio":     0.25,
+		"tiny":      1e-7,
+		"huge":      1e21,
+		"f32":       float32(3.14),
+		"whole":     100.0,
+		"ok":        true,
+		"nothing":   nil,
+		"nested":    map[string]interface{}{"b": 1, "a": []int{1, 2}, "deeper": map[string]interface{}{"t": time.Unix(0, 5).UTC()}},
+		"labels":    map[string]string{"z": "1", "a": "<2>"},
+		"list":      []interface{}{"x", 1, 2.5, nil, map[string]interface{}{"k": false}},
+		"nil_list":  []interface{}(nil),
+		"nil_map":   map[string]interface{}(nil),
+		"":          "empty key",
+		"k\"ey<":    "escaped key",
+		"bytes":     []byte("hi"),
+		"stringers": []string{"x", "y"},
+		"at":        time.Date(2024, 3, 1, 12, 30, 0, 123456000, time.FixedZone("X", 3600)),
+		"took":      1500 * time.Millisecond,
+		"custom":    upperName("ada"),
+		"struct":    struct{ A int }{1},
+		"both":      marshalerError{},
+	}
+	want, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := getEntryBuffer()
+	defer putEntryBuffer(b)
+	if err := b.writeJSON(fields); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != string(want)+"\n" {
+		t.Errorf("writeJSON mismatch:\n got %s\nwant %s", got, want)
+	}
+
+	for _, bad := range []interface{}{math.NaN(), time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC), []interface{}{math.Inf(1)}} {
+		b.Reset()
+		if err := b.writeJSON(map[string]interface{}{"bad": bad}); err == nil {
+			t.Errorf("expected an error for %v, as json.Marshal reports", bad)
+		}
+	}
+}
+
+func TestWriteJSON_Errors(t *testing.T) {
+	b := getEntryBuffer()
+	defer putEntryBuffer(b)
+	err := b.writeJSON(map[string]interface{}{
+		"error":   errors.New(`disk "full"`),
+		"wrapped": &pathError{"/tmp/x"},
+		"typed":   (*pathError)(nil),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"error":"disk \"full\"","typed":null,"wrapped":"open /tmp/x"}` + "\n"
+	if got := b.String(); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func BenchmarkWriteJSON(b *testing.B) {
+	fields := map[string]interface{}{
+		"message":   "request served",
+		"level":     LevelInfo,
+		"timestamp": time.Now(),
+		"status":    200,
+		"took":      12 * time.Millisecond,
+		"ratio":     0.97,
+		"cached":    true,
+		"error":     errors.New("upstream slow"),
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf := getEntryBuffer()
+		_ = buf.writeJSON(fields)
+		putEntryBuffer(buf)
+	}
+}
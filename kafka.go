@@ -0,0 +1,381 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+type kafkaLogWriter struct {
+	writer      *kafka.Writer
+	topic       string
+	levelTopics map[logLevel]string
+
+	// service and environment fill the headers of entries that don't carry
+	// them, such as text lines.
+	service     string
+	environment string
+
+	partitionKey string
+	hostname     string
+
+	// produce sends messages; it is writer.WriteMessages outside tests.
+	produce func(ctx context.Context, msgs ...kafka.Message) error
+
+	// spool holds the messages the brokers didn't accept until a background
+	// replay delivers them; nil when SpoolDir is not set.
+	spool     *kafkaSpool
+	breaker   *circuitBreaker
+	stop      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// Write sends p as one message. With a spool, a message the brokers don't
+// accept is spooled instead of lost, and so are later messages until the
+// spool has been replayed, which keeps the order.
+func (k *kafkaLogWriter) Write(p []byte) (int, error) {
+	msg := k.message(p)
+	if k.spool == nil {
+		if err := k.send(msg); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if !k.spool.pending() && k.send(msg) == nil {
+		return len(p), nil
+	}
+	if err := k.spool.append(msg); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// send produces msgs, unless the circuit breaker is open.
+func (k *kafkaLogWriter) send(msgs ...kafka.Message) error {
+	if k.breaker == nil {
+		return k.produce(context.Background(), msgs...)
+	}
+	if !k.breaker.allow() {
+		return ErrSinkCircuitOpen
+	}
+	err := k.produce(context.Background(), msgs...)
+	if err != nil {
+		k.breaker.failure(err)
+	} else {
+		k.breaker.success()
+	}
+	return err
+}
+
+// replaySpool sends spooled messages to the brokers every
+// kafkaSpoolReplayInterval until Close.
+func (k *kafkaLogWriter) replaySpool() {
+	defer k.wg.Done()
+	ticker := time.NewTicker(kafkaSpoolReplayInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-k.stop:
+			return
+		case <-ticker.C:
+		}
+		for k.spool.pending() {
+			msgs, n, err := k.spool.next(100)
+			if err != nil {
+				break
+			}
+			if len(msgs) > 0 && k.send(msgs...) != nil {
+				break
+			}
+			if k.spool.commit(n, len(msgs)) != nil {
+				break
+			}
+		}
+	}
+}
+
+// message wraps one encoded entry, with headers that let consumers filter
+// and route it without parsing the value.
+func (k *kafkaLogWriter) message(p []byte) kafka.Message {
+	meta := kafkaEntryMeta{Service: k.service, Environment: k.environment}
+	contentType := "text/plain"
+	line := bytes.TrimSpace(p)
+	if len(line) > 1 && line[0] == '{' && json.Unmarshal(line, &meta) == nil {
+		contentType = "application/json"
+		if meta.Level == "" {
+			meta.Level = meta.Severity
+		}
+	} else {
+		level, _ := syslogEntryMeta(line)
+		meta.Level = string(level)
+		if bytes.HasPrefix(line, []byte("time=")) {
+			meta.fromLogfmt(string(line))
+		}
+	}
+
+	headers := make([]kafka.Header, 0, 5)
+	for _, h := range [][2]string{
+		{"service", meta.Service},
+		{"environment", meta.Environment},
+		{"level", meta.Level},
+		{"trace_id", meta.TraceID},
+		{"content-type", contentType},
+	} {
+		if h[1] != "" {
+			headers = append(headers, kafka.Header{Key: h[0], Value: []byte(h[1])})
+		}
+	}
+	msg := kafka.Message{Topic: k.topic, Value: p, Headers: headers}
+	if level, ok := parseLevelName(meta.Level); ok && k.levelTopics[level] != "" {
+		msg.Topic = k.levelTopics[level]
+	}
+	var key string
+	switch k.partitionKey {
+	case "service":
+		key = meta.Service
+	case "trace_id":
+		key = meta.TraceID
+	case "host":
+		key = k.hostname
+	}
+	if key != "" {
+		msg.Key = []byte(key)
+	}
+	return msg
+}
+
+// kafkaEntryMeta is the part of an entry copied into message headers.
+type kafkaEntryMeta struct {
+	Level       string `json:"level"`
+	Severity    string `json:"severity"`
+	Service     string `json:"service"`
+	Environment string `json:"environment"`
+	TraceID     string `json:"trace_id"`
+}
+
+// fromLogfmt reads the header fields of a logfmt line.
+func (m *kafkaEntryMeta) fromLogfmt(line string) {
+	for _, pair := range strings.Fields(line) {
+		k, v, _ := strings.Cut(pair, "=")
+		if u, err := strconv.Unquote(v); err == nil {
+			v = u
+		}
+		switch k {
+		case "service":
+			m.Service = v
+		case "environment":
+			m.Environment = v
+		case "trace_id":
+			m.TraceID = v
+		}
+	}
+}
+
+func (k *kafkaLogWriter) sinkHealth(h *SinkStatus) {
+	if k.spool != nil {
+		h.QueueDepth = k.spool.depth()
+	}
+	if k.breaker != nil && k.breaker.isOpen() {
+		h.CircuitOpen = true
+		h.Healthy = false
+	}
+}
+
+// Close flushes pending messages and closes the connection. Spooled
+// messages stay on disk and are replayed by the next writer using the same
+// SpoolDir.
+func (k *kafkaLogWriter) Close() error {
+	k.closeOnce.Do(func() { close(k.stop) })
+	k.wg.Wait()
+	err := k.writer.Close()
+	if k.spool != nil {
+		err = errors.Join(err, k.spool.close())
+	}
+	return err
+}
+
+func newKafkaWriter(kc KafkaConfig, service, environment string) (*kafkaLogWriter, error) {
+	// Validate rejects unknown values; an invalid setting passed straight to
+	// WithKafkaConfig falls back to the default.
+	acks, _ := kafkaRequiredAcks(kc.Acks)
+	compression, _ := kafkaCompression(kc.Compression)
+	var balancer kafka.Balancer = &kafka.LeastBytes{}
+	if kc.PartitionKey != "" {
+		balancer = &kafka.Hash{}
+	}
+	w := &kafka.Writer{
+		Addr:         kafka.TCP(kc.Brokers...),
+		Balancer:     balancer,
+		RequiredAcks: acks,
+		Compression:  compression,
+		BatchSize:    kc.BatchSize,
+		BatchBytes:   kc.BatchBytes,
+		BatchTimeout: time.Duration(kc.BatchTimeoutMS) * time.Millisecond,
+		WriteTimeout: time.Duration(kc.WriteTimeoutMS) * time.Millisecond,
+		ErrorLogger:  kafkaDiagnostics(),
+	}
+	if kc.MaxRetries > 0 {
+		w.MaxAttempts = kc.MaxRetries + 1
+	}
+	if kc.TLS != nil || kc.SASL != nil {
+		tr := &kafka.Transport{}
+		if kc.TLS != nil {
+			tc, err := kc.TLS.tlsConfig()
+			if err != nil {
+				return nil, err
+			}
+			tr.TLS = tc
+		}
+		if kc.SASL != nil {
+			m, err := kc.SASL.mechanism()
+			if err != nil {
+				return nil, err
+			}
+			tr.SASL = m
+		}
+		w.Transport = tr
+	}
+	kw := &kafkaLogWriter{
+		topic:        kc.Topic,
+		writer:       w,
+		produce:      w.WriteMessages,
+		service:      service,
+		environment:  environment,
+		partitionKey: kc.PartitionKey,
+		stop:         make(chan struct{}),
+	}
+	for name, topic := range kc.LevelTopics {
+		if level, ok := parseLevelName(name); ok {
+			if kw.levelTopics == nil {
+				kw.levelTopics = make(map[logLevel]string)
+			}
+			kw.levelTopics[level] = topic
+		}
+	}
+	if kc.PartitionKey == "host" {
+		kw.hostname, _ = os.Hostname()
+	}
+	if kc.BreakerThreshold > 0 {
+		cooldown := time.Duration(kc.BreakerCooldownMS) * time.Millisecond
+		if cooldown <= 0 {
+			cooldown = 30 * time.Second
+		}
+		kw.breaker = newCircuitBreaker("kafka", kc.BreakerThreshold, cooldown)
+	}
+	if kc.SpoolDir != "" {
+		maxSize := int64(kc.SpoolMaxSizeMB) << 20
+		if maxSize <= 0 {
+			maxSize = 100 << 20
+		}
+		spool, err := openKafkaSpool(kc.SpoolDir, kc.Topic, maxSize)
+		if err != nil {
+			return nil, err
+		}
+		kw.spool = spool
+		kw.wg.Add(1)
+		go kw.replaySpool()
+	}
+	return kw, nil
+}
+
+// kafkaCompression maps KafkaConfig.Compression to the producer codec.
+func kafkaCompression(codec string) (kafka.Compression, error) {
+	switch strings.ToLower(codec) {
+	case "", "none":
+		return 0, nil
+	case "gzip":
+		return kafka.Gzip, nil
+	case "snappy":
+		return kafka.Snappy, nil
+	case "lz4":
+		return kafka.Lz4, nil
+	case "zstd":
+		return kafka.Zstd, nil
+	}
+	return 0, fmt.Errorf("unknown kafka compression %q (want gzip, snappy, lz4 or zstd)", codec)
+}
+
+// tlsConfig loads the certificates referenced by c.
+func (c *KafkaTLSConfig) tlsConfig() (*tls.Config, error) {
+	tc := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: c.InsecureSkipVerify}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("kafka tls: %w", err)
+		}
+		tc.RootCAs = x509.NewCertPool()
+		if !tc.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("kafka tls: no certificates found in %s", c.CAFile)
+		}
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return nil, errors.New("kafka tls: cert_file and key_file must be set together")
+	}
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("kafka tls: %w", err)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	return tc, nil
+}
+
+// mechanism returns the SASL mechanism c selects.
+func (c *KafkaSASLConfig) mechanism() (sasl.Mechanism, error) {
+	if c.Username == "" {
+		return nil, errors.New("kafka sasl: username is required")
+	}
+	switch strings.ToUpper(c.Mechanism) {
+	case "PLAIN":
+		return plain.Mechanism{Username: c.Username, Password: c.Password}, nil
+	case "SCRAM-SHA-256":
+		return scram.Mechanism(scram.SHA256, c.Username, c.Password)
+	case "SCRAM-SHA-512":
+		return scram.Mechanism(scram.SHA512, c.Username, c.Password)
+	}
+	return nil, fmt.Errorf("kafka sasl: unknown mechanism %q (want PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512)", c.Mechanism)
+}
+
+// kafkaRequiredAcks maps KafkaConfig.Acks to the producer setting.
+func kafkaRequiredAcks(acks string) (kafka.RequiredAcks, error) {
+	switch strings.ToLower(acks) {
+	case "", "none":
+		return kafka.RequireNone, nil
+	case "one":
+		return kafka.RequireOne, nil
+	case "all":
+		return kafka.RequireAll, nil
+	}
+	return kafka.RequireNone, fmt.Errorf("unknown kafka acks %q (want none, one or all)", acks)
+}
+
+// kafkaDiagnostics reports the producer's own errors, such as failed
+// connections to a broker, as diagnostics, at most one per
+// diagnosticInterval.
+func kafkaDiagnostics() kafka.Logger {
+	var limit diagnosticLimiter
+	return kafka.LoggerFunc(func(msg string, args ...interface{}) {
+		if limit.allow() {
+			diagnose("kafka", nil, msg, args...)
+		}
+	})
+}
+
+var _ io.WriteCloser = (*kafkaLogWriter)(nil)
@@ -0,0 +1,235 @@
+package logger
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func testKafkaWriter(t *testing.T, kc KafkaConfig) *kafkaLogWriter {
+	t.Helper()
+	w, err := newKafkaWriter(kc, "billing", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestNewKafkaWriter_Config(t *testing.T) {
+	w := testKafkaWriter(t, KafkaConfig{
+		Brokers: []string{"k:9092"}, Topic: "logs", Acks: "all", MaxRetries: 4,
+		WriteTimeoutMS: 2500, BatchSize: 10, BatchBytes: 4096, BatchTimeoutMS: 50,
+	})
+	kw := w.writer
+	if w.topic != "logs" || kw.RequiredAcks != kafka.RequireAll || kw.MaxAttempts != 5 {
+		t.Errorf("unexpected producer settings: topic %q, acks %v, attempts %d", w.topic, kw.RequiredAcks, kw.MaxAttempts)
+	}
+	if kw.WriteTimeout != 2500*time.Millisecond || kw.BatchSize != 10 || kw.BatchBytes != 4096 || kw.BatchTimeout != 50*time.Millisecond {
+		t.Errorf("unexpected batch settings: %+v", kw)
+	}
+
+	kw = testKafkaWriter(t, KafkaConfig{Brokers: []string{"k:9092"}, Topic: "logs"}).writer
+	if kw.RequiredAcks != kafka.RequireNone || kw.MaxAttempts != 0 || kw.Transport != nil {
+		t.Errorf("defaults changed: acks %v, attempts %d, transport %v", kw.RequiredAcks, kw.MaxAttempts, kw.Transport)
+	}
+}
+
+func TestKafkaCompression(t *testing.T) {
+	for in, want := range map[string]kafka.Compression{
+		"": 0, "none": 0, "gzip": kafka.Gzip, "snappy": kafka.Snappy, "LZ4": kafka.Lz4, "zstd": kafka.Zstd,
+	} {
+		if got, err := kafkaCompression(in); err != nil || got != want {
+			t.Errorf("kafkaCompression(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := kafkaCompression("brotli"); err == nil {
+		t.Error("expected an error for an unknown codec")
+	}
+	kw := testKafkaWriter(t, KafkaConfig{Brokers: []string{"k:9092"}, Topic: "logs", Compression: "zstd"}).writer
+	if kw.Compression != kafka.Zstd {
+		t.Errorf("writer compression = %v, want zstd", kw.Compression)
+	}
+}
+
+func TestKafkaRequiredAcks(t *testing.T) {
+	for in, want := range map[string]kafka.RequiredAcks{
+		"": kafka.RequireNone, "none": kafka.RequireNone, "one": kafka.RequireOne, "ALL": kafka.RequireAll,
+	} {
+		if got, err := kafkaRequiredAcks(in); err != nil || got != want {
+			t.Errorf("kafkaRequiredAcks(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := kafkaRequiredAcks("some"); err == nil {
+		t.Error("expected an error for an unknown value")
+	}
+}
+
+// writeTestCert writes a self-signed certificate and its key as PEM files.
+func writeTestCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "kafka-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestNewKafkaWriter_TLS(t *testing.T) {
+	cert, key := writeTestCert(t)
+	kw := testKafkaWriter(t, KafkaConfig{
+		Brokers: []string{"k:9093"}, Topic: "logs",
+		TLS: &KafkaTLSConfig{CAFile: cert, CertFile: cert, KeyFile: key},
+	}).writer
+	tr, ok := kw.Transport.(*kafka.Transport)
+	if !ok || tr.TLS == nil {
+		t.Fatalf("expected a TLS transport, got %v", kw.Transport)
+	}
+	if tr.TLS.RootCAs == nil || len(tr.TLS.Certificates) != 1 || tr.TLS.InsecureSkipVerify {
+		t.Errorf("unexpected TLS config: %+v", tr.TLS)
+	}
+
+	for _, tc := range []struct {
+		cfg  KafkaTLSConfig
+		want string
+	}{
+		{KafkaTLSConfig{CAFile: key}, "no certificates"},
+		{KafkaTLSConfig{CertFile: cert}, "set together"},
+		{KafkaTLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}, "missing.pem"},
+	} {
+		cfg := tc.cfg
+		if _, err := newKafkaWriter(KafkaConfig{Brokers: []string{"k:9093"}, Topic: "logs", TLS: &cfg}, "", ""); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("newKafkaWriter(%+v) = %v, want an error containing %q", cfg, err, tc.want)
+		}
+	}
+}
+
+func TestNewKafkaWriter_SASL(t *testing.T) {
+	for mech, want := range map[string]string{"PLAIN": "PLAIN", "scram-sha-256": "SCRAM-SHA-256", "SCRAM-SHA-512": "SCRAM-SHA-512"} {
+		kw := testKafkaWriter(t, KafkaConfig{
+			Brokers: []string{"k:9092"}, Topic: "logs",
+			SASL: &KafkaSASLConfig{Mechanism: mech, Username: "svc", Password: "secret"},
+		}).writer
+		tr, ok := kw.Transport.(*kafka.Transport)
+		if !ok || tr.SASL == nil || tr.SASL.Name() != want {
+			t.Errorf("mechanism %q: got transport %+v, want SASL %s", mech, kw.Transport, want)
+		}
+	}
+
+	for _, cfg := range []KafkaSASLConfig{
+		{Mechanism: "GSSAPI", Username: "svc"},
+		{Mechanism: "PLAIN"},
+	} {
+		cfg := cfg
+		if _, err := newKafkaWriter(KafkaConfig{Brokers: []string{"k:9092"}, Topic: "logs", SASL: &cfg}, "", ""); err == nil {
+			t.Errorf("newKafkaWriter(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
+
+func TestKafkaLogWriter_Headers(t *testing.T) {
+	w := testKafkaWriter(t, KafkaConfig{Brokers: []string{"k:9092"}, Topic: "logs"})
+	headers := func(p string) map[string]string {
+		got := map[string]string{}
+		for _, h := range w.message([]byte(p)).Headers {
+			got[h.Key] = string(h.Value)
+		}
+		return got
+	}
+
+	got := headers(`{"level":"ERROR","service":"api","environment":"staging","trace_id":"abc","message":"boom"}` + "\n")
+	want := map[string]string{"service": "api", "environment": "staging", "level": "ERROR", "trace_id": "abc", "content-type": "application/json"}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("json header %s = %q, want %q", k, got[k], v)
+		}
+	}
+
+	got = headers("WARNING: 2025/05/11 main.go:1: slow\n")
+	if got["level"] != "WARNING" || got["service"] != "billing" || got["environment"] != "prod" || got["content-type"] != "text/plain" {
+		t.Errorf("unexpected text headers %v", got)
+	}
+	if _, ok := got["trace_id"]; ok {
+		t.Error("expected no trace_id header without a trace")
+	}
+
+	got = headers(`time=2025-05-11T10:00:00Z level=DEBUG trace_id="t-1" message="x"` + "\n")
+	if got["level"] != "DEBUG" || got["trace_id"] != "t-1" {
+		t.Errorf("unexpected logfmt headers %v", got)
+	}
+}
+
+func TestKafkaLogWriter_PartitionKey(t *testing.T) {
+	entry := []byte(`{"service":"api","trace_id":"abc","message":"x"}`)
+	for key, want := range map[string]string{"": "", "service": "api", "trace_id": "abc"} {
+		w := testKafkaWriter(t, KafkaConfig{Brokers: []string{"k:9092"}, Topic: "logs", PartitionKey: key})
+		if got := string(w.message(entry).Key); got != want {
+			t.Errorf("partition key %q: got %q, want %q", key, got, want)
+		}
+		_, hashed := w.writer.Balancer.(*kafka.Hash)
+		if hashed != (key != "") {
+			t.Errorf("partition key %q: balancer %T", key, w.writer.Balancer)
+		}
+	}
+
+	w := testKafkaWriter(t, KafkaConfig{Brokers: []string{"k:9092"}, Topic: "logs", PartitionKey: "trace_id"})
+	if msg := w.message([]byte(`{"message":"no trace"}`)); msg.Key != nil {
+		t.Errorf("expected no key without a trace_id, got %q", msg.Key)
+	}
+	hostname, _ := os.Hostname()
+	w = testKafkaWriter(t, KafkaConfig{Brokers: []string{"k:9092"}, Topic: "logs", PartitionKey: "host"})
+	if got := string(w.message(entry).Key); got != hostname {
+		t.Errorf("host key = %q, want %q", got, hostname)
+	}
+}
+
+func TestKafkaLogWriter_LevelTopics(t *testing.T) {
+	w := testKafkaWriter(t, KafkaConfig{
+		Brokers: []string{"k:9092"}, Topic: "app-logs",
+		LevelTopics: map[string]string{"error": "app-errors", "FATAL": "app-errors"},
+	})
+	for entry, want := range map[string]string{
+		`{"level":"ERROR","message":"boom"}`: "app-errors",
+		`{"level":"FATAL","message":"dead"}`: "app-errors",
+		`{"level":"INFO","message":"ok"}`:    "app-logs",
+		"ERROR: 2025/05/11 main.go:1: boom":  "app-errors",
+		"DEBUG: 2025/05/11 main.go:1: tick":  "app-logs",
+	} {
+		if got := w.message([]byte(entry)).Topic; got != want {
+			t.Errorf("%s: topic %q, want %q", entry, got, want)
+		}
+	}
+}
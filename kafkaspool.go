@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaSpoolReplayInterval is how often a non-empty spool is replayed to the
+// brokers.
+var kafkaSpoolReplayInterval = 5 * time.Second
+
+// errKafkaSpoolFull is returned when an entry doesn't fit in the spool.
+var errKafkaSpoolFull = errors.New("kafka spool: full, entry dropped")
+
+// kafkaSpool is an append-only file of messages the brokers didn't accept.
+// Replayed messages are skipped by advancing offset; the file is truncated
+// once it has been replayed completely and compacted on close, so a restart
+// resumes with the messages that were still pending.
+type kafkaSpool struct {
+	path    string
+	maxSize int64
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	offset int64
+	// records counts the pending messages, for SinkHealth.
+	records int
+}
+
+// kafkaSpoolRecord is one spooled message, one JSON document per line.
+type kafkaSpoolRecord struct {
+	Topic   string         `json:"topic"`
+	Key     []byte         `json:"key,omitempty"`
+	Headers []kafka.Header `json:"headers,omitempty"`
+	Value   []byte         `json:"value"`
+}
+
+func openKafkaSpool(dir, topic string, maxSize int64) (*kafkaSpool, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("kafka spool: %w", err)
+	}
+	path := filepath.Join(dir, "kafka-"+topic+".spool")
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("kafka spool: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("kafka spool: %w", err)
+	}
+	s := &kafkaSpool{path: path, maxSize: maxSize, f: f, size: info.Size()}
+	sc := bufio.NewScanner(io.NewSectionReader(f, 0, s.size))
+	sc.Buffer(nil, int(maxSize))
+	for sc.Scan() {
+		s.records++
+	}
+	return s, nil
+}
+
+// depth returns the number of pending messages.
+func (s *kafkaSpool) depth() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.records
+}
+
+// pending reports whether messages are waiting to be replayed.
+func (s *kafkaSpool) pending() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.offset < s.size
+}
+
+// append persists msg for a later replay.
+func (s *kafkaSpool) append(msg kafka.Message) error {
+	line, err := json.Marshal(kafkaSpoolRecord{Topic: msg.Topic, Key: msg.Key, Headers: msg.Headers, Value: msg.Value})
+	if err != nil {
+		return fmt.Errorf("kafka spool: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size+int64(len(line)) > s.maxSize {
+		return errKafkaSpoolFull
+	}
+	n, err := s.f.Write(line)
+	s.size += int64(n)
+	s.records++
+	if err != nil {
+		return fmt.Errorf("kafka spool: %w", err)
+	}
+	return nil
+}
+
+// next reads up to max pending messages and the number of bytes they take,
+// to be passed to commit once they are delivered. Unreadable records are
+// skipped.
+func (s *kafkaSpool) next(max int) ([]kafka.Message, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := bufio.NewReader(io.NewSectionReader(s.f, s.offset, s.size-s.offset))
+	var (
+		msgs []kafka.Message
+		read int64
+	)
+	for len(msgs) < max {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("kafka spool: %w", err)
+		}
+		read += int64(len(line))
+		var rec kafkaSpoolRecord
+		if json.Unmarshal(line, &rec) != nil {
+			continue
+		}
+		msgs = append(msgs, kafka.Message{Topic: rec.Topic, Key: rec.Key, Headers: rec.Headers, Value: rec.Value})
+	}
+	return msgs, read, nil
+}
+
+// commit marks n bytes returned by next, holding delivered messages, as
+// delivered.
+func (s *kafkaSpool) commit(n int64, delivered int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offset += n
+	if s.offset < s.size {
+		s.records -= delivered
+		return nil
+	}
+	s.offset, s.size, s.records = 0, 0, 0
+	if err := s.f.Truncate(0); err != nil {
+		return fmt.Errorf("kafka spool: %w", err)
+	}
+	return nil
+}
+
+// close drops the replayed part of the file and closes it.
+func (s *kafkaSpool) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.offset == 0 {
+		return s.f.Close()
+	}
+	rest, err := io.ReadAll(io.NewSectionReader(s.f, s.offset, s.size-s.offset))
+	s.f.Close()
+	if err != nil {
+		return fmt.Errorf("kafka spool: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, rest, 0o600); err != nil {
+		return fmt.Errorf("kafka spool: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("kafka spool: %w", err)
+	}
+	return nil
+}
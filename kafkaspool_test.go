@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeProducer records produced messages and fails while down is set.
+type fakeProducer struct {
+	mu   sync.Mutex
+	down bool
+	msgs []kafka.Message
+}
+
+func (f *fakeProducer) produce(_ context.Context, msgs ...kafka.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		return errors.New("broker unreachable")
+	}
+	f.msgs = append(f.msgs, msgs...)
+	return nil
+}
+
+func (f *fakeProducer) setDown(down bool) {
+	f.mu.Lock()
+	f.down = down
+	f.mu.Unlock()
+}
+
+func (f *fakeProducer) values() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []string
+	for _, m := range f.msgs {
+		out = append(out, string(m.Value))
+	}
+	return out
+}
+
+func TestKafkaLogWriter_Spool(t *testing.T) {
+	old := kafkaSpoolReplayInterval
+	kafkaSpoolReplayInterval = 10 * time.Millisecond
+	defer func() { kafkaSpoolReplayInterval = old }()
+
+	dir := t.TempDir()
+	fake := &fakeProducer{down: true}
+	w := testKafkaWriter(t, KafkaConfig{Brokers: []string{"k:9092"}, Topic: "logs", SpoolDir: dir})
+	w.produce = fake.produce
+
+	for _, entry := range []string{`{"n":1}`, `{"n":2}`} {
+		if _, err := w.Write([]byte(entry)); err != nil {
+			t.Fatalf("Write(%s) = %v, want the entry spooled", entry, err)
+		}
+	}
+	fake.setDown(false)
+	// A write while the spool is pending queues behind it to keep the order.
+	if _, err := w.Write([]byte(`{"n":3}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for w.spool.pending() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	got := fake.values()
+	if len(got) != 3 || got[0] != `{"n":1}` || got[1] != `{"n":2}` || got[2] != `{"n":3}` {
+		t.Fatalf("replayed %q, want entries 1, 2, 3 in order", got)
+	}
+	if msgs := fake.msgs; msgs[0].Topic != "logs" || len(msgs[0].Headers) == 0 {
+		t.Errorf("replayed message lost its topic or headers: %+v", msgs[0])
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKafkaSpool_SurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	s, err := openKafkaSpool(dir, "logs", 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"a", "b", "c"} {
+		if err := s.append(kafka.Message{Topic: "logs", Value: []byte(v)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	msgs, n, err := s.next(1)
+	if err != nil || len(msgs) != 1 || string(msgs[0].Value) != "a" {
+		t.Fatalf("next(1) = %v, %v", msgs, err)
+	}
+	if err := s.commit(n, len(msgs)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = openKafkaSpool(dir, "logs", 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	if s.depth() != 2 {
+		t.Errorf("depth() = %d after restart, want 2", s.depth())
+	}
+	msgs, _, err = s.next(10)
+	if err != nil || len(msgs) != 2 || string(msgs[0].Value) != "b" || string(msgs[1].Value) != "c" {
+		t.Fatalf("after restart next(10) = %v, %v; want b, c", msgs, err)
+	}
+}
+
+func TestKafkaSpool_Full(t *testing.T) {
+	s, err := openKafkaSpool(t.TempDir(), "logs", 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	if err := s.append(kafka.Message{Topic: "logs", Value: []byte("x")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.append(kafka.Message{Topic: "logs", Value: make([]byte, 64)}); !errors.Is(err, errKafkaSpoolFull) {
+		t.Errorf("append() = %v, want errKafkaSpoolFull", err)
+	}
+}
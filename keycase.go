@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"maps"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// KeyCase is a field key naming convention.
+type KeyCase int32
+
+const (
+	// KeyCaseAsIs leaves keys untouched.
+	KeyCaseAsIs KeyCase = iota
+	// KeyCaseSnake converts keys to snake_case ("userID" -> "user_id").
+	KeyCaseSnake
+	// KeyCaseCamel converts keys to camelCase ("user_id" -> "userId").
+	KeyCaseCamel
+)
+
+var keyCase atomic.Int32
+
+// SetKeyCase normalizes every structured field key, including the keys of
+// nested objects, to the given convention at encode time, so entries from
+// different teams and adapters share one naming scheme. Dot-separated
+// segments are converted individually.
+func SetKeyCase(c KeyCase) {
+	keyCase.Store(int32(c))
+}
+
+func normalizeKeyCase(fields map[string]interface{}) {
+	c := KeyCase(keyCase.Load())
+	if c == KeyCaseAsIs {
+		return
+	}
+	normalizeKeys(fields, c)
+}
+
+func normalizeKeys(m map[string]interface{}, c KeyCase) {
+	for key, value := range m {
+		if nested, ok := value.(map[string]interface{}); ok {
+			// Nested maps belong to the caller; convert a copy.
+			nested = maps.Clone(nested)
+			normalizeKeys(nested, c)
+			value = nested
+			m[key] = value
+		}
+		converted := convertKey(key, c)
+		if converted == key {
+			continue
+		}
+		delete(m, key)
+		if _, taken := m[converted]; taken {
+			// A key already in the target convention wins.
+			continue
+		}
+		m[converted] = value
+	}
+}
+
+func convertKey(key string, c KeyCase) string {
+	segments := strings.Split(key, ".")
+	for i, s := range segments {
+		words := splitWords(s)
+		if len(words) == 0 {
+			continue
+		}
+		switch c {
+		case KeyCaseSnake:
+			segments[i] = strings.Join(words, "_")
+		case KeyCaseCamel:
+			var b strings.Builder
+			b.WriteString(words[0])
+			for _, w := range words[1:] {
+				b.WriteString(strings.ToUpper(w[:1]))
+				b.WriteString(w[1:])
+			}
+			segments[i] = b.String()
+		}
+	}
+	return strings.Join(segments, ".")
+}
+
+// splitWords splits an identifier into lower-case words at underscores,
+// dashes, spaces and case changes, keeping acronyms together
+// ("HTTPStatusCode" -> http, status, code).
+func splitWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	start := -1
+
+	flush := func(end int) {
+		if start >= 0 && end > start {
+			words = append(words, strings.ToLower(string(runes[start:end])))
+		}
+		start = -1
+	}
+
+	for i, r := range runes {
+		if r == '_' || r == '-' || unicode.IsSpace(r) {
+			flush(i)
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := runes[i-1]
+		switch {
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			flush(i)
+			start = i
+		case unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			flush(i)
+			start = i
+		}
+	}
+	flush(len(runes))
+	return words
+}
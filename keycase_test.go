@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestConvertKey(t *testing.T) {
+	cases := []struct {
+		in    string
+		snake string
+		camel string
+	}{
+		{"userID", "user_id", "userId"},
+		{"HTTPStatusCode", "http_status_code", "httpStatusCode"},
+		{"request-id", "request_id", "requestId"},
+		{"trace_id", "trace_id", "traceId"},
+		{"http.RequestMethod", "http.request_method", "http.requestMethod"},
+		{"level", "level", "level"},
+	}
+	for _, c := range cases {
+		if got := convertKey(c.in, KeyCaseSnake); got != c.snake {
+			t.Errorf("snake(%q) = %q, want %q", c.in, got, c.snake)
+		}
+		if got := convertKey(c.in, KeyCaseCamel); got != c.camel {
+			t.Errorf("camel(%q) = %q, want %q", c.in, got, c.camel)
+		}
+	}
+}
+
+func TestSetKeyCase_NormalizesEntries(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+	SetKeyCase(KeyCaseSnake)
+	defer SetKeyCase(KeyCaseAsIs)
+
+	InfofMap(nil, map[string]interface{}{
+		"userID": 7,
+		"req":    map[string]interface{}{"bodyBytes": 12},
+	})
+
+	e := decodeLogLines(t, &buf)[0]
+	if e["user_id"] != float64(7) || e["userID"] != nil {
+		t.Errorf("expected userID to become user_id, got %v", e)
+	}
+	if req, _ := e["req"].(map[string]interface{}); req["body_bytes"] != float64(12) {
+		t.Errorf("expected nested keys to be normalized, got %v", e["req"])
+	}
+}
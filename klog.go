@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"bytes"
+
+	"k8s.io/klog/v2"
+)
+
+// RedirectKlog installs this logger as the klog backend, so output from
+// Kubernetes client-go and other klog users is leveled and structured like
+// every other entry. Structured klog calls (InfoS, ErrorS) keep their
+// key/value pairs; printf-style calls keep their severity.
+func RedirectKlog() {
+	klog.SetLoggerWithOptions(
+		std.Named("klog").Logr(),
+		klog.WriteKlogBuffer(writeKlogLine),
+	)
+}
+
+// writeKlogLine parses a klog text line such as
+// "W1014 12:00:00.000000   12345 file.go:12] message" into an entry.
+func writeKlogLine(line []byte) {
+	line = bytes.TrimRight(line, "\n")
+	if len(line) == 0 {
+		return
+	}
+
+	level := LevelInfo
+	switch line[0] {
+	case 'W':
+		level = LevelWarn
+	case 'E':
+		level = LevelError
+	case 'F':
+		// A fatal klog call exits on its own once this returns.
+		level = LevelError
+	}
+
+	fields := map[string]interface{}{"logger": "klog"}
+	msg := line
+	if i := bytes.Index(line, []byte("] ")); i > 0 && line[0] >= 'A' && line[0] <= 'Z' {
+		header := line[1:i]
+		msg = line[i+2:]
+		if parts := bytes.Fields(header); len(parts) > 0 {
+			fields["caller"] = string(parts[len(parts)-1])
+		}
+	}
+	fields["message"] = string(msg)
+
+	logWithMap(level, nil, fields)
+}
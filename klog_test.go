@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"k8s.io/klog/v2"
+)
+
+func TestRedirectKlog(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	RedirectKlog()
+	defer klog.ClearLogger()
+
+	klog.Warningf("watch %s closed", "pods")
+	klog.InfoS("synced", "resource", "pods", "count", 3)
+	klog.ErrorS(errors.New("forbidden"), "list failed", "resource", "secrets")
+	klog.Flush()
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d: %s", len(entries), buf.String())
+	}
+	if entries[0]["level"] != "WARNING" || entries[0]["message"] != "watch pods closed" {
+		t.Errorf("unexpected printf-style entry: %v", entries[0])
+	}
+	if entries[1]["level"] != "INFO" || entries[1]["resource"] != "pods" || entries[1]["count"] != float64(3) {
+		t.Errorf("unexpected structured entry: %v", entries[1])
+	}
+	if entries[2]["level"] != "ERROR" || entries[2]["error"] != "forbidden" {
+		t.Errorf("unexpected error entry: %v", entries[2])
+	}
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// LevelHandler returns an http.Handler that reports and changes the
+// package-level logger's level. See Logger.LevelHandler.
+func LevelHandler() http.Handler {
+	return std.LevelHandler()
+}
+
+type levelRequest struct {
+	Level  string `json:"level"`
+	Logger string `json:"logger,omitempty"`
+}
+
+// LevelHandler returns an http.Handler for an admin mux that exposes l's
+// level:
+//
+//	GET  /log/level               -> {"level":"info"}
+//	PUT  /log/level {"level":"debug"}
+//	PUT  /log/level {"level":"debug","logger":"db"}   (named override)
+//
+// GET also accepts ?logger=name. Invalid or missing levels and unknown keys
+// are rejected with 400 and the current level is kept. Every change is logged at INFO.
+func (l *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := l
+		switch r.Method {
+		case http.MethodGet:
+			if name := r.URL.Query().Get("logger"); name != "" {
+				target = l.root().Named(name)
+			}
+			writeLevelJSON(w, http.StatusOK, levelRequest{Level: target.GetLevel(), Logger: target.name})
+
+		case http.MethodPut:
+			var req levelRequest
+			dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4<<10))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&req); err != nil {
+				writeLevelJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body: " + err.Error()})
+				return
+			}
+			if req.Level == "" {
+				writeLevelJSON(w, http.StatusBadRequest, map[string]string{"error": "level is required"})
+				return
+			}
+			if req.Logger != "" {
+				target = l.root().Named(req.Logger)
+			}
+			previous := target.GetLevel()
+			if err := target.SetLevel(req.Level); err != nil {
+				writeLevelJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			// Logged through l so that raising a named logger's level
+			// doesn't hide its own change.
+			change := map[string]interface{}{
+				"message":        "log level changed",
+				"previous_level": previous,
+				"new_level":      target.GetLevel(),
+				"remote_addr":    r.RemoteAddr,
+			}
+			if target.name != "" {
+				change["target_logger"] = target.name
+			}
+			l.InfofMap(r.Context(), change)
+			writeLevelJSON(w, http.StatusOK, levelRequest{Level: target.GetLevel(), Logger: target.name})
+
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeLevelJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		}
+	})
+}
+
+func writeLevelJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
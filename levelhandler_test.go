@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLevelHandler(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "info")
+	h := LevelHandler()
+
+	do := func(method, target, body string) (int, map[string]string) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		var out map[string]string
+		_ = json.Unmarshal(rec.Body.Bytes(), &out)
+		return rec.Code, out
+	}
+
+	if code, out := do(http.MethodGet, "/", ""); code != 200 || out["level"] != "info" {
+		t.Errorf("unexpected GET response %d %v", code, out)
+	}
+	if code, out := do(http.MethodPut, "/", `{"level":"debug"}`); code != 200 || out["level"] != "debug" || GetLevel() != "debug" {
+		t.Errorf("unexpected PUT response %d %v", code, out)
+	}
+	if code, _ := do(http.MethodPut, "/", `{"level":"shouty"}`); code != http.StatusBadRequest || GetLevel() != "debug" {
+		t.Errorf("expected invalid level to be rejected, got %d", code)
+	}
+	for _, body := range []string{`{}`, `{"lvl":"trace"}`, `{"level":""}`} {
+		if code, _ := do(http.MethodPut, "/", body); code != http.StatusBadRequest || GetLevel() != "debug" {
+			t.Errorf("expected %s to be rejected and the level kept, got %d, level %s", body, code, GetLevel())
+		}
+	}
+	if code, out := do(http.MethodPut, "/", `{"level":"error","logger":"db"}`); code != 200 || out["logger"] != "db" {
+		t.Errorf("unexpected named PUT response %d %v", code, out)
+	}
+	if _, out := do(http.MethodGet, "/?logger=db", ""); out["level"] != "error" {
+		t.Errorf("expected named level, got %v", out)
+	}
+	if code, _ := do(http.MethodPost, "/", ""); code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", code)
+	}
+
+	entries := decodeLogLines(t, &buf)
+	if entries[0]["message"] != "log level changed" || entries[0]["previous_level"] != "info" || entries[0]["new_level"] != "debug" {
+		t.Errorf("expected the change to be logged, got %v", entries[0])
+	}
+	if len(entries) != 2 || entries[1]["target_logger"] != "db" {
+		t.Errorf("expected the named change to be logged, got %v", entries)
+	}
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// LevelRule rewrites the level of entries matching a filter expression (see
+// CompileFilter for the syntax), e.g. to treat a dependency's known-benign
+// "connection reset" errors as warnings:
+//
+//	logger.LevelRule{When: `level == "ERROR" && message =~ "connection reset"`, Level: logger.LevelWarn}
+type LevelRule struct {
+	When  string
+	Level logLevel
+}
+
+type compiledLevelRule struct {
+	rule  *FilterRule
+	level logLevel
+}
+
+var levelRules atomic.Pointer[[]compiledLevelRule]
+
+// SetLevelRules compiles and atomically installs level rewrite rules,
+// replacing any previous ones. The first matching rule wins. Rules apply to
+// entries that pass the level filter at their original level; the rewritten
+// level is then filtered again, so a downgraded entry may be dropped. FATAL
+// entries are never rewritten.
+func SetLevelRules(rules ...LevelRule) error {
+	compiled := make([]compiledLevelRule, 0, len(rules))
+	for _, r := range rules {
+		level, ok := parseLevelName(string(r.Level))
+		if !ok || level == LevelFatal {
+			return fmt.Errorf("level rule %q: invalid target level %q", r.When, r.Level)
+		}
+		rule, err := CompileFilter(r.When)
+		if err != nil {
+			return err
+		}
+		compiled = append(compiled, compiledLevelRule{rule: rule, level: level})
+	}
+	if len(compiled) == 0 {
+		levelRules.Store(nil)
+		return nil
+	}
+	levelRules.Store(&compiled)
+	return nil
+}
+
+func rewriteLevel(level logLevel, message string, fields map[string]interface{}) logLevel {
+	rules := levelRules.Load()
+	if rules == nil || level == LevelFatal {
+		return level
+	}
+	for _, r := range *rules {
+		if r.rule.Match(string(level), message, fields) {
+			return r.level
+		}
+	}
+	return level
+}
+
+func parseLevelName(name string) (logLevel, bool) {
+	switch strings.ToUpper(name) {
+	case "TRACE":
+		return LevelTrace, true
+	case "DEBUG":
+		return LevelDebug, true
+	case "INFO":
+		return LevelInfo, true
+	case "WARN", "WARNING":
+		return LevelWarn, true
+	case "ERROR":
+		return LevelError, true
+	case "FATAL":
+		return LevelFatal, true
+	}
+	if _, ok := customSeverity(logLevel(strings.ToUpper(name))); ok {
+		return logLevel(strings.ToUpper(name)), true
+	}
+	return "", false
+}
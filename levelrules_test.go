@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestLevelRules_DowngradeKnownNoise(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	err := SetLevelRules(LevelRule{When: `level == "ERROR" && message =~ "connection reset"`, Level: LevelWarn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer SetLevelRules()
+
+	Error("read tcp: connection reset by peer")
+	ErrorfMap(context.Background(), map[string]interface{}{"message": "upstream connection reset"})
+	Error("disk full")
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if entries[0]["level"] != "WARNING" {
+		t.Errorf("expected plain entry to be downgraded, got %v", entries[0]["level"])
+	}
+	if entries[1]["level"] != "WARNING" || entries[1]["original_level"] != "ERROR" {
+		t.Errorf("expected structured entry to be downgraded, got %v", entries[1])
+	}
+	if entries[2]["level"] != "ERROR" {
+		t.Errorf("non-matching entries must keep their level, got %v", entries[2]["level"])
+	}
+}
+
+func TestLevelRules_RewrittenLevelIsFiltered(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "error")
+
+	if err := SetLevelRules(LevelRule{When: `message == "benign"`, Level: LevelWarn}); err != nil {
+		t.Fatal(err)
+	}
+	defer SetLevelRules()
+
+	Error("benign")
+	if buf.Len() != 0 {
+		t.Errorf("downgraded entry below the global level must be dropped, got %s", buf.String())
+	}
+}
+
+func TestSetLevelRules_InvalidTarget(t *testing.T) {
+	if err := SetLevelRules(LevelRule{When: `level == "ERROR"`, Level: "LOUD"}); err == nil {
+		t.Errorf("expected error for unknown target level")
+	}
+	if err := SetLevelRules(LevelRule{When: `level == "ERROR"`, Level: LevelFatal}); err == nil {
+		t.Errorf("expected error for fatal target level")
+	}
+}
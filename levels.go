@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	customLevelsMu sync.Mutex
+	// customLevels maps registered level names to their severity.
+	customLevels atomic.Pointer[map[logLevel]int]
+)
+
+// RegisterLevel adds a custom level such as NOTICE, AUDIT or SECURITY and
+// returns it for use with Log, Logf and the Logger methods of the same name.
+// severity orders it against the built-in levels on the OpenTelemetry scale
+// (TRACE=1, DEBUG=5, INFO=9, WARNING=13, ERROR=17, FATAL=21): a NOTICE of 10
+// is written whenever INFO is, and is filtered out at "warn".
+//
+//	var LevelAudit = logger.MustRegisterLevel("AUDIT", 11)
+//	logger.Log(LevelAudit, ctx, map[string]interface{}{"message": "role granted", "user": id})
+//
+// Names are case-insensitive and stored in upper case. A custom level with a
+// fatal severity does not exit the process. The level name can also be used
+// as the minimum level passed to Init.
+func RegisterLevel(name string, severity int) (logLevel, error) {
+	level := logLevel(strings.ToUpper(strings.TrimSpace(name)))
+	if level == "" {
+		return "", fmt.Errorf("logger: level name is empty")
+	}
+	if severity < 1 || severity > 24 {
+		return "", fmt.Errorf("logger: level %s: severity %d is outside 1-24", level, severity)
+	}
+	if _, builtin := builtinSeverity(level); builtin || level == "WARN" {
+		return "", fmt.Errorf("logger: level %s is built in", level)
+	}
+
+	customLevelsMu.Lock()
+	defer customLevelsMu.Unlock()
+
+	next := map[logLevel]int{}
+	if cur := customLevels.Load(); cur != nil {
+		for k, v := range *cur {
+			next[k] = v
+		}
+	}
+	if existing, ok := next[level]; ok && existing != severity {
+		return "", fmt.Errorf("logger: level %s is already registered with severity %d", level, existing)
+	}
+	next[level] = severity
+	customLevels.Store(&next)
+	return level, nil
+}
+
+// MustRegisterLevel is like RegisterLevel but panics on error. It is meant
+// for package-level variables.
+func MustRegisterLevel(name string, severity int) logLevel {
+	level, err := RegisterLevel(name, severity)
+	if err != nil {
+		panic(err)
+	}
+	return level
+}
+
+func resetCustomLevels() {
+	customLevelsMu.Lock()
+	defer customLevelsMu.Unlock()
+	customLevels.Store(nil)
+}
+
+func builtinSeverity(level logLevel) (int, bool) {
+	switch level {
+	case LevelTrace:
+		return 1, true
+	case LevelDebug:
+		return 5, true
+	case LevelInfo:
+		return 9, true
+	case LevelWarn:
+		return 13, true
+	case LevelError:
+		return 17, true
+	case LevelFatal:
+		return 21, true
+	}
+	return 0, false
+}
+
+func customSeverity(level logLevel) (int, bool) {
+	if levels := customLevels.Load(); levels != nil {
+		sev, ok := (*levels)[level]
+		return sev, ok
+	}
+	return 0, false
+}
+
+// checkLevelName reports an error unless name is usable as a minimum level.
+func checkLevelName(name string) error {
+	switch strings.ToLower(name) {
+	case "", "trace", "debug", "info", "warn", "warning", "error":
+		return nil
+	}
+	if _, ok := customSeverity(logLevel(strings.ToUpper(name))); ok {
+		return nil
+	}
+	return fmt.Errorf("unknown level %q (want trace, debug, info, warn, error or a registered level)", name)
+}
+
+// minSeverity returns the lowest severity written at the configured level
+// name. Unknown and empty names write everything but trace.
+func minSeverity(name string) int {
+	switch strings.ToLower(name) {
+	case "trace":
+		return 1
+	case "debug":
+		return 5
+	case "info":
+		return 9
+	case "warn", "warning":
+		return 13
+	case "error":
+		return 17
+	}
+	if sev, ok := customSeverity(logLevel(strings.ToUpper(name))); ok {
+		return sev
+	}
+	return 2
+}
+
+// SetLevel changes the minimum level of the package-level logger at runtime.
+// It is safe to call while other goroutines are logging.
+func SetLevel(level string) error {
+	return std.SetLevel(level)
+}
+
+// GetLevel returns the minimum level of the package-level logger.
+func GetLevel() string {
+	return std.GetLevel()
+}
+
+// SetLevel changes l's minimum level at runtime. On a logger returned by
+// Named it sets that logger's override instead, like SetNamedLevel.
+func (l *Logger) SetLevel(level string) error {
+	if l.name != "" {
+		return l.SetNamedLevel(l.name, level)
+	}
+	if err := checkLevelName(level); err != nil {
+		return err
+	}
+	l.update(func(st *loggerState) { st.level = strings.ToLower(level) })
+	return nil
+}
+
+// GetLevel returns l's minimum level, taking named overrides into account.
+func (l *Logger) GetLevel() string {
+	st := l.load()
+	if l.name != "" {
+		if level, ok := st.namedLevel(l.name); ok {
+			return level
+		}
+	}
+	return st.level
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestRegisterLevel_OrderingAndOutput(t *testing.T) {
+	defer resetCustomLevels()
+	notice := MustRegisterLevel("notice", 10)
+	security := MustRegisterLevel("SECURITY", 18)
+
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "warn")
+	EnableSeverityNumber(true)
+	defer EnableSeverityNumber(false)
+
+	Log(notice, context.Background(), map[string]interface{}{"message": "filtered at warn"})
+	Logf(security, nil, nil, "password spraying from %s", "10.0.0.1")
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected only the security entry, got %v", entries)
+	}
+	if e := entries[0]; e["level"] != "SECURITY" || e["severity_number"] != float64(18) {
+		t.Errorf("unexpected custom level entry: %v", e)
+	}
+
+	buf.Reset()
+	initTestLogger(&buf, "json", "notice")
+	Info("below notice")
+	Log(notice, nil, map[string]interface{}{"message": "kept"})
+	Warning("above notice")
+	if n := len(decodeLogLines(t, &buf)); n != 2 {
+		t.Errorf("expected a custom level to work as the minimum level, got %d entries", n)
+	}
+}
+
+func TestRegisterLevel_Errors(t *testing.T) {
+	defer resetCustomLevels()
+	if _, err := RegisterLevel("info", 9); err == nil {
+		t.Errorf("expected built-in names to be rejected")
+	}
+	if _, err := RegisterLevel("AUDIT", 30); err == nil {
+		t.Errorf("expected out-of-range severity to be rejected")
+	}
+	if _, err := RegisterLevel("AUDIT", 11); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RegisterLevel("audit", 11); err != nil {
+		t.Errorf("re-registering with the same severity should be allowed, got %v", err)
+	}
+	if _, err := RegisterLevel("audit", 12); err == nil {
+		t.Errorf("expected conflicting severity to be rejected")
+	}
+	if err := (Config{Level: "audit"}).Validate(); err != nil {
+		t.Errorf("expected registered level to validate, got %v", err)
+	}
+}
+
+func TestSetLevel_Runtime(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "info")
+
+	Debug("filtered")
+	if err := SetLevel("DEBUG"); err != nil {
+		t.Fatal(err)
+	}
+	Debug("kept")
+	if GetLevel() != "debug" {
+		t.Errorf("expected debug, got %q", GetLevel())
+	}
+	if err := SetLevel("chatty"); err == nil || GetLevel() != "debug" {
+		t.Errorf("expected an invalid level to be rejected and ignored, got %v / %q", err, GetLevel())
+	}
+
+	db := Named("db")
+	if err := db.SetLevel("error"); err != nil {
+		t.Fatal(err)
+	}
+	db.Warning("filtered by the named override")
+	if db.GetLevel() != "error" || GetLevel() != "debug" {
+		t.Errorf("expected a named-only change, got %q / %q", db.GetLevel(), GetLevel())
+	}
+
+	if n := len(decodeLogLines(t, &buf)); n != 1 {
+		t.Errorf("expected 1 entry, got %d", n)
+	}
+}
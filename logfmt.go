@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// logfmtLogger is the logfmt counterpart of jsonLogger for plain entries.
+type logfmtLogger struct {
+	logType string
+	writer  io.Writer
+}
+
+func (j *logfmtLogger) Write(p []byte) (n int, err error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+
+	logEntry := map[string]interface{}{
+		"timestamp": time.Now().Format(time.RFC3339),
+		"level":     j.logType,
+		"message":   msg,
+	}
+	addConcurrencyFields(nil, logEntry)
+	addSeverityNumber(logLevel(j.logType), logEntry)
+	addFingerprint(logLevel(j.logType), logEntry)
+
+	return j.writer.Write(append(encodeLogfmt(logEntry), '\n'))
+}
+
+// logfmtLeadingKeys are written first, under their conventional logfmt
+// names, so that every line starts with time=... level=... msg=....
+var logfmtLeadingKeys = []struct{ field, key string }{
+	{"timestamp", "time"},
+	{"level", "level"},
+	{"message", "msg"},
+}
+
+// encodeLogfmt encodes fields as a logfmt line without the trailing newline.
+// The remaining keys follow the leading ones in sorted order; maps and slices
+// are written as quoted JSON.
+func encodeLogfmt(fields map[string]interface{}) []byte {
+	var b strings.Builder
+	seen := make(map[string]bool, len(logfmtLeadingKeys))
+	for _, k := range logfmtLeadingKeys {
+		seen[k.field] = true
+		if v, ok := fields[k.field]; ok {
+			writeLogfmtPair(&b, k.key, v)
+		}
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if !seen[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeLogfmtPair(&b, logfmtKey(k), fields[k])
+	}
+	return []byte(b.String())
+}
+
+func writeLogfmtPair(b *strings.Builder, key string, v interface{}) {
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	b.WriteString(key)
+	b.WriteByte('=')
+	b.WriteString(logfmtValue(v))
+}
+
+// logfmtKey replaces the characters a logfmt key can't contain.
+func logfmtKey(k string) string {
+	if k == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == unicode.ReplacementChar {
+			return '_'
+		}
+		return r
+	}, k)
+}
+
+func logfmtValue(v interface{}) string {
+	var s string
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		s = t
+	case logLevel:
+		s = string(t)
+	case error:
+		s = t.Error()
+	case fmt.Stringer:
+		s = t.String()
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return fmt.Sprint(t)
+	default:
+		data, err := json.Marshal(t)
+		if err != nil {
+			s = fmt.Sprint(t)
+		} else {
+			s = string(data)
+		}
+	}
+	if needsLogfmtQuote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func needsLogfmtQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == unicode.ReplacementChar || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
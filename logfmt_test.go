@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestLogfmt_PlainEntry(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "logfmt", "info")
+
+	Warning("disk almost full\nsecond line")
+
+	line := strings.TrimSuffix(buf.String(), "\n")
+	if !strings.HasPrefix(line, "time=") || !strings.Contains(line, ` level=WARNING msg="disk almost full\nsecond line"`) {
+		t.Errorf("unexpected logfmt line: %q", line)
+	}
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("expected a single line, got %q", buf.String())
+	}
+}
+
+func TestLogfmt_StructuredEntry(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "logfmt", "info")
+
+	ctx := context.WithValue(context.Background(), "trace_id", "abc-123")
+	InfofMap(ctx, map[string]interface{}{
+		"message": "user signed up",
+		"user":    "jane doe",
+		"attempt": 2,
+		"tags":    []string{"a", "b"},
+		"empty":   "",
+	})
+
+	line := buf.String()
+	for _, want := range []string{
+		`level=INFO msg="user signed up" `,
+		` attempt=2 `,
+		` empty="" `,
+		` tags="[\"a\",\"b\"]" `,
+		` trace_id=abc-123`,
+		` user="jane doe"`,
+	} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected %q in %q", want, line)
+		}
+	}
+	if !strings.HasPrefix(line, "time=") {
+		t.Errorf("expected the entry to start with time=, got %q", line)
+	}
+}
+
+func TestLogfmt_BoundFieldsOnPlainEntries(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "logfmt", "info")
+
+	With(map[string]interface{}{"request_id": "r-1"}).Info("fetching profile")
+
+	if line := buf.String(); !strings.Contains(line, `msg="fetching profile"`) || !strings.Contains(line, "request_id=r-1") {
+		t.Errorf("unexpected logfmt line: %q", line)
+	}
+}
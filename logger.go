@@ -1,40 +1,117 @@
 package logger
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/segmentio/kafka-go"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 type logLevel string
 
+// Level names the type of the level constants, for declaring level values
+// such as the levels passed to AddHook.
+type Level = logLevel
+
 const (
 	LevelInfo  logLevel = "INFO"
 	LevelWarn  logLevel = "WARNING"
 	LevelError logLevel = "ERROR"
 	LevelDebug logLevel = "DEBUG"
+	LevelTrace logLevel = "TRACE"
 	LevelFatal logLevel = "FATAL"
 )
 
-var (
+// Logger is an independent logger with its own level, outputs and metadata.
+// The package-level functions write through a default Logger configured by
+// Init and InitWithOptions.
+//
+// A Logger is safe for concurrent use, including logging while it is being
+// reconfigured: its configuration is an immutable snapshot that is swapped
+// atomically, so each entry sees either the old or the new configuration.
+type Logger struct {
+	state atomic.Pointer[loggerState]
+
+	// parent is the Logger whose configuration a child created by With or
+	// Named shares; fields are attached to every entry of the child and name
+	// selects its level override.
+	parent *Logger
+	fields map[string]interface{}
+	name   string
+
+	// keys records the InfoOnce and InfoEvery keys of a root Logger.
+	keys logKeys
+	// hooks are the entry hooks of a root Logger. They are kept across
+	// reconfiguration.
+	hooks atomic.Pointer[[]entryHook]
+	// written counts the entries written by a root Logger and its children.
+	written levelCounters
+}
+
+type loggerState struct {
+	level       string
+	namedLevels map[string]string
+	format      string
+	service     string
+	environment string
+	gcpProject  string
+
 	infoLogger       *log.Logger
 	warningLogger    *log.Logger
 	errorLogger      *log.Logger
 	debugLogger      *log.Logger
+	traceLogger      *log.Logger
 	structuredWriter io.Writer
+	fanout           *fanoutWriter
+	async            *asyncWriter
+	sampler          *sampler
+	throttle         *throttle
+	redactor         *redactor
+	scrubbers        []Scrubber
+	maxEntryBytes    int
+	caller           bool
+}
 
-	currentLevel string
-	serviceName  string
-	environment  string
-)
+// std is the default Logger used by the package-level functions.
+var std = &Logger{}
+
+// load returns the current configuration. A zero Logger has no outputs and
+// discards everything.
+func (l *Logger) load() *loggerState {
+	if l.parent != nil {
+		return l.parent.load()
+	}
+	if st := l.state.Load(); st != nil {
+		return st
+	}
+	return &loggerState{}
+}
+
+// update applies fn to a copy of the current configuration and installs it.
+func (l *Logger) update(fn func(*loggerState)) {
+	if l.parent != nil {
+		l.parent.update(fn)
+		return
+	}
+	for {
+		old := l.state.Load()
+		next := &loggerState{}
+		if old != nil {
+			*next = *old
+		}
+		fn(next)
+		if l.state.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
 
 type jsonLogger struct {
 	logType string
@@ -42,30 +119,43 @@ type jsonLogger struct {
 }
 
 func SetMetadata(service, env string) {
-	serviceName = service
-	environment = env
+	std.SetMetadata(service, env)
+}
+
+// SetMetadata sets the service and environment attached to l's structured
+// entries.
+func (l *Logger) SetMetadata(service, env string) {
+	l.update(func(st *loggerState) {
+		st.service = service
+		st.environment = env
+	})
 }
 
 func (j *jsonLogger) Write(p []byte) (n int, err error) {
 	msg := strings.TrimSuffix(string(p), "\n")
 	msg = strings.ReplaceAll(msg, "\n", " ")
 
-	logEntry := map[string]interface{}{
-		"timestamp": time.Now().Format(time.RFC3339),
-		"level":     j.logType,
-		"message":   msg,
-	}
-
-	jsonData, err := json.Marshal(logEntry)
-	if err != nil {
+	logEntry := getFieldMap()
+	defer putFieldMap(logEntry)
+	logEntry["timestamp"] = time.Now().Format(time.RFC3339)
+	logEntry["level"] = j.logType
+	logEntry["message"] = msg
+	addConcurrencyFields(nil, logEntry)
+	addSeverityNumber(logLevel(j.logType), logEntry)
+	addFingerprint(logLevel(j.logType), logEntry)
+
+	buf := getEntryBuffer()
+	defer putEntryBuffer(buf)
+	if err := buf.writeJSON(logEntry); err != nil {
 		return 0, err
 	}
-	jsonData = append(jsonData, '\n')
-	return j.writer.Write(jsonData)
+	return j.writer.Write(buf.Bytes())
 }
 
+// Init configures the package-level logger from positional parameters. New
+// code should prefer InitWithOptions.
 func Init(
-	logLevel string, // "debug", "info", "warn", "error"
+	logLevel string, // "trace", "debug", "info", "warn", "error"
 	logFormat string,
 	serviceName string,
 	environment string,
@@ -73,186 +163,623 @@ func Init(
 	writeToStdout bool,
 	sendToAKafkaQueue bool,
 	kafkaBrokers *[]string,
-	kafkaTopic *string) {
+	kafkaTopic *string,
+	extraOutputs ...io.Writer) {
+
+	opts := []Option{
+		WithLevel(logLevel),
+		WithFormat(logFormat),
+		WithService(serviceName, environment),
+		WithOutputs(extraOutputs...),
+	}
+	if writeToAFile {
+		opts = append(opts, WithFile(""))
+	}
+	if writeToStdout {
+		opts = append(opts, WithStdout())
+	}
+	if sendToAKafkaQueue {
+		if kafkaBrokers == nil || kafkaTopic == nil {
+			diagnose("kafka", nil, "output disabled: sendToAKafkaQueue requires kafkaBrokers and kafkaTopic")
+		} else {
+			opts = append(opts, WithKafka(*kafkaBrokers, *kafkaTopic))
+		}
+	}
+	InitWithOptions(opts...)
+}
 
-	currentLevel = logLevel
+// InitWithOptions configures the package-level logger. Without options it
+// logs every level except trace as text to no outputs.
+//
+//	logger.InitWithOptions(
+//		logger.WithLevel("info"),
+//		logger.WithFormat("json"),
+//		logger.WithService("file-service", "prod"),
+//		logger.WithStdout(),
+//		logger.WithKafka(brokers, "logs"),
+//	)
+func InitWithOptions(opts ...Option) {
+	st := New(opts...).load()
+	// Only the package-level logger feeds crash reports and shadow sinks.
+	// The state isn't shared yet, so its fanout can still be changed.
+	st.fanout.taps = []io.Writer{recentEntries, shadowSinks}
+	if st.throttle != nil {
+		st.throttle.summarize = std.writeThrottleSummary
+	}
+	if old := std.state.Swap(st); old != nil {
+		retire(old)
+	}
+}
 
-	var writers []io.Writer
+// retireGrace is how long outputs replaced by Init or a configuration reload
+// stay open, so entries that were already being written to them are not lost.
+var retireGrace = 2 * time.Second
 
-	if writeToAFile {
+// retire closes a replaced configuration once retireGrace has passed,
+// stopping its async workers and closing the outputs it owns.
+func retire(st *loggerState) {
+	retired := &Logger{}
+	retired.state.Store(st)
+	time.AfterFunc(retireGrace, func() { _ = retired.Close() })
+}
+
+// New returns a Logger configured by opts, independent of the package-level
+// logger and of other instances. Without options it logs every level except
+// trace as text to no outputs.
+func New(opts ...Option) *Logger {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	st := &loggerState{
+		level:         o.level,
+		namedLevels:   o.namedLevels,
+		format:        o.format,
+		service:       o.service,
+		environment:   o.environment,
+		sampler:       newSampler(o.sampling),
+		redactor:      mustRedactor(o.redact),
+		scrubbers:     o.scrubbers,
+		maxEntryBytes: o.maxEntryBytes,
+		caller:        o.caller,
+	}
+
+	var sinks []*sinkWriter
+
+	if o.file != nil {
 		// ✅ Log rotation with lumberjack
 		rotatingFile := &lumberjack.Logger{
-			Filename:   "app.log",
-			MaxSize:    10, // MB
-			MaxBackups: 5,
-			MaxAge:     28,   // days
-			Compress:   true, // gzip
+			Filename:   o.file.Path,
+			MaxSize:    o.file.MaxSizeMB,
+			MaxBackups: o.file.MaxBackups,
+			MaxAge:     o.file.MaxAgeDays,
+			Compress:   !o.file.DisableCompression, // gzip
 		}
-		writers = append(writers, rotatingFile)
+		sink := newSinkWriter("file", rotatingFile)
+		sink.owned = true
+		sinks = append(sinks, sink)
 	}
 
-	if writeToStdout {
-		writers = append(writers, os.Stdout)
+	if o.stdout {
+		sinks = append(sinks, newSinkWriter("stdout", os.Stdout))
 	}
 
-	if sendToAKafkaQueue {
-		writers = append(writers, newKafkaWriter(*kafkaBrokers, *kafkaTopic))
+	if o.kafka != nil {
+		if kw, err := newKafkaWriter(*o.kafka, o.service, o.environment); err != nil {
+			diagnose("kafka", err, "output disabled")
+		} else {
+			sink := newSinkWriter("kafka", kw)
+			sink.owned = true
+			sinks = append(sinks, sink)
+		}
 	}
 
-	multiWriter := io.MultiWriter(writers...)
-	structuredWriter = multiWriter
+	for i, out := range o.outputs {
+		if out.w != nil {
+			sink := newSinkWriter(fmt.Sprintf("custom-%d", i), out.w)
+			sink.owned = out.owned
+			sinks = append(sinks, sink)
+		}
+	}
 
-	if logFormat == "json" {
-		infoLogger = log.New(&jsonLogger{"INFO", multiWriter}, "", 0)
-		warningLogger = log.New(&jsonLogger{"WARNING", multiWriter}, "", 0)
-		errorLogger = log.New(&jsonLogger{"ERROR", multiWriter}, "", 0)
-		debugLogger = log.New(&jsonLogger{"DEBUG", multiWriter}, "", 0)
-	} else {
+	st.fanout = newFanout(sinks)
+	multiWriter := io.Writer(st.fanout)
+	if o.async != nil {
+		st.async = newAsyncWriter(st.fanout, *o.async)
+		multiWriter = st.async
+	}
+	st.structuredWriter = multiWriter
+
+	switch o.format {
+	case "json":
+		st.infoLogger = log.New(&jsonLogger{"INFO", multiWriter}, "", 0)
+		st.warningLogger = log.New(&jsonLogger{"WARNING", multiWriter}, "", 0)
+		st.errorLogger = log.New(&jsonLogger{"ERROR", multiWriter}, "", 0)
+		st.debugLogger = log.New(&jsonLogger{"DEBUG", multiWriter}, "", 0)
+		st.traceLogger = log.New(&jsonLogger{"TRACE", multiWriter}, "", 0)
+	case "logfmt":
+		st.infoLogger = log.New(&logfmtLogger{"INFO", multiWriter}, "", 0)
+		st.warningLogger = log.New(&logfmtLogger{"WARNING", multiWriter}, "", 0)
+		st.errorLogger = log.New(&logfmtLogger{"ERROR", multiWriter}, "", 0)
+		st.debugLogger = log.New(&logfmtLogger{"DEBUG", multiWriter}, "", 0)
+		st.traceLogger = log.New(&logfmtLogger{"TRACE", multiWriter}, "", 0)
+	case "gcp":
+		project := o.gcpProject
+		if project == "" {
+			project = os.Getenv("GOOGLE_CLOUD_PROJECT")
+		}
+		st.gcpProject = project
+		st.infoLogger = log.New(&gcpLogger{"INFO", multiWriter, project}, "", log.Llongfile)
+		st.warningLogger = log.New(&gcpLogger{"WARNING", multiWriter, project}, "", log.Llongfile)
+		st.errorLogger = log.New(&gcpLogger{"ERROR", multiWriter, project}, "", log.Llongfile)
+		st.debugLogger = log.New(&gcpLogger{"DEBUG", multiWriter, project}, "", log.Llongfile)
+		st.traceLogger = log.New(&gcpLogger{"TRACE", multiWriter, project}, "", log.Llongfile)
+	default:
 		flags := log.Ldate | log.Ltime | log.Lmicroseconds | log.Lshortfile
-		infoLogger = log.New(multiWriter, "INFO: ", flags)
-		warningLogger = log.New(multiWriter, "WARNING: ", flags)
-		errorLogger = log.New(multiWriter, "ERROR: ", flags)
-		debugLogger = log.New(multiWriter, "DEBUG: ", flags)
+		st.infoLogger = log.New(multiWriter, "INFO: ", flags)
+		st.warningLogger = log.New(multiWriter, "WARNING: ", flags)
+		st.errorLogger = log.New(multiWriter, "ERROR: ", flags)
+		st.debugLogger = log.New(multiWriter, "DEBUG: ", flags)
+		st.traceLogger = log.New(multiWriter, "TRACE: ", flags)
 	}
+	l := &Logger{}
+	st.throttle = newThrottle(o.throttle, l.writeThrottleSummary)
+	l.state.Store(st)
+	return l
 }
 
 func shouldLog(level logLevel) bool {
-	switch strings.ToLower(currentLevel) {
-	case "debug":
-		return true
-	case "info":
-		return level != LevelDebug
-	case "warn":
-		return level == LevelWarn || level == LevelError || level == LevelFatal
-	case "error":
-		return level == LevelError || level == LevelFatal
-	default:
-		return true
+	return std.shouldLog(level)
+}
+
+func (l *Logger) shouldLog(level logLevel) bool {
+	st := l.load()
+	min := st.level
+	if l.name != "" {
+		if named, ok := st.namedLevel(l.name); ok {
+			min = named
+		}
 	}
+	return SeverityNumber(level) >= minSeverity(min)
 }
 
 func Info(msg string) {
-	if shouldLog(LevelInfo) {
-		infoLogger.Output(2, msg)
+	if std.shouldLog(LevelInfo) {
+		std.logPlain(nil, LevelInfo, msg)
 	}
 }
 func Warning(msg string) {
-	if shouldLog(LevelWarn) {
-		warningLogger.Output(2, msg)
+	if std.shouldLog(LevelWarn) {
+		std.logPlain(nil, LevelWarn, msg)
 	}
 }
 func Error(msg string) {
-	if shouldLog(LevelError) {
-		errorLogger.Output(2, msg)
+	if std.shouldLog(LevelError) {
+		std.logPlain(nil, LevelError, msg)
 	}
 }
 func Debug(msg string) {
-	if shouldLog(LevelDebug) {
-		debugLogger.Output(2, msg)
+	if std.shouldLog(LevelDebug) {
+		std.logPlain(nil, LevelDebug, msg)
+	}
+}
+func Trace(msg string) {
+	if std.shouldLog(LevelTrace) {
+		std.logPlain(nil, LevelTrace, msg)
 	}
 }
 
 func Fatal(msg string) {
-	if shouldLog(LevelFatal) {
-		errorLogger.Output(2, msg)
-		os.Exit(1)
+	if std.shouldLog(LevelFatal) {
+		std.logPlain(nil, LevelFatal, msg)
+		std.exitFatal()
 	}
 }
 
 func Infof(msg string, args ...interface{}) {
-	if shouldLog(LevelInfo) {
-		infoLogger.Output(2, fmt.Sprintf(msg, args...))
+	if std.shouldLog(LevelInfo) {
+		std.logPlainf(nil, LevelInfo, msg, args)
 	}
 }
 func Warningf(msg string, args ...interface{}) {
-	if shouldLog(LevelWarn) {
-		warningLogger.Output(2, fmt.Sprintf(msg, args...))
+	if std.shouldLog(LevelWarn) {
+		std.logPlainf(nil, LevelWarn, msg, args)
 	}
 }
 func Errorf(msg string, args ...interface{}) {
-	if shouldLog(LevelError) {
-		errorLogger.Output(2, fmt.Sprintf(msg, args...))
+	if std.shouldLog(LevelError) {
+		std.logPlainf(nil, LevelError, msg, args)
 	}
 }
 func Debugf(msg string, args ...interface{}) {
-	if shouldLog(LevelDebug) {
-		debugLogger.Output(2, fmt.Sprintf(msg, args...))
+	if std.shouldLog(LevelDebug) {
+		std.logPlainf(nil, LevelDebug, msg, args)
+	}
+}
+func Tracef(msg string, args ...interface{}) {
+	if std.shouldLog(LevelTrace) {
+		std.logPlainf(nil, LevelTrace, msg, args)
 	}
 }
 func Fatalf(msg string, args ...interface{}) {
-	if shouldLog(LevelFatal) {
-		errorLogger.Output(2, fmt.Sprintf("FATAL: "+msg, args...))
-		os.Exit(1)
+	if std.shouldLog(LevelFatal) {
+		std.logPlain(nil, LevelFatal, fmt.Sprintf("FATAL: "+msg, args...))
+		std.exitFatal()
 	}
 }
 
-func logWithMap(level logLevel, ctx context.Context, fields map[string]interface{}) {
-	if !shouldLog(level) {
+func (l *Logger) Info(msg string) {
+	if l.shouldLog(LevelInfo) {
+		l.logPlain(nil, LevelInfo, msg)
+	}
+}
+func (l *Logger) Warning(msg string) {
+	if l.shouldLog(LevelWarn) {
+		l.logPlain(nil, LevelWarn, msg)
+	}
+}
+func (l *Logger) Error(msg string) {
+	if l.shouldLog(LevelError) {
+		l.logPlain(nil, LevelError, msg)
+	}
+}
+func (l *Logger) Debug(msg string) {
+	if l.shouldLog(LevelDebug) {
+		l.logPlain(nil, LevelDebug, msg)
+	}
+}
+func (l *Logger) Trace(msg string) {
+	if l.shouldLog(LevelTrace) {
+		l.logPlain(nil, LevelTrace, msg)
+	}
+}
+
+func (l *Logger) Fatal(msg string) {
+	if l.shouldLog(LevelFatal) {
+		l.logPlain(nil, LevelFatal, msg)
+		l.exitFatal()
+	}
+}
+
+func (l *Logger) Infof(msg string, args ...interface{}) {
+	if l.shouldLog(LevelInfo) {
+		l.logPlainf(nil, LevelInfo, msg, args)
+	}
+}
+func (l *Logger) Warningf(msg string, args ...interface{}) {
+	if l.shouldLog(LevelWarn) {
+		l.logPlainf(nil, LevelWarn, msg, args)
+	}
+}
+func (l *Logger) Errorf(msg string, args ...interface{}) {
+	if l.shouldLog(LevelError) {
+		l.logPlainf(nil, LevelError, msg, args)
+	}
+}
+func (l *Logger) Debugf(msg string, args ...interface{}) {
+	if l.shouldLog(LevelDebug) {
+		l.logPlainf(nil, LevelDebug, msg, args)
+	}
+}
+func (l *Logger) Tracef(msg string, args ...interface{}) {
+	if l.shouldLog(LevelTrace) {
+		l.logPlainf(nil, LevelTrace, msg, args)
+	}
+}
+func (l *Logger) Fatalf(msg string, args ...interface{}) {
+	if l.shouldLog(LevelFatal) {
+		l.logPlain(nil, LevelFatal, fmt.Sprintf("FATAL: "+msg, args...))
+		l.exitFatal()
+	}
+}
+
+// logPlain writes a plain-message entry through the level's logger. It and
+// logPlainf must be called directly from the exported level functions so that
+// Lshortfile reports their caller.
+func (l *Logger) logPlain(ctx context.Context, level logLevel, msg string) {
+	l.emitPlain(3, ctx, level, msg, msg)
+}
+
+// logPlainf formats and writes a plain-message entry, keeping format as the
+// entry's template for throttling.
+func (l *Logger) logPlainf(ctx context.Context, level logLevel, format string, args []interface{}) {
+	l.emitPlain(3, ctx, level, format, fmt.Sprintf(format, args...))
+}
+
+// emitPlain writes a plain-message entry. calldepth is the number of frames
+// between emitPlain and the logging call, as in log.Logger.Output, with 1
+// identifying the caller of emitPlain.
+func (l *Logger) emitPlain(calldepth int, ctx context.Context, level logLevel, template, msg string) {
+	st := l.load()
+	limited := st.maxEntryBytes > 0
+	hooked := l.root().hooks.Load() != nil
+	if (ctx != nil || len(l.fields) > 0 || limited || hooked || st.caller) && st.structuredFormat() {
+		// The JSON and logfmt plain loggers can't carry fields, which hooks
+		// may add, or callers, or measure and truncate the encoded entry;
+		// write a structured entry.
+		fields := getFieldMap()
+		fields["message"] = msg
+		l.writeStructured(level, ctx, fields, time.Now(), template)
+		putFieldMap(fields)
 		return
 	}
+	if rewritten := rewriteLevel(level, msg, nil); rewritten != level {
+		if !l.shouldLog(rewritten) {
+			return
+		}
+		level = rewritten
+	}
+	if dropEntry(level, msg, nil) || !st.sampler.allow(level, msg) || !st.throttle.allow(level, template, msg) {
+		return
+	}
+	msg, extra, ok := l.hooksPlain(ctx, level, transformMessage(level, msg), l.plainFields(ctx))
+	if !ok {
+		return
+	}
+	msg, extra = scrubPlain(st.scrubbers, level, msg, extra)
+	msg = st.redactor.message(msg)
+	if extra = st.redactor.redactedFields(extra); len(extra) > 0 {
+		msg += formatFieldSuffix(extra)
+	}
+	pl := l.loggerFor(level)
+	if pl == nil {
+		// A zero Logger.
+		return
+	}
+	if limited {
+		if room := st.maxEntryBytes - textHeaderLen(pl, calldepth+1); len(msg) > room {
+			msg = truncateString(msg, room-len(truncationSuffix))
+		}
+	}
+	l.root().written.inc(level)
+	_ = pl.Output(calldepth+1, msg)
+}
+
+func logWithMap(level logLevel, ctx context.Context, fields map[string]interface{}) {
+	std.logWithMapAt(level, ctx, fields, time.Now())
+}
+
+func logWithMapAt(level logLevel, ctx context.Context, fields map[string]interface{}, at time.Time) {
+	std.logWithMapAt(level, ctx, fields, at)
+}
+
+func (l *Logger) logWithMapAt(level logLevel, ctx context.Context, fields map[string]interface{}, at time.Time) {
+	if level, ok := l.writeStructured(level, ctx, fields, at, ""); ok && level == LevelFatal {
+		l.exitFatal()
+	}
+}
 
-	fields["service"] = serviceName
-	fields["environment"] = environment
-	fields["timestamp"] = time.Now().Format(time.RFC3339)
+// writeStructured runs fields through the entry pipeline and writes them. It
+// returns the final level of the entry, and false when the level is disabled.
+// template is the format the message was built from, for throttling; empty
+// means the message itself. fields isn't modified: the entry is built in a
+// copy.
+func (l *Logger) writeStructured(level logLevel, ctx context.Context, fields map[string]interface{}, at time.Time, template string) (logLevel, bool) {
+	if !l.shouldLogCtx(ctx, level) {
+		return level, false
+	}
+	entry := getFieldMap()
+	defer putFieldMap(entry)
+	for k, v := range fields {
+		entry[k] = v
+	}
+	for k, v := range l.fields {
+		if _, ok := entry[k]; !ok {
+			entry[k] = v
+		}
+	}
+	fields = entry
+	st := l.load()
+
+	fields["service"] = st.service
+	fields["environment"] = st.environment
+	fields["timestamp"] = at.Format(time.RFC3339)
 	fields["level"] = level
 
-	if ctx != nil {
-		if traceID := ctx.Value("trace_id"); traceID != nil {
-			fields["trace_id"] = traceID
+	addContextFields(ctx, fields)
+
+	if rewritten := rewriteLevel(level, "", fields); rewritten != level {
+		if !l.shouldLogCtx(ctx, rewritten) {
+			return rewritten, false
 		}
+		fields["original_level"] = level
+		fields["level"] = rewritten
+		level = rewritten
 	}
 
-	jsonData, err := json.Marshal(fields)
+	if dropEntry(level, "", fields) {
+		return level, true
+	}
+	msg, _ := fields["message"].(string)
+	if template == "" {
+		template = msg
+	}
+	if _, summary := fields["suppressed_count"].(suppressedCount); !summary && !st.throttle.allow(level, template, msg) {
+		return level, true
+	}
+	if !st.sampler.allow(level, msg) {
+		return level, true
+	}
+	if st.caller {
+		addCaller(fields)
+	}
+	transformFields(level, fields)
+	if !l.hooksStructured(ctx, level, at, fields) {
+		return level, true
+	}
+	scrubFields(st.scrubbers, level, fields)
+	st.redactor.fields(fields)
+	expandDottedKeys(fields)
+	normalizeKeyCase(fields)
+	flattenFields(fields)
+	annotateEventCode(fields)
+	addSeverityNumber(level, fields)
+	addFingerprint(level, fields)
+
+	buf := getEntryBuffer()
+	defer putEntryBuffer(buf)
+	err := st.encodeTo(buf, fields)
+	if err == nil && st.maxEntryBytes > 0 && buf.Len() > st.maxEntryBytes {
+		err = st.fitEntry(buf, fields)
+	}
 	if err != nil {
-		errorLogger.Output(2, fmt.Sprintf("Failed to marshal structured log: %v", err))
-		return
+		if st.errorLogger != nil {
+			st.errorLogger.Output(2, fmt.Sprintf("Failed to marshal structured log: %v", err))
+		}
+		return level, true
 	}
-	jsonData = append(jsonData, '\n')
 
-	if structuredWriter != nil {
-		_, _ = structuredWriter.Write(jsonData)
+	l.root().written.inc(level)
+	if st.structuredWriter != nil {
+		_, _ = st.structuredWriter.Write(buf.Bytes())
 	}
+	return level, true
+}
 
-	if level == LevelFatal {
-		os.Exit(1)
+// structuredFormat reports whether plain entries are written as structured
+// records rather than prefixed text lines.
+func (st *loggerState) structuredFormat() bool {
+	switch st.format {
+	case "json", "logfmt", "gcp":
+		return true
 	}
+	return false
 }
 
-func InfofMap(ctx context.Context, fields map[string]interface{}) { logWithMap(LevelInfo, ctx, fields) }
+// encodeTo writes a structured entry in the configured format to b,
+// followed by a newline.
+func (st *loggerState) encodeTo(b *entryBuffer, fields map[string]interface{}) error {
+	switch st.format {
+	case "logfmt":
+		b.Write(encodeLogfmt(fields))
+		b.WriteByte('\n')
+		return nil
+	case "gcp":
+		return b.writeJSON(gcpEntry(fields, st.gcpProject))
+	default:
+		return b.writeJSON(fields)
+	}
+}
+
+// encode serializes a structured entry in the configured format, without the
+// trailing newline. Text-format loggers write structured entries as JSON.
+func (st *loggerState) encode(fields map[string]interface{}) ([]byte, error) {
+	buf := getEntryBuffer()
+	defer putEntryBuffer(buf)
+	if err := st.encodeTo(buf, fields); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil
+}
+
+func InfofMap(ctx context.Context, fields map[string]interface{}) {
+	std.logWithMapAt(LevelInfo, ctx, fields, time.Now())
+}
 func WarningfMap(ctx context.Context, fields map[string]interface{}) {
-	logWithMap(LevelWarn, ctx, fields)
+	std.logWithMapAt(LevelWarn, ctx, fields, time.Now())
 }
 func ErrorfMap(ctx context.Context, fields map[string]interface{}) {
-	logWithMap(LevelError, ctx, fields)
+	std.logWithMapAt(LevelError, ctx, fields, time.Now())
 }
 func DebugfMap(ctx context.Context, fields map[string]interface{}) {
-	logWithMap(LevelDebug, ctx, fields)
+	std.logWithMapAt(LevelDebug, ctx, fields, time.Now())
+}
+func TracefMap(ctx context.Context, fields map[string]interface{}) {
+	std.logWithMapAt(LevelTrace, ctx, fields, time.Now())
+}
+func FatalfMap(ctx context.Context, fields map[string]interface{}) {
+	std.logWithMapAt(LevelFatal, ctx, fields, time.Now())
+}
+
+func (l *Logger) InfofMap(ctx context.Context, fields map[string]interface{}) {
+	l.logWithMapAt(LevelInfo, ctx, fields, time.Now())
+}
+func (l *Logger) WarningfMap(ctx context.Context, fields map[string]interface{}) {
+	l.logWithMapAt(LevelWarn, ctx, fields, time.Now())
+}
+func (l *Logger) ErrorfMap(ctx context.Context, fields map[string]interface{}) {
+	l.logWithMapAt(LevelError, ctx, fields, time.Now())
+}
+func (l *Logger) DebugfMap(ctx context.Context, fields map[string]interface{}) {
+	l.logWithMapAt(LevelDebug, ctx, fields, time.Now())
+}
+func (l *Logger) TracefMap(ctx context.Context, fields map[string]interface{}) {
+	l.logWithMapAt(LevelTrace, ctx, fields, time.Now())
+}
+func (l *Logger) FatalfMap(ctx context.Context, fields map[string]interface{}) {
+	l.logWithMapAt(LevelFatal, ctx, fields, time.Now())
 }
 
-func (k *kafkaLogWriter) Write(p []byte) (int, error) {
-	msg := kafka.Message{
-		Topic: k.topic,
-		Value: p,
+// Log writes a structured entry at the given level.
+func Log(level logLevel, ctx context.Context, fields map[string]interface{}) {
+	std.Log(level, ctx, fields)
+}
+
+// Log writes a structured entry at the given level.
+func (l *Logger) Log(level logLevel, ctx context.Context, fields map[string]interface{}) {
+	l.logWithMapAt(level, ctx, fields, time.Now())
+}
+
+// Logf writes a structured entry at the given level whose message field is
+// formatted from format and args. Formatting is skipped when the level is
+// disabled.
+func Logf(level logLevel, ctx context.Context, fields map[string]interface{}, format string, args ...interface{}) {
+	std.Logf(level, ctx, fields, format, args...)
+}
+
+// Logf writes a structured entry at the given level whose message field is
+// formatted from format and args. Formatting is skipped when the level is
+// disabled.
+func (l *Logger) Logf(level logLevel, ctx context.Context, fields map[string]interface{}, format string, args ...interface{}) {
+	if !l.shouldLogCtx(ctx, level) {
+		return
 	}
-	err := k.writer.WriteMessages(context.Background(), msg)
-	if err != nil {
-		return 0, err
+	entry := getFieldMap()
+	defer putFieldMap(entry)
+	for k, v := range fields {
+		entry[k] = v
+	}
+	entry["message"] = fmt.Sprintf(format, args...)
+	if level, ok := l.writeStructured(level, ctx, entry, time.Now(), format); ok && level == LevelFatal {
+		l.exitFatal()
 	}
-	return len(p), nil
 }
 
-func newKafkaWriter(kafkaBrokers []string, kafkaTopic string) io.Writer {
-	return &kafkaLogWriter{
-		topic: kafkaTopic,
-		writer: &kafka.Writer{
-			Addr:         kafka.TCP(kafkaBrokers...), // Replace with your broker(s)
-			Balancer:     &kafka.LeastBytes{},
-			RequiredAcks: kafka.RequireNone,
-		},
-	}
+func InfoMapf(ctx context.Context, fields map[string]interface{}, format string, args ...interface{}) {
+	std.Logf(LevelInfo, ctx, fields, format, args...)
+}
+func WarningMapf(ctx context.Context, fields map[string]interface{}, format string, args ...interface{}) {
+	std.Logf(LevelWarn, ctx, fields, format, args...)
+}
+func ErrorMapf(ctx context.Context, fields map[string]interface{}, format string, args ...interface{}) {
+	std.Logf(LevelError, ctx, fields, format, args...)
+}
+func DebugMapf(ctx context.Context, fields map[string]interface{}, format string, args ...interface{}) {
+	std.Logf(LevelDebug, ctx, fields, format, args...)
+}
+func TraceMapf(ctx context.Context, fields map[string]interface{}, format string, args ...interface{}) {
+	std.Logf(LevelTrace, ctx, fields, format, args...)
+}
+func FatalMapf(ctx context.Context, fields map[string]interface{}, format string, args ...interface{}) {
+	std.Logf(LevelFatal, ctx, fields, format, args...)
 }
 
-type kafkaLogWriter struct {
-	writer *kafka.Writer
-	topic  string
+func (l *Logger) InfoMapf(ctx context.Context, fields map[string]interface{}, format string, args ...interface{}) {
+	l.Logf(LevelInfo, ctx, fields, format, args...)
+}
+func (l *Logger) WarningMapf(ctx context.Context, fields map[string]interface{}, format string, args ...interface{}) {
+	l.Logf(LevelWarn, ctx, fields, format, args...)
+}
+func (l *Logger) ErrorMapf(ctx context.Context, fields map[string]interface{}, format string, args ...interface{}) {
+	l.Logf(LevelError, ctx, fields, format, args...)
+}
+func (l *Logger) DebugMapf(ctx context.Context, fields map[string]interface{}, format string, args ...interface{}) {
+	l.Logf(LevelDebug, ctx, fields, format, args...)
+}
+func (l *Logger) TraceMapf(ctx context.Context, fields map[string]interface{}, format string, args ...interface{}) {
+	l.Logf(LevelTrace, ctx, fields, format, args...)
+}
+func (l *Logger) FatalMapf(ctx context.Context, fields map[string]interface{}, format string, args ...interface{}) {
+	l.Logf(LevelFatal, ctx, fields, format, args...)
 }
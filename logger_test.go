@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"log"
+	"io"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -89,13 +92,7 @@ func TestTimestampFormatRFC3339(t *testing.T) {
 }
 
 func initTestLogger(buf *bytes.Buffer, format, level string) {
-	currentLevel = level
-	structuredWriter = buf
-
-	infoLogger = log.New(&jsonLogger{"INFO", buf}, "", 0)
-	warningLogger = log.New(&jsonLogger{"WARNING", buf}, "", 0)
-	errorLogger = log.New(&jsonLogger{"ERROR", buf}, "", 0)
-	debugLogger = log.New(&jsonLogger{"DEBUG", buf}, "", 0)
+	Init(level, format, "", "", false, false, false, nil, nil, buf)
 }
 
 func checkLogJSON(t *testing.T, logLine string, expectedLevel, expectedMessage string) {
@@ -110,3 +107,205 @@ func checkLogJSON(t *testing.T, logLine string, expectedLevel, expectedMessage s
 		t.Errorf("log message does not contain expected text: %s", expectedMessage)
 	}
 }
+
+func decodeLogLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid JSON log: %v\nlog: %s", err, line)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// lockedWriter serializes writes for tests that log from several goroutines.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+func TestLogf_MapAndFormatHybrid(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	Logf(LevelWarn, context.Background(), map[string]interface{}{"user": "alice"}, "retry %d of %d", 2, 3)
+	ErrorMapf(nil, nil, "no fields %s", "needed")
+
+	entries := decodeLogLines(t, &buf)
+	if entries[0]["level"] != "WARNING" || entries[0]["message"] != "retry 2 of 3" || entries[0]["user"] != "alice" {
+		t.Errorf("unexpected hybrid entry: %v", entries[0])
+	}
+	if entries[1]["level"] != "ERROR" || entries[1]["message"] != "no fields needed" {
+		t.Errorf("expected nil field map to be accepted, got %v", entries[1])
+	}
+}
+
+func TestFatalfMap_Exits(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+	code := stubExit(t)
+
+	FatalfMap(context.Background(), map[string]interface{}{"event": "unrecoverable"})
+
+	if *code != 1 {
+		t.Errorf("expected FatalfMap to exit with 1, got %d", *code)
+	}
+	if e := decodeLogLines(t, &buf)[0]; e["level"] != "FATAL" || e["event"] != "unrecoverable" {
+		t.Errorf("unexpected fatal entry: %v", e)
+	}
+}
+
+func TestInit_CustomOutputs(t *testing.T) {
+	var first, second bytes.Buffer
+	Init("info", "json", "", "", false, false, false, nil, nil, &first, nil, &second)
+
+	Info("to every output")
+
+	checkLogJSON(t, first.String(), "INFO", "to every output")
+	checkLogJSON(t, second.String(), "INFO", "to every output")
+
+	stats := SinkStatistics()
+	if len(stats) != 2 || stats[0].Name != "custom-0" || stats[1].Name != "custom-2" {
+		t.Errorf("unexpected sink names: %+v", stats)
+	}
+}
+
+func TestNew_IndependentInstances(t *testing.T) {
+	var global, audit, debug bytes.Buffer
+	initTestLogger(&global, "json", "error")
+
+	a := New(WithLevel("info"), WithFormat("json"), WithService("audit", "prod"), WithOutputs(&audit))
+	d := New(WithLevel("debug"), WithFormat("text"), WithOutputs(&debug))
+
+	a.InfofMap(nil, map[string]interface{}{"message": "login"})
+	a.Debug("filtered by the audit level")
+	d.Debugf("cache size %d", 3)
+	Info("filtered by the global level")
+
+	if global.Len() != 0 {
+		t.Errorf("instances must not write to the package-level outputs, got %s", global.String())
+	}
+	entries := decodeLogLines(t, &audit)
+	if len(entries) != 1 || entries[0]["service"] != "audit" || entries[0]["message"] != "login" {
+		t.Errorf("unexpected audit entries: %v", entries)
+	}
+	if !strings.Contains(debug.String(), "DEBUG: ") || !strings.Contains(debug.String(), "logger_test.go") {
+		t.Errorf("expected text entry with the caller's file, got %q", debug.String())
+	}
+}
+
+func TestConcurrentLoggingAndReconfiguration(t *testing.T) {
+	var buf bytes.Buffer
+	// Successive Inits wrap the same buffer in different sinks.
+	out := &lockedWriter{w: &buf}
+	Init("debug", "json", "", "", false, false, false, nil, nil, out)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				Info("plain")
+				InfofMap(nil, map[string]interface{}{"n": j})
+			}
+		}()
+	}
+	for j := 0; j < 20; j++ {
+		SetMetadata("svc", "env")
+		Init("info", "json", "", "", false, false, false, nil, nil, out)
+	}
+	wg.Wait()
+	SetMetadata("", "")
+
+	decodeLogLines(t, &buf)
+}
+
+func TestTraceLevel(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+	Trace("hidden at debug")
+	TracefMap(nil, map[string]interface{}{"message": "also hidden"})
+	if buf.Len() != 0 {
+		t.Fatalf("expected trace entries to be filtered at debug, got %s", buf.String())
+	}
+
+	initTestLogger(&buf, "json", "trace")
+	Tracef("wire dump %x", []byte("hi"))
+	TraceMapf(nil, map[string]interface{}{"conn": 4}, "frame %d", 1)
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 2 || entries[0]["level"] != "TRACE" || entries[0]["message"] != "wire dump 6869" {
+		t.Fatalf("unexpected trace entries: %v", entries)
+	}
+	if entries[1]["level"] != "TRACE" || entries[1]["conn"] != float64(4) {
+		t.Errorf("unexpected structured trace entry: %v", entries[1])
+	}
+}
+
+func TestZeroLogger_Discards(t *testing.T) {
+	var l Logger
+	l.Info("plain")
+	l.Errorf("formatted %d", 1)
+	l.WarningCtx(context.Background(), "with context")
+	l.InfofMap(nil, map[string]interface{}{"message": "structured"})
+	l.With(map[string]interface{}{"k": "v"}).Info("child")
+	l.StdLogger(LevelWarn).Print("bridged")
+}
+
+func TestInitWithOptions_RetiresPreviousConfiguration(t *testing.T) {
+	prevGrace := retireGrace
+	retireGrace = 0
+	defer func() { retireGrace = prevGrace }()
+	defer InitWithOptions()
+
+	settle := func(count func() int, limit int) int {
+		deadline := time.Now().Add(2 * time.Second)
+		n := count()
+		for n > limit && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+			n = count()
+		}
+		return n
+	}
+
+	InitWithOptions(WithAsync(AsyncConfig{Workers: 4}))
+	before := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		InitWithOptions(WithAsync(AsyncConfig{Workers: 4}))
+		Info("async")
+	}
+	if n := settle(runtime.NumGoroutine, before); n > before {
+		t.Errorf("goroutines grew from %d to %d over repeated Init calls", before, n)
+	}
+
+	fds := func() int { n, _ := openFDs(); return n }
+	if _, ok := openFDs(); !ok {
+		return
+	}
+	// Goroutines aren't counted here: lumberjack's mill goroutine outlives
+	// Close.
+	path := filepath.Join(t.TempDir(), "app.log")
+	InitWithOptions(WithFile(path))
+	Info("file")
+	beforeFDs := fds()
+	for i := 0; i < 20; i++ {
+		InitWithOptions(WithFile(path))
+		Info("file")
+	}
+	if n := settle(fds, beforeFDs); n > beforeFDs {
+		t.Errorf("open files grew from %d to %d over repeated Init calls", beforeFDs, n)
+	}
+}
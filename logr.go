@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// Logr returns a logr.Logger that writes through the package-level logger,
+// for controller-runtime, client-go and other logr users:
+//
+//	ctrl.SetLogger(logger.Logr())
+func Logr() logr.Logger {
+	return std.Logr()
+}
+
+// Logr returns a logr.Logger that writes through l. V(0) maps to INFO, V(1)
+// to DEBUG and higher verbosity to TRACE; keysAndValues become fields and
+// WithName creates Named loggers, so their levels can be overridden with
+// SetNamedLevel.
+func (l *Logger) Logr() logr.Logger {
+	return logr.New(&logrSink{l: l})
+}
+
+// logrSink implements logr.LogSink on top of the structured logging path.
+type logrSink struct {
+	l      *Logger
+	values []interface{}
+}
+
+var _ logr.LogSink = (*logrSink)(nil)
+
+func (s *logrSink) Init(logr.RuntimeInfo) {}
+
+func (s *logrSink) Enabled(level int) bool {
+	return s.l.shouldLog(logrLevel(level))
+}
+
+func (s *logrSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.l.logWithMapAt(logrLevel(level), nil, s.fields(msg, keysAndValues), time.Now())
+}
+
+func (s *logrSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	fields := s.fields(msg, keysAndValues)
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	s.l.logWithMapAt(LevelError, nil, fields, time.Now())
+}
+
+func (s *logrSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	values := make([]interface{}, 0, len(s.values)+len(keysAndValues))
+	values = append(values, s.values...)
+	values = append(values, keysAndValues...)
+	return &logrSink{l: s.l, values: values}
+}
+
+func (s *logrSink) WithName(name string) logr.LogSink {
+	return &logrSink{l: s.l.Named(name), values: s.values}
+}
+
+func (s *logrSink) fields(msg string, keysAndValues []interface{}) map[string]interface{} {
+	fields := make(map[string]interface{}, (len(s.values)+len(keysAndValues))/2+2)
+	addKeysAndValues(fields, s.values)
+	addKeysAndValues(fields, keysAndValues)
+	fields["message"] = msg
+	return fields
+}
+
+func logrLevel(v int) logLevel {
+	switch {
+	case v <= 0:
+		return LevelInfo
+	case v == 1:
+		return LevelDebug
+	default:
+		return LevelTrace
+	}
+}
+
+// addKeysAndValues copies alternating key/value pairs into fields. A trailing
+// key without a value is recorded under "!BADKEY" and errors are recorded by
+// their message.
+func addKeysAndValues(fields map[string]interface{}, kv []interface{}) {
+	for i := 0; i < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprint(kv[i])
+		}
+		if i+1 >= len(kv) {
+			fields["!BADKEY"] = key
+			break
+		}
+		if err, ok := kv[i+1].(error); ok {
+			fields[key] = err.Error()
+			continue
+		}
+		fields[key] = kv[i+1]
+	}
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestLogr_VLevelsAndFields(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	log := Logr().WithName("controller").WithValues("reconciler", "pods")
+	log.Info("reconciling", "namespace", "default")
+	log.V(1).Info("cache hit")
+	log.V(2).Info("trace filtered at debug")
+	log.WithName("queue").Error(errors.New("conflict"), "requeue", "attempt", 2, "cause", errors.New("stale"))
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %v", entries)
+	}
+	if e := entries[0]; e["level"] != "INFO" || e["logger"] != "controller" || e["reconciler"] != "pods" || e["namespace"] != "default" {
+		t.Errorf("unexpected entry: %v", e)
+	}
+	if entries[1]["level"] != "DEBUG" {
+		t.Errorf("expected V(1) to map to DEBUG, got %v", entries[1])
+	}
+	if e := entries[2]; e["level"] != "ERROR" || e["logger"] != "controller.queue" || e["error"] != "conflict" || e["cause"] != "stale" {
+		t.Errorf("unexpected error entry: %v", e)
+	}
+
+	buf.Reset()
+	if err := SetNamedLevel("controller", "trace"); err != nil {
+		t.Fatal(err)
+	}
+	log.V(3).Info("now visible")
+	checkLogJSON(t, buf.String(), "TRACE", "now visible")
+}
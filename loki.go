@@ -0,0 +1,264 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LokiConfig configures the Grafana Loki sink.
+type LokiConfig struct {
+	// URL is Loki's base URL ("http://loki:3100") or the full push endpoint.
+	URL string
+	// Labels are attached to every stream.
+	Labels map[string]string
+	// LabelFields are entry fields promoted to stream labels; defaults to
+	// service, environment and level. Keep them low-cardinality.
+	LabelFields []string
+	// TenantID is sent as X-Scope-OrgID for multi-tenant Loki.
+	TenantID string
+	// Username and Password enable basic auth; BearerToken bearer auth.
+	Username    string
+	Password    string
+	BearerToken string
+	// BatchSize and BatchInterval control batching; defaults are 500 entries
+	// and 1 second.
+	BatchSize     int
+	BatchInterval time.Duration
+	// Client sends the requests; defaults to a client with a 10 second
+	// timeout.
+	Client *http.Client
+}
+
+const lokiPushPath = "/loki/api/v1/push"
+
+var defaultLokiLabelFields = []string{"service", "environment", "level"}
+
+// LokiWriter pushes entries to Loki in batches. Writes do not block on the
+// network; delivery errors are reported by LastError and by the next Write.
+type LokiWriter struct {
+	cfg      LokiConfig
+	endpoint string
+	client   *http.Client
+	batch    *batcher[lokiEntry]
+}
+
+type lokiEntry struct {
+	at     time.Time
+	line   string
+	labels map[string]string
+}
+
+// NewLokiWriter validates cfg and returns a sink that can be passed to Init
+// as an additional output.
+func NewLokiWriter(cfg LokiConfig) (*LokiWriter, error) {
+	endpoint, err := lokiEndpoint(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.BearerToken != "" && cfg.Username != "" {
+		return nil, errors.New("loki: set either basic auth or BearerToken, not both")
+	}
+	if cfg.LabelFields == nil {
+		cfg.LabelFields = defaultLokiLabelFields
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	interval := cfg.BatchInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	w := &LokiWriter{cfg: cfg, endpoint: endpoint, client: client}
+	w.batch = newBatcher(batchSize, interval, w.push)
+	return w, nil
+}
+
+func lokiEndpoint(raw string) (string, error) {
+	if raw == "" {
+		return "", errors.New("loki: URL is required")
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("loki: invalid URL %q", raw)
+	}
+	if !strings.HasSuffix(u.Path, lokiPushPath) {
+		u.Path = strings.TrimSuffix(u.Path, "/") + lokiPushPath
+	}
+	return u.String(), nil
+}
+
+// Write queues each line of p.
+func (w *LokiWriter) Write(p []byte) (int, error) {
+	now := time.Now()
+	var err error
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if aerr := w.batch.add(w.entry(line, now)); aerr != nil && err == nil {
+			err = aerr
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("loki: %w", err)
+	}
+	return len(p), nil
+}
+
+// entry picks the stream labels of line from the configured label fields,
+// or from the level prefix of text entries.
+func (w *LokiWriter) entry(line []byte, at time.Time) lokiEntry {
+	labels := make(map[string]string, len(w.cfg.Labels)+len(w.cfg.LabelFields))
+	for k, v := range w.cfg.Labels {
+		labels[lokiLabelName(k)] = v
+	}
+
+	var fields map[string]interface{}
+	if len(line) > 1 && line[0] == '{' && json.Unmarshal(line, &fields) == nil {
+		for _, k := range w.cfg.LabelFields {
+			if v, ok := fields[k]; ok {
+				if s := fmt.Sprint(v); s != "" {
+					labels[lokiLabelName(k)] = s
+				}
+			}
+		}
+	} else {
+		for _, k := range w.cfg.LabelFields {
+			if k == "level" {
+				level, _ := syslogEntryMeta(line)
+				labels["level"] = string(level)
+			}
+		}
+	}
+	if len(labels) == 0 {
+		// Loki rejects streams without labels.
+		labels["job"] = "go-logger"
+	}
+	return lokiEntry{at: at, line: string(line), labels: labels}
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (w *LokiWriter) push(entries []lokiEntry) error {
+	streams := map[string]*lokiStream{}
+	var order []string
+	for _, e := range entries {
+		key := lokiStreamKey(e.labels)
+		s, ok := streams[key]
+		if !ok {
+			s = &lokiStream{Stream: e.labels}
+			streams[key] = s
+			order = append(order, key)
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(e.at.UnixNano(), 10), e.line})
+	}
+	payload := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{}
+	for _, key := range order {
+		payload.Streams = append(payload.Streams, streams[key])
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, w.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.cfg.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", w.cfg.TenantID)
+	}
+	switch {
+	case w.cfg.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+w.cfg.BearerToken)
+	case w.cfg.Username != "":
+		req.SetBasicAuth(w.cfg.Username, w.cfg.Password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("push rejected: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Flush pushes the pending entries.
+func (w *LokiWriter) Flush() error {
+	if err := w.batch.flush(); err != nil {
+		return fmt.Errorf("loki: %w", err)
+	}
+	return nil
+}
+
+// LastError returns the most recent asynchronous delivery error.
+func (w *LokiWriter) LastError() error {
+	return w.batch.lastError()
+}
+
+// Close pushes the pending entries and stops the background pushes.
+func (w *LokiWriter) Close() error {
+	if err := w.batch.close(); err != nil {
+		return fmt.Errorf("loki: %w", err)
+	}
+	return nil
+}
+
+var _ io.WriteCloser = (*LokiWriter)(nil)
+
+func lokiStreamKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[k]))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+// lokiLabelName turns a key into a Prometheus-style label name.
+func lokiLabelName(k string) string {
+	name := []byte(k)
+	for i, c := range name {
+		ok := c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9'
+		if !ok {
+			name[i] = '_'
+		}
+	}
+	if len(name) == 0 {
+		return "_"
+	}
+	return string(name)
+}
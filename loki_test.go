@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLokiWriter_Push(t *testing.T) {
+	type push struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	got := make(chan push, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/loki/api/v1/push" || r.Header.Get("X-Scope-OrgID") != "team-a" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Header)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "u" || pass != "p" {
+			t.Errorf("expected basic auth, got %q %q", user, pass)
+		}
+		var p push
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Error(err)
+		}
+		got <- p
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	w, err := NewLokiWriter(LokiConfig{
+		URL:           srv.URL,
+		Labels:        map[string]string{"cluster": "eu-1"},
+		TenantID:      "team-a",
+		Username:      "u",
+		Password:      "p",
+		BatchInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte(`{"level":"INFO","service":"billing","message":"a"}` + "\n"))
+	_, _ = w.Write([]byte(`{"level":"ERROR","service":"billing","message":"b"}` + "\n"))
+	_, _ = w.Write([]byte(`{"level":"INFO","service":"billing","message":"c"}` + "\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	p := <-got
+	if len(p.Streams) != 2 {
+		t.Fatalf("expected one stream per level, got %+v", p.Streams)
+	}
+	info := p.Streams[0]
+	if info.Stream["level"] != "INFO" || info.Stream["service"] != "billing" || info.Stream["cluster"] != "eu-1" || len(info.Values) != 2 {
+		t.Errorf("unexpected stream %+v", info)
+	}
+	if info.Values[0][1] != `{"level":"INFO","service":"billing","message":"a"}` {
+		t.Errorf("unexpected line %q", info.Values[0][1])
+	}
+}
+
+func TestLokiWriter_ReportsRejectedPush(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "entry out of order", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	w, err := NewLokiWriter(LokiConfig{URL: srv.URL + "/loki/api/v1/push", BearerToken: "tok"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte("ERROR: 2025/05/11 main.go:1: boom\n"))
+	if e := w.entry([]byte(`time=2025-05-11T19:30:12Z level=WARNING msg="disk low"`), time.Now()); e.labels["level"] != "WARNING" {
+		t.Errorf("expected the level of logfmt entries, got %v", e.labels)
+	}
+	if err := w.Flush(); err == nil {
+		t.Errorf("expected the rejected push to be reported")
+	}
+}
+
+func TestNewLokiWriter_Validation(t *testing.T) {
+	for _, cfg := range []LokiConfig{
+		{},
+		{URL: "not a url"},
+		{URL: "http://loki:3100", Username: "u", BearerToken: "t"},
+	} {
+		if _, err := NewLokiWriter(cfg); err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+}
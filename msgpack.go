@@ -0,0 +1,289 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// A minimal MessagePack encoder and decoder for the Fluentd forward
+// protocol. It covers the types JSON entries decode to plus binary data and
+// the forward protocol's EventTime extension.
+
+type msgpackEventTime time.Time
+
+func appendMsgpack(b []byte, v interface{}) ([]byte, error) {
+	switch t := v.(type) {
+	case nil:
+		return append(b, 0xc0), nil
+	case bool:
+		if t {
+			return append(b, 0xc3), nil
+		}
+		return append(b, 0xc2), nil
+	case int:
+		return appendMsgpackInt(b, int64(t)), nil
+	case int64:
+		return appendMsgpackInt(b, t), nil
+	case uint32:
+		return appendMsgpackInt(b, int64(t)), nil
+	case float64:
+		b = append(b, 0xcb)
+		return binary.BigEndian.AppendUint64(b, math.Float64bits(t)), nil
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return appendMsgpackInt(b, i), nil
+		}
+		f, err := t.Float64()
+		if err != nil {
+			return appendMsgpackString(b, t.String()), nil
+		}
+		return appendMsgpack(b, f)
+	case string:
+		return appendMsgpackString(b, t), nil
+	case logLevel:
+		return appendMsgpackString(b, string(t)), nil
+	case []byte:
+		return appendMsgpackBinary(b, t), nil
+	case msgpackEventTime:
+		// fixext 8, type 0: seconds and nanoseconds as big-endian uint32.
+		tm := time.Time(t)
+		b = append(b, 0xd7, 0x00)
+		b = binary.BigEndian.AppendUint32(b, uint32(tm.Unix()))
+		return binary.BigEndian.AppendUint32(b, uint32(tm.Nanosecond())), nil
+	case []interface{}:
+		b = appendMsgpackLen(b, len(t), 0x90, 0xdc, 0xdd)
+		for _, e := range t {
+			var err error
+			if b, err = appendMsgpack(b, e); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b = appendMsgpackLen(b, len(t), 0x80, 0xde, 0xdf)
+		for _, k := range keys {
+			b = appendMsgpackString(b, k)
+			var err error
+			if b, err = appendMsgpack(b, t[k]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("msgpack: unsupported type %T", v)
+}
+
+func appendMsgpackInt(b []byte, i int64) []byte {
+	switch {
+	case i >= 0 && i <= 0x7f:
+		return append(b, byte(i))
+	case i < 0 && i >= -32:
+		return append(b, byte(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		b = append(b, 0xd2)
+		return binary.BigEndian.AppendUint32(b, uint32(i))
+	default:
+		b = append(b, 0xd3)
+		return binary.BigEndian.AppendUint64(b, uint64(i))
+	}
+}
+
+func appendMsgpackString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = append(b, 0xda)
+		b = binary.BigEndian.AppendUint16(b, uint16(n))
+	default:
+		b = append(b, 0xdb)
+		b = binary.BigEndian.AppendUint32(b, uint32(n))
+	}
+	return append(b, s...)
+}
+
+func appendMsgpackBinary(b []byte, p []byte) []byte {
+	switch n := len(p); {
+	case n <= math.MaxUint8:
+		b = append(b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		b = append(b, 0xc5)
+		b = binary.BigEndian.AppendUint16(b, uint16(n))
+	default:
+		b = append(b, 0xc6)
+		b = binary.BigEndian.AppendUint32(b, uint32(n))
+	}
+	return append(b, p...)
+}
+
+func appendMsgpackLen(b []byte, n int, fix, code16, code32 byte) []byte {
+	switch {
+	case n < 16:
+		return append(b, fix|byte(n))
+	case n <= math.MaxUint16:
+		b = append(b, code16)
+		return binary.BigEndian.AppendUint16(b, uint16(n))
+	default:
+		b = append(b, code32)
+		return binary.BigEndian.AppendUint32(b, uint32(n))
+	}
+}
+
+var errMsgpackType = errors.New("msgpack: unsupported type")
+
+// readMsgpack decodes one value: nil, bool, int64, float64, string (str and
+// bin), msgpackEventTime, []interface{} or map[string]interface{}.
+func readMsgpack(r *bufio.Reader) (interface{}, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xe0 == 0xa0:
+		return readMsgpackString(r, int(c&0x1f))
+	case c&0xf0 == 0x90:
+		return readMsgpackArray(r, int(c&0x0f))
+	case c&0xf0 == 0x80:
+		return readMsgpackMap(r, int(c&0x0f))
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xd9:
+		n, err := readMsgpackUint(r, 1)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackString(r, int(n))
+	case 0xc5, 0xda:
+		n, err := readMsgpackUint(r, 2)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackString(r, int(n))
+	case 0xc6, 0xdb:
+		n, err := readMsgpackUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackString(r, int(n))
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := readMsgpackUint(r, 1<<(c-0xcc))
+		return int64(n), err
+	case 0xd0:
+		n, err := readMsgpackUint(r, 1)
+		return int64(int8(n)), err
+	case 0xd1:
+		n, err := readMsgpackUint(r, 2)
+		return int64(int16(n)), err
+	case 0xd2:
+		n, err := readMsgpackUint(r, 4)
+		return int64(int32(n)), err
+	case 0xd3:
+		n, err := readMsgpackUint(r, 8)
+		return int64(n), err
+	case 0xca:
+		n, err := readMsgpackUint(r, 4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err := readMsgpackUint(r, 8)
+		return math.Float64frombits(n), err
+	case 0xd7:
+		var buf [9]byte
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return nil, err
+		}
+		if buf[0] != 0 {
+			return nil, fmt.Errorf("%w: extension %d", errMsgpackType, int8(buf[0]))
+		}
+		sec, nsec := binary.BigEndian.Uint32(buf[1:5]), binary.BigEndian.Uint32(buf[5:])
+		return msgpackEventTime(time.Unix(int64(sec), int64(nsec))), nil
+	case 0xdc, 0xde:
+		n, err := readMsgpackUint(r, 2)
+		if err != nil {
+			return nil, err
+		}
+		if c == 0xdc {
+			return readMsgpackArray(r, int(n))
+		}
+		return readMsgpackMap(r, int(n))
+	case 0xdd, 0xdf:
+		n, err := readMsgpackUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		if c == 0xdd {
+			return readMsgpackArray(r, int(n))
+		}
+		return readMsgpackMap(r, int(n))
+	}
+	return nil, fmt.Errorf("%w 0x%02x", errMsgpackType, c)
+}
+
+func readMsgpackUint(r *bufio.Reader, size int) (uint64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[:size]); err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, c := range buf[:size] {
+		n = n<<8 | uint64(c)
+	}
+	return n, nil
+}
+
+func readMsgpackString(r *bufio.Reader, n int) (string, error) {
+	buf := make([]byte, n)
+	_, err := io.ReadFull(r, buf)
+	return string(buf), err
+}
+
+func readMsgpackArray(r *bufio.Reader, n int) ([]interface{}, error) {
+	a := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		v, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+	}
+	return a, nil
+}
+
+func readMsgpackMap(r *bufio.Reader, n int) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		v, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		m[fmt.Sprint(k)] = v
+	}
+	return m, nil
+}
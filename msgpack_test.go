@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMsgpack_RoundTrip(t *testing.T) {
+	at := time.Unix(1746991812, 500)
+	in := []interface{}{
+		"tag",
+		msgpackEventTime(at),
+		map[string]interface{}{
+			"n":     json.Number("42"),
+			"neg":   -7,
+			"big":   int64(1) << 40,
+			"f":     json.Number("1.5"),
+			"ok":    true,
+			"none":  nil,
+			"long":  strings.Repeat("x", 300),
+			"items": []interface{}{"a", "b"},
+		},
+	}
+	data, err := appendMsgpack(nil, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := readMsgpack(bufio.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{
+		"tag",
+		msgpackEventTime(at),
+		map[string]interface{}{
+			"n":     int64(42),
+			"neg":   int64(-7),
+			"big":   int64(1) << 40,
+			"f":     1.5,
+			"ok":    true,
+			"none":  nil,
+			"long":  strings.Repeat("x", 300),
+			"items": []interface{}{"a", "b"},
+		},
+	}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("round trip mismatch:\n got %#v\nwant %#v", out, want)
+	}
+}
+
+func TestMsgpack_UnsupportedType(t *testing.T) {
+	if _, err := appendMsgpack(nil, struct{}{}); err == nil {
+		t.Errorf("expected an error for an unsupported type")
+	}
+}
@@ -0,0 +1,65 @@
+package logger
+
+import "strings"
+
+// Named returns a child of the package-level logger for a subsystem. See
+// Logger.Named.
+func Named(name string) *Logger {
+	return std.Named(name)
+}
+
+// SetNamedLevel overrides the minimum level of the package-level logger's
+// named child. See Logger.SetNamedLevel.
+func SetNamedLevel(name, level string) error {
+	return std.SetNamedLevel(name, level)
+}
+
+// Named returns a child logger for a subsystem such as "db" or "http". Its
+// entries carry logger=name, and its level can be overridden independently
+// with SetNamedLevel or WithNamedLevel, e.g. to turn on debug for one
+// subsystem only. Naming a named logger joins the names with a dot
+// ("http.client"); a child without its own override uses its closest named
+// ancestor's.
+func (l *Logger) Named(name string) *Logger {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	child := l.WithField("logger", name)
+	child.name = name
+	return child
+}
+
+// SetNamedLevel overrides the minimum level of the logger named name and its
+// descendants at runtime. An empty level removes the override.
+func (l *Logger) SetNamedLevel(name, level string) error {
+	if err := checkLevelName(level); err != nil {
+		return err
+	}
+	l.update(func(st *loggerState) {
+		next := make(map[string]string, len(st.namedLevels)+1)
+		for k, v := range st.namedLevels {
+			next[k] = v
+		}
+		if level == "" {
+			delete(next, name)
+		} else {
+			next[name] = level
+		}
+		st.namedLevels = next
+	})
+	return nil
+}
+
+// namedLevel returns the override for name or its closest named ancestor.
+func (st *loggerState) namedLevel(name string) (string, bool) {
+	for {
+		if level, ok := st.namedLevels[name]; ok {
+			return level, true
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return "", false
+		}
+		name = name[:i]
+	}
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNamed_LevelOverrides(t *testing.T) {
+	var buf bytes.Buffer
+	InitWithOptions(WithLevel("info"), WithFormat("json"), WithOutputs(&buf), WithNamedLevel("db", "debug"))
+
+	db := Named("db")
+	httpLog := Named("http")
+
+	db.Debug("query plan")
+	db.Named("pool").Debugf("idle %d", 3)
+	httpLog.Debug("filtered")
+	Debug("filtered too")
+
+	if err := SetNamedLevel("http", "error"); err != nil {
+		t.Fatal(err)
+	}
+	httpLog.Warning("filtered by the runtime override")
+	httpLog.Error("kept")
+
+	if err := SetNamedLevel("db", "loud"); err == nil {
+		t.Errorf("expected unknown level to be rejected")
+	}
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %v", entries)
+	}
+	if entries[0]["logger"] != "db" || entries[1]["logger"] != "db.pool" || entries[2]["logger"] != "http" {
+		t.Errorf("unexpected logger names: %v", entries)
+	}
+
+	_ = SetNamedLevel("db", "")
+	buf.Reset()
+	db.Debug("override removed")
+	if buf.Len() != 0 {
+		t.Errorf("expected the override to be removed")
+	}
+}
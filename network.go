@@ -0,0 +1,267 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NetworkConfig configures the TCP/UDP sink.
+type NetworkConfig struct {
+	// Network is "tcp" (the default) or "udp"; Addr is the endpoint's
+	// host:port.
+	Network string
+	Addr    string
+	// TLS enables TLS with the given configuration (TCP only).
+	TLS *tls.Config
+	// BufferSize bounds the bytes held while disconnected; once it is full
+	// the oldest entries are dropped. Defaults to 1MB.
+	BufferSize int
+	// MinBackoff and MaxBackoff bound the delay between reconnection
+	// attempts, which doubles after every failure; defaults are 100ms and
+	// 30 seconds.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Timeout bounds connecting and each write; defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// NetworkWriter writes newline-delimited entries to a TCP or UDP endpoint;
+// each UDP datagram holds one entry. Pass it to Init as an additional output.
+// While the endpoint is unreachable, entries are buffered and sent once a
+// background reconnection succeeds; a failed write may resend entries the
+// endpoint already received.
+type NetworkWriter struct {
+	cfg NetworkConfig
+
+	mu           sync.Mutex
+	conn         net.Conn
+	buf          [][]byte
+	buffered     int
+	reconnecting bool
+	lastErr      error
+
+	dropped atomic.Uint64
+
+	stop      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewNetworkWriter validates cfg and connects to the endpoint. An
+// unreachable endpoint is not an error: entries are buffered until it is
+// reachable.
+func NewNetworkWriter(cfg NetworkConfig) (*NetworkWriter, error) {
+	switch cfg.Network {
+	case "":
+		cfg.Network = "tcp"
+	case "tcp", "udp":
+	default:
+		return nil, fmt.Errorf("network: unknown network %q (want tcp or udp)", cfg.Network)
+	}
+	if _, _, err := net.SplitHostPort(cfg.Addr); err != nil {
+		return nil, fmt.Errorf("network: invalid Addr %q: %w", cfg.Addr, err)
+	}
+	if cfg.TLS != nil && cfg.Network == "udp" {
+		return nil, errors.New("network: TLS requires tcp")
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 1 << 20
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = 100 * time.Millisecond
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = 30 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	w := &NetworkWriter{cfg: cfg, stop: make(chan struct{})}
+	conn, err := w.dial()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		w.disconnectedLocked(err)
+	} else {
+		w.conn = conn
+	}
+	return w, nil
+}
+
+func (w *NetworkWriter) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: w.cfg.Timeout}
+	if w.cfg.TLS != nil {
+		return tls.DialWithDialer(dialer, "tcp", w.cfg.Addr, w.cfg.TLS)
+	}
+	return dialer.Dial(w.cfg.Network, w.cfg.Addr)
+}
+
+// Write sends each line of p, or buffers it while disconnected.
+func (w *NetworkWriter) Write(p []byte) (int, error) {
+	var lines [][]byte
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		lines = append(lines, append(line[:len(line):len(line)], '\n'))
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil {
+		if err := w.send(w.conn, lines); err != nil {
+			w.conn.Close()
+			w.conn = nil
+			w.disconnectedLocked(err)
+		} else {
+			return len(p), nil
+		}
+	}
+	for _, line := range lines {
+		w.bufferLocked(line)
+	}
+	return len(p), nil
+}
+
+func (w *NetworkWriter) send(conn net.Conn, lines [][]byte) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(w.cfg.Timeout))
+	defer conn.SetWriteDeadline(time.Time{})
+	if w.cfg.Network == "udp" {
+		for _, line := range lines {
+			if _, err := conn.Write(line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	_, err := conn.Write(bytes.Join(lines, nil))
+	return err
+}
+
+// bufferLocked queues line, dropping the oldest entries to stay within
+// BufferSize.
+func (w *NetworkWriter) bufferLocked(line []byte) {
+	if len(line) > w.cfg.BufferSize {
+		w.dropped.Add(1)
+		return
+	}
+	w.buf = append(w.buf, line)
+	w.buffered += len(line)
+	for w.buffered > w.cfg.BufferSize {
+		w.buffered -= len(w.buf[0])
+		w.buf[0] = nil
+		w.buf = w.buf[1:]
+		w.dropped.Add(1)
+	}
+}
+
+// disconnectedLocked records err and starts reconnecting in the background.
+func (w *NetworkWriter) disconnectedLocked(err error) {
+	w.lastErr = fmt.Errorf("network: %w", err)
+	if w.reconnecting {
+		return
+	}
+	select {
+	case <-w.stop:
+		return
+	default:
+	}
+	w.reconnecting = true
+	w.wg.Add(1)
+	go w.reconnect()
+}
+
+func (w *NetworkWriter) reconnect() {
+	defer w.wg.Done()
+	backoff := w.cfg.MinBackoff
+	for {
+		timer := time.NewTimer(backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1)))
+		select {
+		case <-w.stop:
+			timer.Stop()
+			w.mu.Lock()
+			w.reconnecting = false
+			w.mu.Unlock()
+			return
+		case <-timer.C:
+		}
+
+		conn, err := w.dial()
+		if err == nil {
+			w.mu.Lock()
+			err = w.send(conn, w.buf)
+			if err == nil {
+				w.buf, w.buffered = nil, 0
+				w.conn = conn
+				w.reconnecting = false
+				w.lastErr = nil
+				w.mu.Unlock()
+				return
+			}
+			w.mu.Unlock()
+			conn.Close()
+		}
+		w.mu.Lock()
+		w.lastErr = fmt.Errorf("network: %w", err)
+		w.mu.Unlock()
+
+		backoff *= 2
+		if backoff > w.cfg.MaxBackoff {
+			backoff = w.cfg.MaxBackoff
+		}
+	}
+}
+
+// Connected reports whether the endpoint is currently connected.
+func (w *NetworkWriter) Connected() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.conn != nil
+}
+
+// Dropped returns the number of entries dropped because the buffer was full.
+func (w *NetworkWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// LastError returns the error that disconnected the endpoint, or that the
+// last reconnection attempt failed with; nil while connected.
+func (w *NetworkWriter) LastError() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastErr
+}
+
+// Close stops reconnecting and closes the connection. Entries still
+// buffered are discarded.
+func (w *NetworkWriter) Close() error {
+	// Closing stop under mu orders it against disconnectedLocked starting
+	// a reconnection.
+	w.mu.Lock()
+	w.closeOnce.Do(func() { close(w.stop) })
+	w.mu.Unlock()
+	w.wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+var _ io.WriteCloser = (*NetworkWriter)(nil)
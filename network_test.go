@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"bufio"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestNetworkWriter_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	w, err := NewNetworkWriter(NetworkConfig{Addr: ln.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := w.Write([]byte(`{"message":"one"}` + "\n" + `{"message":"two"}`)); err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(conn)
+	for _, want := range []string{`{"message":"one"}`, `{"message":"two"}`} {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != want+"\n" {
+			t.Errorf("got %q, want %q", line, want)
+		}
+	}
+}
+
+func TestNetworkWriter_BuffersWhileDisconnected(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	w, err := NewNetworkWriter(NetworkConfig{
+		Addr: addr, BufferSize: 40, MinBackoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if w.Connected() || w.LastError() == nil {
+		t.Fatal("expected the writer to start disconnected")
+	}
+	for _, line := range []string{`{"n":"first-entry"}`, `{"n":"second"}`, `{"n":"third"}`} {
+		if _, err := w.Write([]byte(line + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if w.Dropped() != 1 {
+		t.Errorf("Dropped() = %d, want 1", w.Dropped())
+	}
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("cannot listen on %s again: %v", addr, err)
+	}
+	defer ln.Close()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+	for _, want := range []string{`{"n":"second"}`, `{"n":"third"}`} {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != want+"\n" {
+			t.Errorf("got %q, want %q", line, want)
+		}
+	}
+	if !w.Connected() {
+		t.Error("expected the writer to be connected after flushing the buffer")
+	}
+}
+
+func TestNetworkWriter_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	w, err := NewNetworkWriter(NetworkConfig{Network: "udp", Addr: pc.LocalAddr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if _, err := w.Write([]byte("{\"a\":1}\n{\"b\":2}\n")); err != nil {
+		t.Fatal(err)
+	}
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1024)
+	for _, want := range []string{"{\"a\":1}\n", "{\"b\":2}\n"} {
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(buf[:n]) != want {
+			t.Errorf("got datagram %q, want %q", buf[:n], want)
+		}
+	}
+}
+
+func TestNewNetworkWriter_Validation(t *testing.T) {
+	for _, cfg := range []NetworkConfig{
+		{Network: "sctp", Addr: "localhost:1"},
+		{Addr: "no-port"},
+		{Network: "udp", Addr: "localhost:1", TLS: &tls.Config{}},
+	} {
+		if _, err := NewNetworkWriter(cfg); err == nil {
+			t.Errorf("NewNetworkWriter(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// InfoOnce writes msg the first time it is called with key, and InfoEvery
+// at most once per interval for key, however often they are called; the
+// other levels have the same helpers. They are for entries inside tight loops
+// and retry paths, keyed by a constant such as "kafka-dial":
+//
+//	for {
+//		if err := dial(); err != nil {
+//			logger.ErrorEvery("kafka-dial", time.Minute, "kafka unreachable: "+err.Error())
+//			continue
+//		}
+//	}
+//
+// A call at a disabled level doesn't use up its key. A Logger and its
+// children share keys, and every key is remembered for the life of the
+// Logger, so don't build keys from request data.
+func InfoOnce(key, msg string) {
+	if std.shouldLog(LevelInfo) && std.firstTime(key) {
+		std.logPlain(nil, LevelInfo, msg)
+	}
+}
+func InfoEvery(key string, interval time.Duration, msg string) {
+	if std.shouldLog(LevelInfo) && std.due(key, interval) {
+		std.logPlain(nil, LevelInfo, msg)
+	}
+}
+func WarningOnce(key, msg string) {
+	if std.shouldLog(LevelWarn) && std.firstTime(key) {
+		std.logPlain(nil, LevelWarn, msg)
+	}
+}
+func WarningEvery(key string, interval time.Duration, msg string) {
+	if std.shouldLog(LevelWarn) && std.due(key, interval) {
+		std.logPlain(nil, LevelWarn, msg)
+	}
+}
+func ErrorOnce(key, msg string) {
+	if std.shouldLog(LevelError) && std.firstTime(key) {
+		std.logPlain(nil, LevelError, msg)
+	}
+}
+func ErrorEvery(key string, interval time.Duration, msg string) {
+	if std.shouldLog(LevelError) && std.due(key, interval) {
+		std.logPlain(nil, LevelError, msg)
+	}
+}
+func DebugOnce(key, msg string) {
+	if std.shouldLog(LevelDebug) && std.firstTime(key) {
+		std.logPlain(nil, LevelDebug, msg)
+	}
+}
+func DebugEvery(key string, interval time.Duration, msg string) {
+	if std.shouldLog(LevelDebug) && std.due(key, interval) {
+		std.logPlain(nil, LevelDebug, msg)
+	}
+}
+func TraceOnce(key, msg string) {
+	if std.shouldLog(LevelTrace) && std.firstTime(key) {
+		std.logPlain(nil, LevelTrace, msg)
+	}
+}
+func TraceEvery(key string, interval time.Duration, msg string) {
+	if std.shouldLog(LevelTrace) && std.due(key, interval) {
+		std.logPlain(nil, LevelTrace, msg)
+	}
+}
+
+func (l *Logger) InfoOnce(key, msg string) {
+	if l.shouldLog(LevelInfo) && l.firstTime(key) {
+		l.logPlain(nil, LevelInfo, msg)
+	}
+}
+func (l *Logger) InfoEvery(key string, interval time.Duration, msg string) {
+	if l.shouldLog(LevelInfo) && l.due(key, interval) {
+		l.logPlain(nil, LevelInfo, msg)
+	}
+}
+func (l *Logger) WarningOnce(key, msg string) {
+	if l.shouldLog(LevelWarn) && l.firstTime(key) {
+		l.logPlain(nil, LevelWarn, msg)
+	}
+}
+func (l *Logger) WarningEvery(key string, interval time.Duration, msg string) {
+	if l.shouldLog(LevelWarn) && l.due(key, interval) {
+		l.logPlain(nil, LevelWarn, msg)
+	}
+}
+func (l *Logger) ErrorOnce(key, msg string) {
+	if l.shouldLog(LevelError) && l.firstTime(key) {
+		l.logPlain(nil, LevelError, msg)
+	}
+}
+func (l *Logger) ErrorEvery(key string, interval time.Duration, msg string) {
+	if l.shouldLog(LevelError) && l.due(key, interval) {
+		l.logPlain(nil, LevelError, msg)
+	}
+}
+func (l *Logger) DebugOnce(key, msg string) {
+	if l.shouldLog(LevelDebug) && l.firstTime(key) {
+		l.logPlain(nil, LevelDebug, msg)
+	}
+}
+func (l *Logger) DebugEvery(key string, interval time.Duration, msg string) {
+	if l.shouldLog(LevelDebug) && l.due(key, interval) {
+		l.logPlain(nil, LevelDebug, msg)
+	}
+}
+func (l *Logger) TraceOnce(key, msg string) {
+	if l.shouldLog(LevelTrace) && l.firstTime(key) {
+		l.logPlain(nil, LevelTrace, msg)
+	}
+}
+func (l *Logger) TraceEvery(key string, interval time.Duration, msg string) {
+	if l.shouldLog(LevelTrace) && l.due(key, interval) {
+		l.logPlain(nil, LevelTrace, msg)
+	}
+}
+
+// logKeys holds the keys of the Once and Every helpers of a root Logger.
+type logKeys struct {
+	once  sync.Map // key -> struct{}
+	every sync.Map // key -> *everyKey
+}
+
+type everyKey struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+// firstTime reports whether key is new, recording it.
+func (l *Logger) firstTime(key string) bool {
+	_, seen := l.root().keys.once.LoadOrStore(key, struct{}{})
+	return !seen
+}
+
+// due reports whether interval has passed since key was last due, recording
+// the call when it has.
+func (l *Logger) due(key string, interval time.Duration) bool {
+	v, _ := l.root().keys.every.LoadOrStore(key, &everyKey{})
+	k := v.(*everyKey)
+	now := time.Now()
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if !k.last.IsZero() && now.Sub(k.last) < interval {
+		return false
+	}
+	k.last = now
+	return true
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOnce_WritesFirstCallPerKey(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithLevel("info"), WithOutputs(&out))
+	child := l.WithField("component", "cache")
+	for i := 0; i < 5; i++ {
+		l.InfoOnce("warmup", "cache warming up")
+		child.InfoOnce("warmup", "cache warming up")
+		l.DebugOnce("debug-key", "not enabled")
+	}
+	if got := strings.Count(out.String(), "cache warming up"); got != 1 {
+		t.Errorf("expected one entry for the key across the logger and its child, got %d", got)
+	}
+
+	l.SetLevel("debug")
+	l.DebugOnce("debug-key", "enabled now")
+	if !strings.Contains(out.String(), "enabled now") {
+		t.Error("expected a disabled call not to use up its key")
+	}
+}
+
+func TestEvery_WritesOncePerInterval(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithOutputs(&out))
+	for i := 0; i < 100; i++ {
+		l.ErrorEvery("dial", 30*time.Millisecond, "kafka unreachable")
+	}
+	if got := strings.Count(out.String(), "kafka unreachable"); got != 1 {
+		t.Fatalf("expected one entry in the interval, got %d", got)
+	}
+	if !strings.Contains(out.String(), "once_test.go:") {
+		t.Errorf("expected the caller's file, got %q", out.String())
+	}
+	time.Sleep(40 * time.Millisecond)
+	l.ErrorEvery("dial", 30*time.Millisecond, "kafka unreachable")
+	l.ErrorEvery("other", 30*time.Millisecond, "kafka unreachable")
+	if got := strings.Count(out.String(), "kafka unreachable"); got != 3 {
+		t.Errorf("expected another entry after the interval and one for the new key, got %d", got)
+	}
+}
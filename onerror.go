@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"sync/atomic"
+)
+
+var sinkErrorHandler atomic.Pointer[func(sink string, err error, entry []byte)]
+
+// OnError registers fn to be called whenever an output fails to write an
+// entry, e.g. when Kafka is down or the disk is full, so applications can
+// count delivery failures or alert an operator. sink is the output's name
+// ("file", "stdout", "kafka", "custom-0", ...) and entry is a copy of the
+// encoded entry. Outputs that batch report a failed background delivery on
+// their next write, with that write's entry. nil removes the handler.
+//
+// fn runs on the goroutine writing the entry. It must not log through the
+// failing output, which would fail again.
+func OnError(fn func(sink string, err error, entry []byte)) {
+	if fn == nil {
+		sinkErrorHandler.Store(nil)
+		return
+	}
+	sinkErrorHandler.Store(&fn)
+}
+
+// reportSinkError passes a failed write to the OnError handler.
+func reportSinkError(sink string, err error, entry []byte) {
+	fn := sinkErrorHandler.Load()
+	if fn == nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			diagnose("onerror", nil, "OnError handler panicked: %v", r)
+		}
+	}()
+	(*fn)(sink, err, append([]byte(nil), entry...))
+}
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestOnError_ReportsFailedWrites(t *testing.T) {
+	type failure struct {
+		sink, err, entry string
+	}
+	var (
+		mu       sync.Mutex
+		failures []failure
+	)
+	OnError(func(sink string, err error, entry []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		failures = append(failures, failure{sink, err.Error(), string(entry)})
+	})
+	defer OnError(nil)
+
+	var ok syncBuffer
+	l := New(WithFormat("json"), WithOutputs(&ok, failingWriter{}))
+	l.Error("payment failed")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(failures) != 1 {
+		t.Fatalf("expected one failure, got %+v", failures)
+	}
+	f := failures[0]
+	if f.sink != "custom-1" || f.err != "loki unreachable" || !strings.Contains(f.entry, "payment failed") {
+		t.Errorf("unexpected failure %+v", f)
+	}
+	if !strings.Contains(ok.String(), "payment failed") {
+		t.Error("expected the healthy output to receive the entry")
+	}
+}
+
+func TestOnError_HandlerPanicIsContained(t *testing.T) {
+	OnError(func(string, error, []byte) { panic("boom") })
+	defer OnError(nil)
+	l := New(WithOutputs(failingWriter{}))
+	l.Info("still fine")
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Operation is a lightweight span: Begin logs a start entry and End logs a
+// matching end entry carrying the same operation ID, the duration and the
+// outcome.
+type Operation struct {
+	ctx     context.Context
+	name    string
+	id      string
+	fields  map[string]interface{}
+	started time.Time
+}
+
+// Begin starts the named operation and logs its start entry. The optional
+// field maps are merged and attached to both the start and the end entries.
+func Begin(ctx context.Context, name string, fields ...map[string]interface{}) *Operation {
+	op := &Operation{
+		ctx:     ctx,
+		name:    name,
+		id:      newID(),
+		fields:  map[string]interface{}{},
+		started: time.Now(),
+	}
+	for _, f := range fields {
+		for k, v := range f {
+			op.fields[k] = v
+		}
+	}
+
+	entry := op.entry()
+	entry["message"] = name + " started"
+	entry["status"] = "started"
+	logWithMap(LevelInfo, ctx, entry)
+	return op
+}
+
+// ID returns the operation ID shared by the start and end entries.
+func (op *Operation) ID() string { return op.id }
+
+// End logs the end entry. A nil err is reported as success at INFO level,
+// anything else as failure at ERROR level.
+func (op *Operation) End(err error) {
+	duration := time.Since(op.started)
+
+	entry := op.entry()
+	entry["duration_ms"] = duration.Milliseconds()
+
+	level := LevelInfo
+	if err != nil {
+		level = LevelError
+		entry["message"] = op.name + " failed"
+		entry["status"] = "failure"
+		entry["error"] = err.Error()
+	} else {
+		entry["message"] = op.name + " succeeded"
+		entry["status"] = "success"
+	}
+	logWithMap(level, op.ctx, entry)
+}
+
+func (op *Operation) entry() map[string]interface{} {
+	entry := make(map[string]interface{}, len(op.fields)+6)
+	for k, v := range op.fields {
+		entry[k] = v
+	}
+	entry["operation"] = op.name
+	entry["operation_id"] = op.id
+	return entry
+}
+
+func newID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestOperation_BeginEndSuccess(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	op := Begin(context.Background(), "charge-card", map[string]interface{}{"amount": 42})
+	op.End(nil)
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected start and end entries, got %d", len(entries))
+	}
+	if entries[0]["operation_id"] != entries[1]["operation_id"] || entries[0]["operation_id"] != op.ID() {
+		t.Errorf("start and end entries must share the operation id")
+	}
+	if entries[1]["status"] != "success" || entries[1]["level"] != "INFO" {
+		t.Errorf("unexpected end entry: %v", entries[1])
+	}
+	if entries[1]["amount"] != float64(42) {
+		t.Errorf("expected begin fields on the end entry, got %v", entries[1]["amount"])
+	}
+	if _, ok := entries[1]["duration_ms"]; !ok {
+		t.Errorf("expected duration_ms on the end entry")
+	}
+}
+
+func TestOperation_EndFailure(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	Begin(context.Background(), "charge-card").End(errors.New("card declined"))
+
+	entries := decodeLogLines(t, &buf)
+	end := entries[len(entries)-1]
+	if end["status"] != "failure" || end["level"] != "ERROR" || end["error"] != "card declined" {
+		t.Errorf("unexpected failure entry: %v", end)
+	}
+}
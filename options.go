@@ -0,0 +1,185 @@
+package logger
+
+import "io"
+
+// Option configures InitWithOptions and New.
+type Option func(*options)
+
+type options struct {
+	level       string
+	namedLevels map[string]string
+	format      string
+	gcpProject  string
+	service     string
+	environment string
+	file        *FileConfig
+	stdout      bool
+
+	kafka    *KafkaConfig
+	async    *AsyncConfig
+	sampling *SamplingConfig
+	throttle *ThrottleConfig
+	redact   *RedactConfig
+
+	scrubbers []Scrubber
+
+	maxEntryBytes int
+	caller        bool
+
+	outputs []output
+}
+
+// output is a writer passed to WithOutputs or WithOwnedOutputs.
+type output struct {
+	w     io.Writer
+	owned bool
+}
+
+// WithLevel sets the minimum level: "trace", "debug", "info", "warn" or
+// "error".
+func WithLevel(level string) Option {
+	return func(o *options) { o.level = level }
+}
+
+// WithNamedLevel overrides the minimum level of the logger returned by
+// Named(name) and of its descendants.
+func WithNamedLevel(name, level string) Option {
+	return func(o *options) {
+		if o.namedLevels == nil {
+			o.namedLevels = make(map[string]string)
+		}
+		o.namedLevels[name] = level
+	}
+}
+
+// WithFormat selects "json", "logfmt", "gcp" or "text" output. The gcp format
+// is JSON in the shape the Cloud Logging agents on GKE and Cloud Run expect.
+func WithFormat(format string) Option {
+	return func(o *options) { o.format = format }
+}
+
+// WithGCPProject sets the Google Cloud project used to qualify trace IDs in
+// the gcp format. It defaults to $GOOGLE_CLOUD_PROJECT.
+func WithGCPProject(project string) Option {
+	return func(o *options) { o.gcpProject = project }
+}
+
+// WithService sets the service and environment attached to structured entries.
+func WithService(service, environment string) Option {
+	return func(o *options) {
+		o.service = service
+		o.environment = environment
+	}
+}
+
+// WithFile writes to a rotating log file at path, or app.log when path is
+// empty, with the default rotation settings.
+func WithFile(path string) Option {
+	return WithFileConfig(FileConfig{Path: path})
+}
+
+// WithFileConfig writes to a rotating log file with custom rotation settings.
+// Zero fields take the defaults documented on FileConfig.
+func WithFileConfig(fc FileConfig) Option {
+	fc = fc.withDefaults()
+	return func(o *options) { o.file = &fc }
+}
+
+// WithStdout writes to standard output.
+func WithStdout() Option {
+	return func(o *options) { o.stdout = true }
+}
+
+// WithKafka publishes entries to topic on the given brokers.
+func WithKafka(brokers []string, topic string) Option {
+	return WithKafkaConfig(KafkaConfig{Brokers: brokers, Topic: topic})
+}
+
+// WithKafkaConfig publishes entries to Kafka with custom producer settings.
+// Zero fields take the defaults documented on KafkaConfig.
+func WithKafkaConfig(kc KafkaConfig) Option {
+	return func(o *options) {
+		if kc.Topic == "" {
+			o.kafka = nil
+			return
+		}
+		o.kafka = &kc
+	}
+}
+
+// WithAsync makes log calls queue entries for background workers instead of
+// writing to the outputs themselves, so slow outputs (file fsync, Kafka,
+// HTTP) don't add to request latency. Zero fields take the defaults
+// documented on AsyncConfig. Flush waits for the queue to drain and Close
+// drains it before closing the outputs.
+func WithAsync(ac AsyncConfig) Option {
+	ac = ac.withDefaults()
+	return func(o *options) { o.async = &ac }
+}
+
+// WithSampling caps how often the same entry is written per level; see
+// SamplingConfig.
+func WithSampling(sc SamplingConfig) Option {
+	return func(o *options) { o.sampling = &sc }
+}
+
+// WithThrottle suppresses repeats of the same entry beyond a limit per
+// interval and summarizes them; see ThrottleConfig.
+func WithThrottle(tc ThrottleConfig) Option {
+	return func(o *options) { o.throttle = &tc }
+}
+
+// WithRedaction masks sensitive fields and values before entries reach any
+// output; see RedactConfig. An invalid pattern masks every entry, so check
+// the configuration with Config.Validate.
+func WithRedaction(rc RedactConfig) Option {
+	return func(o *options) { o.redact = &rc }
+}
+
+// WithScrubbers adds functions that rewrite every entry's fields before
+// redaction and encoding, in order. Nil scrubbers are skipped.
+func WithScrubbers(s ...Scrubber) Option {
+	return func(o *options) {
+		for _, fn := range s {
+			if fn != nil {
+				o.scrubbers = append(o.scrubbers, fn)
+			}
+		}
+	}
+}
+
+// WithMaxEntrySize limits entries to about n encoded bytes, e.g. below
+// Kafka's max.message.bytes. Larger entries have their largest values
+// shortened and carry truncated=true rather than being rejected by an
+// output. 0 means no limit.
+func WithMaxEntrySize(n int) Option {
+	return func(o *options) { o.maxEntryBytes = n }
+}
+
+// WithCaller adds caller (file:line) and function fields to structured
+// entries, naming the code that called the logger. Text entries already carry
+// the file and line in their prefix.
+func WithCaller() Option {
+	return func(o *options) { o.caller = true }
+}
+
+// WithOutputs adds writers that receive every entry. Nil writers are skipped.
+// They stay open when the logger is closed; see WithOwnedOutputs.
+func WithOutputs(w ...io.Writer) Option {
+	return func(o *options) {
+		for _, w := range w {
+			o.outputs = append(o.outputs, output{w: w})
+		}
+	}
+}
+
+// WithOwnedOutputs is WithOutputs for writers the logger takes over: Close
+// and Fatal close them, e.g. to send a network writer's last batch and stop
+// its goroutines.
+func WithOwnedOutputs(w ...io.Writer) Option {
+	return func(o *options) {
+		for _, w := range w {
+			o.outputs = append(o.outputs, output{w: w, owned: true})
+		}
+	}
+}
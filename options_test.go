@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestInitWithOptions(t *testing.T) {
+	var buf bytes.Buffer
+	InitWithOptions(
+		WithLevel("warn"),
+		WithFormat("json"),
+		WithService("billing", "staging"),
+		WithOutputs(&buf),
+	)
+	defer SetMetadata("", "")
+
+	Info("filtered")
+	WarningfMap(nil, map[string]interface{}{"message": "kept"})
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected only the warning to be logged, got %d entries", len(entries))
+	}
+	if entries[0]["service"] != "billing" || entries[0]["environment"] != "staging" {
+		t.Errorf("expected service metadata from options, got %v", entries[0])
+	}
+}
+
+func TestInit_SetsServiceMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	Init("debug", "json", "file-service", "dev", false, false, false, nil, nil, &buf)
+	defer SetMetadata("", "")
+
+	InfofMap(nil, nil)
+
+	if e := decodeLogLines(t, &buf)[0]; e["service"] != "file-service" || e["environment"] != "dev" {
+		t.Errorf("expected Init's service and environment to be used, got %v", e)
+	}
+}
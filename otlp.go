@@ -0,0 +1,379 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OTLPConfig configures the OpenTelemetry log exporter.
+type OTLPConfig struct {
+	// Endpoint is the collector's address: "collector:4317" for gRPC or
+	// "http://collector:4318" for HTTP, to which /v1/logs is appended.
+	Endpoint string
+	// Protocol is "grpc" (the default) or "http/protobuf".
+	Protocol string
+	// Insecure disables TLS; TLS customizes it otherwise.
+	Insecure bool
+	TLS      *tls.Config
+	// Headers are sent with every export, e.g. for API keys.
+	Headers map[string]string
+	// ResourceAttributes are added to the resource of every entry, next to
+	// service.name and deployment.environment.name taken from the entry.
+	ResourceAttributes map[string]string
+	// BatchSize and BatchInterval control batching; defaults are 512 entries
+	// and 1 second.
+	BatchSize     int
+	BatchInterval time.Duration
+	// Timeout bounds each export; defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+const (
+	otlpGRPCPath  = "/opentelemetry.proto.collector.logs.v1.LogsService/Export"
+	otlpHTTPPath  = "/v1/logs"
+	otlpScopeName = "github.com/paaavkata/go-logger"
+)
+
+// OTLPWriter exports entries to an OpenTelemetry collector as OTLP log
+// records: the level becomes the severity, the message the body, trace_id
+// and span_id the trace context and the other fields attributes. Writes do
+// not block on the network; export errors are reported by LastError and by
+// the next Write.
+type OTLPWriter struct {
+	cfg      OTLPConfig
+	grpc     bool
+	endpoint string
+	client   *http.Client
+	batch    *batcher[otlpRecord]
+}
+
+type otlpRecord struct {
+	resource string // encoded resource, grouping records into ResourceLogs
+	record   []byte // encoded LogRecord
+}
+
+// NewOTLPWriter validates cfg and returns a sink that can be passed to Init
+// as an additional output.
+func NewOTLPWriter(cfg OTLPConfig) (*OTLPWriter, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("otlp: Endpoint is required")
+	}
+	w := &OTLPWriter{cfg: cfg}
+	switch cfg.Protocol {
+	case "", "grpc":
+		w.grpc = true
+	case "http/protobuf":
+	default:
+		return nil, fmt.Errorf("otlp: unknown protocol %q (want grpc or http/protobuf)", cfg.Protocol)
+	}
+
+	raw := cfg.Endpoint
+	if !strings.Contains(raw, "://") {
+		scheme := "https://"
+		if cfg.Insecure {
+			scheme = "http://"
+		}
+		raw = scheme + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("otlp: invalid endpoint %q", cfg.Endpoint)
+	}
+	if w.grpc {
+		u.Path = otlpGRPCPath
+	} else if !strings.HasSuffix(u.Path, otlpHTTPPath) {
+		u.Path = strings.TrimSuffix(u.Path, "/") + otlpHTTPPath
+	}
+	w.endpoint = u.String()
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	transport := &http.Transport{TLSClientConfig: cfg.TLS, ForceAttemptHTTP2: true}
+	if w.grpc {
+		// gRPC requires HTTP/2, over TLS or in cleartext (h2c).
+		transport.Protocols = new(http.Protocols)
+		if u.Scheme == "http" {
+			transport.Protocols.SetUnencryptedHTTP2(true)
+		} else {
+			transport.Protocols.SetHTTP2(true)
+		}
+	}
+	w.client = &http.Client{Timeout: timeout, Transport: transport}
+
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = 512
+	}
+	interval := cfg.BatchInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	w.batch = newBatcher(batchSize, interval, w.export)
+	return w, nil
+}
+
+// Write queues each line of p as one log record.
+func (w *OTLPWriter) Write(p []byte) (int, error) {
+	now := time.Now()
+	var err error
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if aerr := w.batch.add(w.record(line, now)); aerr != nil && err == nil {
+			err = aerr
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("otlp: %w", err)
+	}
+	return len(p), nil
+}
+
+// record encodes line as a LogRecord together with its resource.
+func (w *OTLPWriter) record(line []byte, observed time.Time) otlpRecord {
+	var fields map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if len(line) < 2 || line[0] != '{' || dec.Decode(&fields) != nil {
+		level, _ := syslogEntryMeta(line)
+		fields = map[string]interface{}{"level": level, "message": string(line)}
+	}
+
+	resource := make(map[string]interface{}, len(w.cfg.ResourceAttributes)+2)
+	for k, v := range w.cfg.ResourceAttributes {
+		resource[k] = v
+	}
+	if v, _, ok := takeFirst(fields, []string{"service"}); ok && fmt.Sprint(v) != "" {
+		resource["service.name"] = fmt.Sprint(v)
+	}
+	if v, _, ok := takeFirst(fields, []string{"environment"}); ok && fmt.Sprint(v) != "" {
+		resource["deployment.environment.name"] = fmt.Sprint(v)
+	}
+
+	var rec []byte
+	at := observed
+	if v, _, ok := takeFirst(fields, passthroughTimestampKeys); ok {
+		if t, ok := parsePassthroughTime(v); ok {
+			at = t
+		}
+	}
+	rec = appendProtoFixed64(rec, 1, uint64(at.UnixNano()))
+	if v, _, ok := takeFirst(fields, []string{"level", "severity"}); ok {
+		level := logLevel(fmt.Sprint(v))
+		rec = appendProtoVarint(rec, 2, uint64(SeverityNumber(level)))
+		rec = appendProtoString(rec, 3, string(level))
+	}
+	// severity_number duplicates the record's severity.
+	delete(fields, "severity_number")
+	if v, _, ok := takeFirst(fields, passthroughMessageKeys); ok {
+		rec = appendProtoBytes(rec, 5, otlpAnyValue(v))
+	}
+	for _, id := range []struct {
+		key   string
+		field int
+		size  int
+	}{{"trace_id", 9, 16}, {"span_id", 10, 8}} {
+		if s, ok := fields[id.key].(string); ok {
+			if b, err := hex.DecodeString(s); err == nil && len(b) == id.size {
+				delete(fields, id.key)
+				rec = appendProtoBytes(rec, id.field, b)
+			}
+		}
+	}
+	rec = appendOTLPAttributes(rec, 6, fields)
+	rec = appendProtoFixed64(rec, 11, uint64(observed.UnixNano()))
+
+	return otlpRecord{resource: string(appendOTLPAttributes(nil, 1, resource)), record: rec}
+}
+
+// export sends records in one ExportLogsServiceRequest.
+func (w *OTLPWriter) export(records []otlpRecord) error {
+	var scope []byte
+	scope = appendProtoString(scope, 1, otlpScopeName)
+
+	groups := map[string][][]byte{}
+	var order []string
+	for _, r := range records {
+		if _, ok := groups[r.resource]; !ok {
+			order = append(order, r.resource)
+		}
+		groups[r.resource] = append(groups[r.resource], r.record)
+	}
+
+	var req []byte
+	for _, resource := range order {
+		var scopeLogs []byte
+		scopeLogs = appendProtoBytes(scopeLogs, 1, scope)
+		for _, rec := range groups[resource] {
+			scopeLogs = appendProtoBytes(scopeLogs, 2, rec)
+		}
+		var rl []byte
+		rl = appendProtoBytes(rl, 1, []byte(resource))
+		rl = appendProtoBytes(rl, 2, scopeLogs)
+		req = appendProtoBytes(req, 1, rl)
+	}
+
+	if w.grpc {
+		return w.exportGRPC(req)
+	}
+	return w.exportHTTP(req)
+}
+
+func (w *OTLPWriter) newRequest(body []byte, contentType string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodPost, w.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range w.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
+
+func (w *OTLPWriter) exportHTTP(msg []byte) error {
+	req, err := w.newRequest(msg, "application/x-protobuf")
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("export rejected: %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (w *OTLPWriter) exportGRPC(msg []byte) error {
+	// Length-prefixed message: uncompressed flag and big-endian length.
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	frame = append(frame, msg...)
+
+	req, err := w.newRequest(frame, "application/grpc")
+	if err != nil {
+		return err
+	}
+	req.Header.Set("TE", "trailers")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("export rejected: %s", resp.Status)
+	}
+
+	status, message := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		// Trailers-only responses carry the status in the headers.
+		status, message = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	if status != "0" {
+		msg, _ := url.PathUnescape(message)
+		return fmt.Errorf("export rejected: grpc status %s: %s", status, msg)
+	}
+	return nil
+}
+
+// Flush exports the pending entries.
+func (w *OTLPWriter) Flush() error {
+	if err := w.batch.flush(); err != nil {
+		return fmt.Errorf("otlp: %w", err)
+	}
+	return nil
+}
+
+// LastError returns the most recent asynchronous export error.
+func (w *OTLPWriter) LastError() error {
+	return w.batch.lastError()
+}
+
+// Close exports the pending entries and stops the background exports.
+func (w *OTLPWriter) Close() error {
+	err := w.batch.close()
+	w.client.CloseIdleConnections()
+	if err != nil {
+		return fmt.Errorf("otlp: %w", err)
+	}
+	return nil
+}
+
+var _ io.WriteCloser = (*OTLPWriter)(nil)
+
+// appendOTLPAttributes appends fields as repeated KeyValue in sorted order.
+func appendOTLPAttributes(b []byte, field int, fields map[string]interface{}) []byte {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b = appendProtoBytes(b, field, otlpKeyValue(k, fields[k]))
+	}
+	return b
+}
+
+func otlpKeyValue(k string, v interface{}) []byte {
+	var kv []byte
+	kv = appendProtoString(kv, 1, k)
+	return appendProtoBytes(kv, 2, otlpAnyValue(v))
+}
+
+// otlpAnyValue encodes v as an AnyValue.
+func otlpAnyValue(v interface{}) []byte {
+	var b []byte
+	switch t := v.(type) {
+	case nil:
+		return b
+	case string:
+		return appendProtoBytes(b, 1, []byte(t))
+	case bool:
+		b = appendProtoTag(b, 2, protoVarint)
+		if t {
+			return append(b, 1)
+		}
+		return append(b, 0)
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			b = appendProtoTag(b, 3, protoVarint)
+			return binary.AppendUvarint(b, uint64(i))
+		}
+		if f, err := t.Float64(); err == nil {
+			return appendProtoDouble(b, 4, f)
+		}
+		return appendProtoBytes(b, 1, []byte(t.String()))
+	case float64:
+		return appendProtoDouble(b, 4, t)
+	case []interface{}:
+		var arr []byte
+		for _, e := range t {
+			arr = appendProtoBytes(arr, 1, otlpAnyValue(e))
+		}
+		return appendProtoBytes(b, 5, arr)
+	case map[string]interface{}:
+		return appendProtoBytes(b, 6, appendOTLPAttributes(nil, 1, t))
+	default:
+		return appendProtoBytes(b, 1, []byte(fmt.Sprint(t)))
+	}
+}
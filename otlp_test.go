@@ -0,0 +1,196 @@
+package logger
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decodeProto splits a protobuf message into its fields: uint64 for varint
+// and fixed64 fields, []byte for length-delimited ones.
+func decodeProto(t *testing.T, b []byte) map[int][]interface{} {
+	t.Helper()
+	fields := map[int][]interface{}{}
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			t.Fatalf("invalid tag in % x", b)
+		}
+		b = b[n:]
+		field := int(tag >> 3)
+		switch tag & 7 {
+		case protoVarint:
+			v, n := binary.Uvarint(b)
+			fields[field] = append(fields[field], v)
+			b = b[n:]
+		case protoFixed64:
+			fields[field] = append(fields[field], binary.LittleEndian.Uint64(b))
+			b = b[8:]
+		case protoBytes:
+			l, n := binary.Uvarint(b)
+			fields[field] = append(fields[field], b[n:n+int(l)])
+			b = b[n+int(l):]
+		default:
+			t.Fatalf("unexpected wire type %d", tag&7)
+		}
+	}
+	return fields
+}
+
+func protoMsg(t *testing.T, f map[int][]interface{}, field int) map[int][]interface{} {
+	t.Helper()
+	if len(f[field]) == 0 {
+		t.Fatalf("field %d missing in %v", field, f)
+	}
+	return decodeProto(t, f[field][0].([]byte))
+}
+
+// protoAttrs returns the string attributes of repeated KeyValue field.
+func protoAttrs(t *testing.T, f map[int][]interface{}, field int) map[string]string {
+	t.Helper()
+	attrs := map[string]string{}
+	for _, kv := range f[field] {
+		m := decodeProto(t, kv.([]byte))
+		v := decodeProto(t, m[2][0].([]byte))
+		switch {
+		case v[1] != nil:
+			attrs[string(m[1][0].([]byte))] = string(v[1][0].([]byte))
+		case v[3] != nil:
+			attrs[string(m[1][0].([]byte))] = "int"
+		}
+	}
+	return attrs
+}
+
+const otlpTestEntry = `{"timestamp":"2025-05-11T19:30:12Z","level":"ERROR","service":"billing","environment":"prod","message":"charge failed","trace_id":"0af7651916cd43dd8448eb211c80319c","span_id":"b7ad6b7169203331","order":"o-1","attempt":2}`
+
+func checkOTLPRequest(t *testing.T, body []byte) {
+	t.Helper()
+	req := decodeProto(t, body)
+	rl := protoMsg(t, req, 1)
+	resource := protoAttrs(t, protoMsg(t, rl, 1), 1)
+	if resource["service.name"] != "billing" || resource["deployment.environment.name"] != "prod" || resource["k8s.cluster.name"] != "eu-1" {
+		t.Errorf("unexpected resource %v", resource)
+	}
+	rec := protoMsg(t, protoMsg(t, rl, 2), 2)
+	if rec[1][0] != uint64(time.Date(2025, 5, 11, 19, 30, 12, 0, time.UTC).UnixNano()) {
+		t.Errorf("unexpected time %v", rec[1])
+	}
+	if rec[2][0] != uint64(17) || string(rec[3][0].([]byte)) != "ERROR" {
+		t.Errorf("unexpected severity %v %s", rec[2], rec[3])
+	}
+	if body := decodeProto(t, rec[5][0].([]byte)); string(body[1][0].([]byte)) != "charge failed" {
+		t.Errorf("unexpected body %v", body)
+	}
+	if hex.EncodeToString(rec[9][0].([]byte)) != "0af7651916cd43dd8448eb211c80319c" || hex.EncodeToString(rec[10][0].([]byte)) != "b7ad6b7169203331" {
+		t.Errorf("unexpected trace context %v %v", rec[9], rec[10])
+	}
+	attrs := protoAttrs(t, rec, 6)
+	if attrs["order"] != "o-1" || attrs["attempt"] != "int" || len(attrs) != 2 {
+		t.Errorf("unexpected attributes %v", attrs)
+	}
+}
+
+func TestOTLPWriter_HTTP(t *testing.T) {
+	got := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/logs" || r.Header.Get("Content-Type") != "application/x-protobuf" || r.Header.Get("Api-Key") != "k" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Header)
+		}
+		body, _ := io.ReadAll(r.Body)
+		got <- body
+	}))
+	defer srv.Close()
+
+	w, err := NewOTLPWriter(OTLPConfig{
+		Endpoint:           srv.URL,
+		Protocol:           "http/protobuf",
+		Headers:            map[string]string{"Api-Key": "k"},
+		ResourceAttributes: map[string]string{"k8s.cluster.name": "eu-1"},
+		BatchInterval:      time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte(otlpTestEntry + "\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	checkOTLPRequest(t, <-got)
+}
+
+func TestOTLPWriter_GRPC(t *testing.T) {
+	got := make(chan []byte, 1)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 || r.URL.Path != otlpGRPCPath || r.Header.Get("Content-Type") != "application/grpc" {
+			t.Errorf("unexpected request %s %s %v", r.Proto, r.URL.Path, r.Header)
+		}
+		frame, _ := io.ReadAll(r.Body)
+		if len(frame) < 5 || int(binary.BigEndian.Uint32(frame[1:5])) != len(frame)-5 {
+			t.Errorf("invalid gRPC frame % x", frame)
+		} else {
+			got <- frame[5:]
+		}
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status")
+		_, _ = w.Write([]byte{0, 0, 0, 0, 0})
+		w.Header().Set("Grpc-Status", "0")
+	}))
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	defer srv.Close()
+
+	w, err := NewOTLPWriter(OTLPConfig{
+		Endpoint:           strings.TrimPrefix(srv.URL, "http://"),
+		Insecure:           true,
+		ResourceAttributes: map[string]string{"k8s.cluster.name": "eu-1"},
+		BatchInterval:      time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte(otlpTestEntry + "\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	checkOTLPRequest(t, <-got)
+}
+
+func TestOTLPWriter_GRPCErrorStatus(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", "16")
+		w.Header().Set("Grpc-Message", "missing%20credentials")
+	}))
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	defer srv.Close()
+
+	w, err := NewOTLPWriter(OTLPConfig{Endpoint: srv.URL, BatchInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte(`{"message":"x"}` + "\n"))
+	if err := w.Flush(); err == nil || !strings.Contains(err.Error(), "missing credentials") {
+		t.Errorf("expected the gRPC status to be reported, got %v", err)
+	}
+}
+
+func TestNewOTLPWriter_Validation(t *testing.T) {
+	for _, cfg := range []OTLPConfig{
+		{},
+		{Endpoint: "collector:4317", Protocol: "http/json"},
+	} {
+		if _, err := NewOTLPWriter(cfg); err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+}
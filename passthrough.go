@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+var (
+	passthroughLevelKeys     = []string{"level", "severity", "lvl"}
+	passthroughMessageKeys   = []string{"message", "msg"}
+	passthroughTimestampKeys = []string{"timestamp", "time", "ts"}
+)
+
+// emitJSON logs a line that is itself a JSON log entry by merging its fields
+// into ours. It reports false when the line isn't a JSON object.
+func (w *levelWriter) emitJSON(line []byte) bool {
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) < 2 || trimmed[0] != '{' {
+		return false
+	}
+
+	var parsed map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.UseNumber()
+	if err := dec.Decode(&parsed); err != nil {
+		return false
+	}
+
+	fields := w.baseFields(len(parsed))
+	level := w.level
+	at := time.Now()
+
+	if v, key, ok := takeFirst(parsed, passthroughLevelKeys); ok {
+		if l, ok := parsePassthroughLevel(v); ok {
+			level = l
+		} else {
+			fields["source_"+key] = v
+		}
+	}
+	if v, _, ok := takeFirst(parsed, passthroughMessageKeys); ok {
+		fields["message"] = v
+	}
+	if v, key, ok := takeFirst(parsed, passthroughTimestampKeys); ok {
+		if t, ok := parsePassthroughTime(v); ok {
+			at = t
+		} else {
+			fields["source_"+key] = v
+		}
+	}
+
+	for k, v := range parsed {
+		switch k {
+		case "service", "environment":
+			// Don't let the embedded tool impersonate this service.
+			fields["source_"+k] = v
+		default:
+			fields[k] = v
+		}
+	}
+
+	logWithMapAt(level, w.ctx, fields, at)
+	return true
+}
+
+func takeFirst(m map[string]interface{}, keys []string) (interface{}, string, bool) {
+	for _, k := range keys {
+		if v, ok := m[k]; ok {
+			delete(m, k)
+			return v, k, true
+		}
+	}
+	return nil, "", false
+}
+
+func parsePassthroughLevel(v interface{}) (logLevel, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return "", false
+	}
+	switch strings.ToLower(s) {
+	case "trace":
+		return LevelTrace, true
+	case "debug":
+		return LevelDebug, true
+	case "info", "notice":
+		return LevelInfo, true
+	case "warn", "warning":
+		return LevelWarn, true
+	case "error", "err", "fatal", "panic", "critical", "crit":
+		// A dependency's fatal must not terminate this process.
+		return LevelError, true
+	}
+	return "", false
+}
+
+func parsePassthroughTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, time.RFC3339} {
+			if parsed, err := time.Parse(layout, t); err == nil {
+				return parsed, true
+			}
+		}
+	case json.Number:
+		if f, err := t.Float64(); err == nil {
+			sec := int64(f)
+			return time.Unix(sec, int64((f-float64(sec))*1e9)), true
+		}
+	}
+	return time.Time{}, false
+}
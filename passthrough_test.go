@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestWriter_ParseJSONPassthrough(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	w := NewWriter(LevelInfo, WriterOptions{ParseJSON: true, Fields: map[string]interface{}{"source": "sidecar"}})
+	fmt.Fprintln(w, `{"level":"warn","msg":"disk almost full","time":"2025-05-11T19:30:12Z","disk":"/dev/sda1","service":"proxy"}`)
+	fmt.Fprintln(w, `not json at all`)
+	_ = w.Close()
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	merged := entries[0]
+	if merged["level"] != "WARNING" || merged["message"] != "disk almost full" {
+		t.Errorf("expected level and message to be mapped, got %v", merged)
+	}
+	if merged["timestamp"] != "2025-05-11T19:30:12Z" {
+		t.Errorf("expected source timestamp to be kept, got %v", merged["timestamp"])
+	}
+	if merged["disk"] != "/dev/sda1" || merged["source"] != "sidecar" {
+		t.Errorf("expected fields to be merged, got %v", merged)
+	}
+	if merged["source_service"] != "proxy" {
+		t.Errorf("expected conflicting service to be preserved as source_service, got %v", merged)
+	}
+	if _, ok := merged["msg"]; ok {
+		t.Errorf("mapped keys must not be duplicated")
+	}
+
+	if entries[1]["message"] != "not json at all" || entries[1]["level"] != "INFO" {
+		t.Errorf("expected non-JSON line to be logged as-is, got %v", entries[1])
+	}
+}
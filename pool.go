@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Buffers and field maps larger than these are left to the garbage collector
+// rather than pooled, so one huge entry doesn't pin its memory.
+const (
+	maxPooledBufferSize = 64 << 10
+	maxPooledMapLen     = 64
+)
+
+// entryBuffer holds one encoded entry. Outputs must not retain the slice
+// passed to Write, as io.Writer requires, because the buffer is reused.
+type entryBuffer struct {
+	bytes.Buffer
+	keys []string // scratch space for writeJSON
+}
+
+var entryBufferPool = sync.Pool{New: func() interface{} {
+	return &entryBuffer{}
+}}
+
+func getEntryBuffer() *entryBuffer {
+	b := entryBufferPool.Get().(*entryBuffer)
+	b.Reset()
+	return b
+}
+
+func putEntryBuffer(b *entryBuffer) {
+	if b.Cap() <= maxPooledBufferSize {
+		entryBufferPool.Put(b)
+	}
+}
+
+// fieldMapPool holds the maps the logger allocates for its own entries.
+// Maps passed in by callers are never pooled.
+var fieldMapPool = sync.Pool{New: func() interface{} {
+	return make(map[string]interface{}, 16)
+}}
+
+func getFieldMap() map[string]interface{} {
+	return fieldMapPool.Get().(map[string]interface{})
+}
+
+func putFieldMap(m map[string]interface{}) {
+	if len(m) <= maxPooledMapLen {
+		clear(m)
+		fieldMapPool.Put(m)
+	}
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func benchmarkLogger(format string) *Logger {
+	return New(WithLevel("info"), WithFormat(format), WithService("bench", "test"), WithOutputs(io.Discard))
+}
+
+func BenchmarkInfo_JSON(b *testing.B) {
+	l := benchmarkLogger("json")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info("request handled")
+	}
+}
+
+func BenchmarkInfoMap_JSON(b *testing.B) {
+	l := benchmarkLogger("json")
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.InfofMap(ctx, map[string]interface{}{"message": "request handled", "status": 200, "path": "/v1/users"})
+	}
+}
+
+func BenchmarkInfoMap_Logfmt(b *testing.B) {
+	l := benchmarkLogger("logfmt")
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.InfofMap(ctx, map[string]interface{}{"message": "request handled", "status": 200, "path": "/v1/users"})
+	}
+}
+
+func BenchmarkDebug_Disabled(b *testing.B) {
+	l := benchmarkLogger("json")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Debug("not written")
+	}
+}
+
+func TestPooling_NoRetainedState(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithFormat("json"), WithOutputs(&out))
+	l.InfofMap(nil, map[string]interface{}{"message": "first", "secret": "s3cr3t"})
+	out.Reset()
+	for i := 0; i < 10; i++ {
+		l.Info("second")
+	}
+	if strings.Contains(out.String(), "s3cr3t") || strings.Contains(out.String(), "first") {
+		t.Errorf("pooled state leaked into a later entry: %s", out.String())
+	}
+}
+
+func TestLogPlain_Allocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector adds allocations")
+	}
+	l := benchmarkLogger("json")
+	allocs := testing.AllocsPerRun(100, func() { l.Info("request handled") })
+	// The timestamp string, the message copy and interface boxing remain.
+	if allocs > 6 {
+		t.Errorf("Info allocates %.0f times per call, want at most 6", allocs)
+	}
+}
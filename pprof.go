@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+)
+
+// WithProfileLabels runs fn with runtime/pprof labels for the handler and the
+// context's trace_id, so CPU profiles captured while fn runs can be matched to
+// its logs. Context-aware log calls made with the ctx passed to fn record the
+// same values as pprof_handler and trace_id fields.
+func WithProfileLabels(ctx context.Context, handler string, fn func(ctx context.Context)) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	labels := map[string]string{"handler": handler}
+	if traceID := ctx.Value("trace_id"); traceID != nil {
+		labels["trace_id"] = fmt.Sprint(traceID)
+	}
+
+	kv := make([]string, 0, len(labels)*2)
+	for k, v := range labels {
+		kv = append(kv, k, v)
+	}
+
+	ctx = context.WithValue(ctx, profileLabelsKey, labels)
+	pprof.Do(ctx, pprof.Labels(kv...), fn)
+}
+
+func addProfileLabelFields(ctx context.Context, fields map[string]interface{}) {
+	if ctx == nil {
+		return
+	}
+	labels, ok := ctx.Value(profileLabelsKey).(map[string]string)
+	if !ok {
+		return
+	}
+	fields["pprof_handler"] = labels["handler"]
+	if traceID, ok := labels["trace_id"]; ok {
+		if _, exists := fields["trace_id"]; !exists {
+			fields["trace_id"] = traceID
+		}
+	}
+}
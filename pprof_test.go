@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"testing"
+)
+
+func TestWithProfileLabels(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	ctx := context.WithValue(context.Background(), "trace_id", "trace-9")
+	WithProfileLabels(ctx, "GET /users", func(ctx context.Context) {
+		if v, ok := pprof.Label(ctx, "handler"); !ok || v != "GET /users" {
+			t.Errorf("expected pprof handler label, got %q", v)
+		}
+		if v, ok := pprof.Label(ctx, "trace_id"); !ok || v != "trace-9" {
+			t.Errorf("expected pprof trace_id label, got %q", v)
+		}
+		InfofMap(ctx, map[string]interface{}{"event": "profiled"})
+	})
+
+	entries := decodeLogLines(t, &buf)
+	if entries[0]["pprof_handler"] != "GET /users" || entries[0]["trace_id"] != "trace-9" {
+		t.Errorf("expected profile labels in entry, got %v", entries[0])
+	}
+}
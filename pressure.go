@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// QueueStats describes one of the logger's internal bounded queues.
+type QueueStats struct {
+	Name     string
+	Depth    int
+	Capacity int
+	Dropped  uint64
+}
+
+// Utilization is Depth/Capacity in the range [0, 1].
+func (q QueueStats) Utilization() float64 {
+	if q.Capacity <= 0 {
+		return 0
+	}
+	return float64(q.Depth) / float64(q.Capacity)
+}
+
+type queueSource interface {
+	queueStats() QueueStats
+}
+
+type pressureCallback struct {
+	threshold float64
+	fn        func(pressure float64, over bool)
+	over      atomic.Bool
+}
+
+var (
+	queuesMu sync.RWMutex
+	queues   []queueSource
+
+	pressureMu        sync.RWMutex
+	pressureCallbacks []*pressureCallback
+	hasPressureCbs    atomic.Bool
+)
+
+// Queues returns the depth of every internal queue, e.g. one per shadow sink.
+func Queues() []QueueStats {
+	queuesMu.RLock()
+	defer queuesMu.RUnlock()
+	out := make([]QueueStats, len(queues))
+	for i, q := range queues {
+		out[i] = q.queueStats()
+	}
+	return out
+}
+
+// Pressure returns the utilization of the fullest internal queue, from 0
+// (idle) to 1 (full and dropping). Applications can use it to shed their own
+// load or lower verbosity when log shipping falls behind.
+func Pressure() float64 {
+	queuesMu.RLock()
+	defer queuesMu.RUnlock()
+	var max float64
+	for _, q := range queues {
+		if u := q.queueStats().Utilization(); u > max {
+			max = u
+		}
+	}
+	return max
+}
+
+// OnPressure registers fn to be called, on its own goroutine, whenever
+// Pressure crosses threshold: with over=true when it rises to or above the
+// threshold and over=false when it falls back below.
+func OnPressure(threshold float64, fn func(pressure float64, over bool)) {
+	pressureMu.Lock()
+	defer pressureMu.Unlock()
+	pressureCallbacks = append(pressureCallbacks, &pressureCallback{threshold: threshold, fn: fn})
+	hasPressureCbs.Store(true)
+}
+
+// ClearPressureCallbacks removes every callback registered with OnPressure.
+func ClearPressureCallbacks() {
+	pressureMu.Lock()
+	defer pressureMu.Unlock()
+	pressureCallbacks = nil
+	hasPressureCbs.Store(false)
+}
+
+func registerQueue(q queueSource) {
+	queuesMu.Lock()
+	defer queuesMu.Unlock()
+	queues = append(queues, q)
+}
+
+func unregisterQueue(q queueSource) {
+	queuesMu.Lock()
+	defer queuesMu.Unlock()
+	for i, other := range queues {
+		if other == q {
+			queues = append(queues[:i:i], queues[i+1:]...)
+			return
+		}
+	}
+}
+
+// checkPressure runs the pressure callbacks whose threshold was crossed. It
+// is called by queues after every enqueue and dequeue.
+func checkPressure() {
+	if !hasPressureCbs.Load() {
+		return
+	}
+	p := Pressure()
+
+	pressureMu.RLock()
+	defer pressureMu.RUnlock()
+	for _, cb := range pressureCallbacks {
+		over := p >= cb.threshold
+		if cb.over.Swap(over) != over {
+			go cb.fn(p, over)
+		}
+	}
+}
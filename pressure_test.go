@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+// blockingWriter holds every write until release is closed.
+type blockingWriter struct{ release chan struct{} }
+
+func (b blockingWriter) Write(p []byte) (int, error) {
+	<-b.release
+	return len(p), nil
+}
+
+func TestPressure_ReportsQueueUtilizationAndCallbacks(t *testing.T) {
+	events := make(chan bool, 4)
+	OnPressure(0.5, func(p float64, over bool) { events <- over })
+	defer ClearPressureCallbacks()
+
+	release := make(chan struct{})
+	s := AddShadowSink("slow", blockingWriter{release})
+
+	for i := 0; i < shadowQueueSize; i++ {
+		_, _ = shadowSinks.Write([]byte("x\n"))
+	}
+
+	if p := Pressure(); p < 0.5 {
+		t.Errorf("expected high pressure with a stalled sink, got %v", p)
+	}
+	var found bool
+	for _, q := range Queues() {
+		if q.Name == "shadow:slow" {
+			found = true
+			if q.Capacity != shadowQueueSize || q.Depth == 0 {
+				t.Errorf("unexpected queue stats: %+v", q)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected the shadow sink queue to be reported")
+	}
+
+	select {
+	case over := <-events:
+		if !over {
+			t.Errorf("expected a rising pressure event first")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected a pressure callback")
+	}
+
+	close(release)
+	s.Remove()
+
+	if p := Pressure(); p != 0 {
+		t.Errorf("expected no pressure after the sink drained, got %v", p)
+	}
+	select {
+	case over := <-events:
+		if over {
+			t.Errorf("expected a falling pressure event")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected a falling pressure callback")
+	}
+}
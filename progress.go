@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Progress periodically logs the progress of a long-running operation such as
+// a batch job or a migration. Entries are emitted every interval and/or every
+// everyN processed items, and Done emits a final summary.
+type Progress struct {
+	ctx      context.Context
+	name     string
+	total    int64
+	everyN   int64
+	started  time.Time
+	count    int64
+	lastLogN int64
+
+	mu     sync.Mutex
+	stop   chan struct{}
+	done   chan struct{}
+	closed bool
+}
+
+// NewProgress starts a progress logger for the named operation. total may be
+// zero when the number of items is unknown, in which case no ETA is reported.
+// A zero interval disables time-based reporting and a zero everyN disables
+// count-based reporting.
+func NewProgress(ctx context.Context, name string, total int64, interval time.Duration, everyN int64) *Progress {
+	p := &Progress{
+		ctx:     ctx,
+		name:    name,
+		total:   total,
+		everyN:  everyN,
+		started: time.Now(),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	if interval > 0 {
+		go p.loop(interval)
+	} else {
+		close(p.done)
+	}
+	return p
+}
+
+func (p *Progress) loop(interval time.Duration) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.report("progress")
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// Add records n more processed items.
+func (p *Progress) Add(n int64) {
+	count := atomic.AddInt64(&p.count, n)
+	if p.everyN <= 0 {
+		return
+	}
+	last := atomic.LoadInt64(&p.lastLogN)
+	if count-last >= p.everyN && atomic.CompareAndSwapInt64(&p.lastLogN, last, count) {
+		p.report("progress")
+	}
+}
+
+// Done stops periodic reporting and emits the final summary entry.
+func (p *Progress) Done() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	close(p.stop)
+	<-p.done
+	p.report("completed")
+}
+
+func (p *Progress) report(status string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed && status != "completed" {
+		return
+	}
+
+	count := atomic.LoadInt64(&p.count)
+	elapsed := time.Since(p.started)
+
+	var rate float64
+	if elapsed > 0 {
+		rate = float64(count) / elapsed.Seconds()
+	}
+
+	fields := map[string]interface{}{
+		"message":      p.name + " " + status,
+		"operation":    p.name,
+		"status":       status,
+		"processed":    count,
+		"elapsed_ms":   elapsed.Milliseconds(),
+		"rate_per_sec": rate,
+	}
+	if p.total > 0 {
+		fields["total"] = p.total
+		fields["percent"] = float64(count) * 100 / float64(p.total)
+		if status != "completed" && rate > 0 && count < p.total {
+			eta := time.Duration(float64(p.total-count) / rate * float64(time.Second))
+			fields["eta"] = eta.Round(time.Second).String()
+		}
+	}
+
+	logWithMap(LevelInfo, p.ctx, fields)
+}
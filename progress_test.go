@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestProgress_EveryNAndSummary(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	p := NewProgress(context.Background(), "migrate-users", 10, 0, 5)
+	for i := 0; i < 10; i++ {
+		p.Add(1)
+	}
+	p.Done()
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 3 {
+		t.Fatalf("expected 2 progress entries and 1 summary, got %d", len(entries))
+	}
+
+	if entries[0]["processed"] != float64(5) {
+		t.Errorf("expected first progress entry at 5 items, got %v", entries[0]["processed"])
+	}
+	if _, ok := entries[0]["eta"]; !ok {
+		t.Errorf("expected progress entry to carry an eta")
+	}
+
+	summary := entries[2]
+	if summary["status"] != "completed" {
+		t.Errorf("expected final status=completed, got %v", summary["status"])
+	}
+	if summary["processed"] != float64(10) || summary["percent"] != float64(100) {
+		t.Errorf("unexpected summary counts: %v", summary)
+	}
+}
+
+func TestProgress_DoneIsIdempotent(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	p := NewProgress(context.Background(), "noop", 0, 0, 0)
+	p.Done()
+	p.Done()
+
+	if entries := decodeLogLines(t, &buf); len(entries) != 1 {
+		t.Fatalf("expected a single summary entry, got %d", len(entries))
+	}
+}
@@ -0,0 +1,84 @@
+package logger
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	promEntries = prometheus.NewDesc("logger_entries_total",
+		"Entries written, by level.", []string{"level"}, nil)
+	promSinkWrites = prometheus.NewDesc("logger_sink_writes_total",
+		"Entries delivered to each output.", []string{"sink"}, nil)
+	promSinkBytes = prometheus.NewDesc("logger_sink_bytes_total",
+		"Bytes delivered to each output.", []string{"sink"}, nil)
+	promSinkErrors = prometheus.NewDesc("logger_sink_errors_total",
+		"Failed writes to each output.", []string{"sink"}, nil)
+	promQueueDepth = prometheus.NewDesc("logger_queue_depth",
+		"Entries waiting in each internal queue, such as the async queue.", []string{"queue"}, nil)
+	promQueueCapacity = prometheus.NewDesc("logger_queue_capacity",
+		"Capacity of each internal queue.", []string{"queue"}, nil)
+	promDropped = prometheus.NewDesc("logger_dropped_entries_total",
+		"Entries dropped because an internal queue was full.", []string{"queue"}, nil)
+	promSampled = prometheus.NewDesc("logger_sampled_entries_total",
+		"Entries left out by sampling.", nil, nil)
+	promThrottled = prometheus.NewDesc("logger_throttled_entries_total",
+		"Repeated entries suppressed by throttling.", nil, nil)
+)
+
+// PrometheusCollector returns a collector for the package-level logger. See
+// Logger.PrometheusCollector.
+func PrometheusCollector() prometheus.Collector {
+	return std.PrometheusCollector()
+}
+
+// PrometheusCollector returns a collector exposing l's activity, so that a
+// service that starts erroring, or log shipping that stalls, can be alerted
+// on:
+//
+//	logger_entries_total{level}          entries written
+//	logger_sink_writes_total{sink}       entries delivered to each output
+//	logger_sink_bytes_total{sink}        bytes delivered to each output
+//	logger_sink_errors_total{sink}       failed writes to each output
+//	logger_queue_depth{queue}            entries waiting in internal queues
+//	logger_queue_capacity{queue}
+//	logger_dropped_entries_total{queue}  entries dropped by full queues
+//	logger_sampled_entries_total         entries left out by sampling
+//	logger_throttled_entries_total       repeats suppressed by throttling
+//
+// Register it once:
+//
+//	prometheus.MustRegister(logger.PrometheusCollector())
+//
+// Queues are process-wide, so loggers registered side by side report the
+// same queue metrics.
+func (l *Logger) PrometheusCollector() prometheus.Collector {
+	return &promCollector{l: l.root()}
+}
+
+type promCollector struct {
+	l *Logger
+}
+
+func (c *promCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{promEntries, promSinkWrites, promSinkBytes, promSinkErrors,
+		promQueueDepth, promQueueCapacity, promDropped, promSampled, promThrottled} {
+		ch <- d
+	}
+}
+
+func (c *promCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.l.Stats()
+	for level, n := range s.Entries {
+		ch <- prometheus.MustNewConstMetric(promEntries, prometheus.CounterValue, float64(n), level)
+	}
+	for _, sink := range s.Sinks {
+		ch <- prometheus.MustNewConstMetric(promSinkWrites, prometheus.CounterValue, float64(sink.Writes), sink.Name)
+		ch <- prometheus.MustNewConstMetric(promSinkBytes, prometheus.CounterValue, float64(sink.Bytes), sink.Name)
+		ch <- prometheus.MustNewConstMetric(promSinkErrors, prometheus.CounterValue, float64(sink.Errors), sink.Name)
+	}
+	for _, q := range s.Queues {
+		ch <- prometheus.MustNewConstMetric(promQueueDepth, prometheus.GaugeValue, float64(q.Depth), q.Name)
+		ch <- prometheus.MustNewConstMetric(promQueueCapacity, prometheus.GaugeValue, float64(q.Capacity), q.Name)
+		ch <- prometheus.MustNewConstMetric(promDropped, prometheus.CounterValue, float64(q.Dropped), q.Name)
+	}
+	ch <- prometheus.MustNewConstMetric(promSampled, prometheus.CounterValue, float64(s.Sampled))
+	ch <- prometheus.MustNewConstMetric(promThrottled, prometheus.CounterValue, float64(s.Throttled))
+}
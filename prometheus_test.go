@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPrometheusCollector(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithLevel("debug"), WithFormat("json"), WithOutputs(&out, failingWriter{}), WithSampling(SamplingConfig{
+		Levels: map[string]SamplingRule{"debug": {First: 1}},
+	}))
+	l.Info("one")
+	l.Info("two")
+	l.Error("three")
+	l.Debug("sampled")
+	l.Debug("sampled")
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(l.PrometheusCollector())
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]float64{}
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			key := f.GetName()
+			for _, lp := range m.GetLabel() {
+				key += "/" + lp.GetValue()
+			}
+			switch {
+			case m.GetCounter() != nil:
+				values[key] = m.GetCounter().GetValue()
+			case m.GetGauge() != nil:
+				values[key] = m.GetGauge().GetValue()
+			}
+		}
+	}
+	want := map[string]float64{
+		"logger_entries_total/INFO":         2,
+		"logger_entries_total/ERROR":        1,
+		"logger_entries_total/DEBUG":        1,
+		"logger_sink_writes_total/custom-0": 4,
+		"logger_sink_errors_total/custom-1": 4,
+		"logger_sink_bytes_total/custom-0":  float64(out.Len()),
+		"logger_sampled_entries_total":      1,
+		"logger_throttled_entries_total":    0,
+		"logger_sink_writes_total/custom-1": 0,
+	}
+	for k, v := range want {
+		if got, ok := values[k]; !ok || got != v {
+			t.Errorf("%s = %v (present %v), want %v", k, got, ok, v)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestProtoWire_Encoding(t *testing.T) {
+	var b []byte
+	b = appendProtoVarint(b, 2, 300)
+	b = appendProtoString(b, 3, "hi")
+	b = appendProtoVarint(b, 4, 0) // default values are omitted
+	b = appendProtoString(b, 5, "")
+	b = appendProtoFixed64(b, 1, 1)
+
+	want := []byte{
+		0x10, 0xac, 0x02, // field 2, varint 300
+		0x1a, 0x02, 'h', 'i', // field 3, "hi"
+		0x09, 1, 0, 0, 0, 0, 0, 0, 0, // field 1, fixed64 1
+	}
+	if !bytes.Equal(b, want) {
+		t.Errorf("got % x, want % x", b, want)
+	}
+}
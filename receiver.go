@@ -0,0 +1,248 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxForwardedLine caps a single entry received by a Receiver.
+const maxForwardedLine = 1 << 20
+
+// forwardHello is the first line a Forwarder sends on every connection.
+type forwardHello struct {
+	Process string `json:"source_process"`
+	PID     int    `json:"source_pid"`
+	Host    string `json:"source_host,omitempty"`
+}
+
+// Receiver accepts entries streamed by Forwarders in other local processes
+// and writes them into this process's outputs, tagged with the sending
+// process. It is the node-local fan-in end of the agent mode.
+type Receiver struct {
+	listener net.Listener
+	logger   *Logger
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// ReceiverOption configures a Receiver.
+type ReceiverOption func(*Receiver)
+
+// ReceiverLogger makes the Receiver write entries to l's outputs instead of
+// the package-level logger's.
+func ReceiverLogger(l *Logger) ReceiverOption {
+	return func(r *Receiver) { r.logger = l }
+}
+
+// NewReceiver listens on network and addr, typically "unix" and a socket path.
+// A stale socket file left by a previous run is removed. Call Serve to start
+// accepting connections.
+func NewReceiver(network, addr string, opts ...ReceiverOption) (*Receiver, error) {
+	if network == "unix" {
+		if conn, err := net.Dial("unix", addr); err == nil {
+			conn.Close()
+			return nil, errors.New("receiver: " + addr + " is already in use")
+		}
+		_ = os.Remove(addr)
+	}
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewReceiverListener(l, opts...), nil
+}
+
+// NewReceiverListener wraps an existing listener, e.g. one with TLS.
+func NewReceiverListener(l net.Listener, opts ...ReceiverOption) *Receiver {
+	r := &Receiver{listener: l, logger: std, conns: make(map[net.Conn]struct{})}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Addr returns the listening address.
+func (r *Receiver) Addr() net.Addr {
+	return r.listener.Addr()
+}
+
+// Serve accepts connections until Close is called. It always returns a
+// non-nil error; after Close it is net.ErrClosed.
+func (r *Receiver) Serve() error {
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			return err
+		}
+		r.mu.Lock()
+		if r.closed {
+			r.mu.Unlock()
+			conn.Close()
+			return net.ErrClosed
+		}
+		r.conns[conn] = struct{}{}
+		r.wg.Add(1)
+		r.mu.Unlock()
+
+		go r.handle(conn)
+	}
+}
+
+// Close stops accepting, drops open connections and waits for their
+// in-progress entries to be written.
+func (r *Receiver) Close() error {
+	r.mu.Lock()
+	r.closed = true
+	err := r.listener.Close()
+	for conn := range r.conns {
+		conn.Close()
+	}
+	r.mu.Unlock()
+	r.wg.Wait()
+	return err
+}
+
+func (r *Receiver) handle(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		r.mu.Lock()
+		delete(r.conns, conn)
+		r.mu.Unlock()
+		r.wg.Done()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxForwardedLine)
+
+	if !scanner.Scan() {
+		return
+	}
+	var hello forwardHello
+	if err := json.Unmarshal(scanner.Bytes(), &hello); err != nil || hello.Process == "" {
+		r.logger.Warningf("receiver: dropping connection from %s without a valid handshake", conn.RemoteAddr())
+		return
+	}
+
+	for scanner.Scan() {
+		r.emit(hello, scanner.Bytes())
+	}
+}
+
+// emit tags a forwarded line with its source and writes it to the outputs.
+// The sender already applied its own level filtering and pipeline, so the
+// entry is written as-is rather than being logged again.
+func (r *Receiver) emit(hello forwardHello, line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+
+	var entry map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if len(line) < 2 || line[0] != '{' || dec.Decode(&entry) != nil {
+		// Text-format senders: keep the line as the message, with the
+		// level and time of its prefix.
+		level, at := syslogEntryMeta(line)
+		entry = map[string]interface{}{
+			"timestamp": at.Format(time.RFC3339),
+			"level":     level,
+			"message":   string(line),
+		}
+	}
+	entry["source_process"] = hello.Process
+	entry["source_pid"] = hello.PID
+	if hello.Host != "" {
+		entry["source_host"] = hello.Host
+	}
+
+	st := r.logger.load()
+	data, err := st.encode(entry)
+	if err != nil {
+		return
+	}
+	if w := st.structuredWriter; w != nil {
+		_, _ = w.Write(append(data, '\n'))
+	}
+}
+
+// Forwarder streams entries to a Receiver. Pass it to Init as an additional
+// output. The connection is re-established on the next write after a failure.
+type Forwarder struct {
+	network, addr string
+	hello         []byte
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewForwarder connects to the Receiver at network and addr.
+func NewForwarder(network, addr string) (*Forwarder, error) {
+	hostname, _ := os.Hostname()
+	hello, _ := json.Marshal(forwardHello{
+		Process: filepath.Base(os.Args[0]),
+		PID:     os.Getpid(),
+		Host:    hostname,
+	})
+	f := &Forwarder{network: network, addr: addr, hello: append(hello, '\n')}
+	if err := f.connect(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *Forwarder) connect() error {
+	conn, err := net.DialTimeout(f.network, f.addr, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Write(f.hello); err != nil {
+		conn.Close()
+		return err
+	}
+	f.conn = conn
+	return nil
+}
+
+// Write sends p, which must hold whole lines, to the Receiver.
+func (f *Forwarder) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.conn == nil {
+		if err := f.connect(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.conn.Write(p)
+	if err != nil {
+		f.conn.Close()
+		f.conn = nil
+	}
+	return n, err
+}
+
+// Close closes the connection to the Receiver.
+func (f *Forwarder) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conn == nil {
+		return nil
+	}
+	err := f.conn.Close()
+	f.conn = nil
+	return err
+}
+
+var _ io.WriteCloser = (*Forwarder)(nil)
@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReceiver_MergesForwardedEntries(t *testing.T) {
+	var buf bytes.Buffer
+	out := &lockedWriter{w: &buf}
+	Init("debug", "json", "", "", false, false, false, nil, nil, out)
+
+	sock := filepath.Join(t.TempDir(), "logs.sock")
+	r, err := NewReceiver("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go r.Serve()
+
+	f, err := NewForwarder("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(f, `{"level":"ERROR","message":"from worker","job":7}`)
+	fmt.Fprintln(f, `plain text line`)
+	f.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		out.mu.Lock()
+		n := bytes.Count(buf.Bytes(), []byte("\n"))
+		out.mu.Unlock()
+		if n >= 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	r.Close()
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 forwarded entries, got %d", len(entries))
+	}
+	e := entries[0]
+	if e["message"] != "from worker" || e["level"] != "ERROR" || e["job"] != float64(7) {
+		t.Errorf("expected entry to be kept as-is, got %v", e)
+	}
+	if e["source_process"] != filepath.Base(os.Args[0]) || e["source_pid"] != float64(os.Getpid()) {
+		t.Errorf("expected source process tags, got %v", e)
+	}
+	if entries[1]["message"] != "plain text line" || entries[1]["source_process"] == nil {
+		t.Errorf("expected text line to be wrapped, got %v", entries[1])
+	}
+}
+
+func TestReceiver_RejectsMissingHandshake(t *testing.T) {
+	var buf bytes.Buffer
+	out := &lockedWriter{w: &buf}
+	Init("debug", "json", "", "", false, false, false, nil, nil, out)
+
+	r, err := NewReceiver("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go r.Serve()
+
+	conn, err := net.Dial("tcp", r.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(conn, `{"message":"no hello"}`)
+	fmt.Fprintln(conn, `{"message":"sneaky"}`)
+	// Wait for the receiver to drop the connection.
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _ = conn.Read(make([]byte, 1))
+	conn.Close()
+	r.Close()
+
+	out.mu.Lock()
+	defer out.mu.Unlock()
+	if bytes.Contains(buf.Bytes(), []byte("sneaky")) {
+		t.Errorf("entries without a handshake must not be merged")
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("without a valid handshake")) {
+		t.Errorf("expected a warning about the rejected connection, got %s", buf.String())
+	}
+}
+
+func TestReceiver_WritesToChosenLoggerWithTextLevels(t *testing.T) {
+	var buf bytes.Buffer
+	out := &lockedWriter{w: &buf}
+	l := New(WithFormat("json"), WithOutputs(out))
+
+	sock := filepath.Join(t.TempDir(), "logs.sock")
+	r, err := NewReceiver("unix", sock, ReceiverLogger(l))
+	if err != nil {
+		t.Fatal(err)
+	}
+	go r.Serve()
+
+	f, err := NewForwarder("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(f, `WARNING: 2026/10/14 12:00:00.000000 worker.go:12: disk almost full`)
+	f.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		out.mu.Lock()
+		n := buf.Len()
+		out.mu.Unlock()
+		if n > 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	r.Close()
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 1 || entries[0]["level"] != "WARNING" {
+		t.Errorf("expected a WARNING entry on the chosen logger, got %v", entries)
+	}
+}
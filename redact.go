@@ -0,0 +1,184 @@
+package logger
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Value patterns for RedactConfig.Values.
+const (
+	// EmailPattern matches email addresses.
+	EmailPattern = `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`
+	// CreditCardPattern matches 13 to 19 digit card numbers, optionally
+	// grouped by spaces or dashes.
+	CreditCardPattern = `\b(?:\d[ -]?){12,18}\d\b`
+)
+
+// RedactConfig masks sensitive data before entries reach any output.
+type RedactConfig struct {
+	// Keys are field name patterns whose values are replaced entirely, e.g.
+	// "password", "token" or "*_secret". They are matched case-insensitively
+	// with path.Match against every field, including fields of nested maps;
+	// a dotted key ("user.password") also matches by its last segment.
+	Keys []string `json:"keys" yaml:"keys"`
+	// Values are regular expressions, e.g. EmailPattern, whose matches are
+	// masked in messages and in every string field. Values of other types,
+	// except errors and slices and maps of strings, are not inspected.
+	Values []string `json:"values" yaml:"values"`
+	// Mask replaces redacted data. Defaults to "[REDACTED]".
+	Mask string `json:"mask" yaml:"mask"`
+}
+
+func (rc RedactConfig) validate() error {
+	_, err := newRedactor(&rc)
+	return err
+}
+
+// redactor implements RedactConfig. A nil redactor changes nothing.
+type redactor struct {
+	keys   []string // lower-cased
+	values []*regexp.Regexp
+	mask   string
+}
+
+// newRedactor compiles rc, reporting the first invalid pattern.
+func newRedactor(rc *RedactConfig) (*redactor, error) {
+	if rc == nil || len(rc.Keys) == 0 && len(rc.Values) == 0 {
+		return nil, nil
+	}
+	r := &redactor{mask: rc.Mask}
+	if r.mask == "" {
+		r.mask = "[REDACTED]"
+	}
+	for _, k := range rc.Keys {
+		k = strings.ToLower(k)
+		if _, err := path.Match(k, ""); err != nil {
+			return nil, fmt.Errorf("redact key %q: %w", k, err)
+		}
+		r.keys = append(r.keys, k)
+	}
+	for _, v := range rc.Values {
+		re, err := regexp.Compile(v)
+		if err != nil {
+			return nil, fmt.Errorf("redact value %q: %w", v, err)
+		}
+		r.values = append(r.values, re)
+	}
+	return r, nil
+}
+
+// mustRedactor is newRedactor for options, which can't return errors. An
+// invalid configuration masks every field and message rather than risk
+// leaking what it was meant to hide.
+func mustRedactor(rc *RedactConfig) *redactor {
+	r, err := newRedactor(rc)
+	if err != nil {
+		diagnose("redact", err, "invalid redaction, masking every entry")
+		mask := rc.Mask
+		if mask == "" {
+			mask = "[REDACTED]"
+		}
+		return &redactor{values: []*regexp.Regexp{regexp.MustCompile(`(?s).+`)}, mask: mask}
+	}
+	return r
+}
+
+func (r *redactor) sensitiveKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	key = strings.ToLower(key)
+	last := key[strings.LastIndexByte(key, '.')+1:]
+	for _, pattern := range r.keys {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, last); ok && last != key {
+			return true
+		}
+	}
+	return false
+}
+
+// message masks the value patterns in msg.
+func (r *redactor) message(msg string) string {
+	if r == nil {
+		return msg
+	}
+	for _, re := range r.values {
+		msg = re.ReplaceAllString(msg, r.mask)
+	}
+	return msg
+}
+
+// fields redacts an entry being built by writeStructured in place. Nested
+// maps and slices are copied rather than modified, since they belong to the
+// caller.
+func (r *redactor) fields(fields map[string]interface{}) {
+	if r == nil {
+		return
+	}
+	for k, v := range fields {
+		fields[k] = r.value(k, v)
+	}
+}
+
+// redactedFields returns a redacted copy of fields.
+func (r *redactor) redactedFields(fields map[string]interface{}) map[string]interface{} {
+	if r == nil || len(fields) == 0 {
+		return fields
+	}
+	out := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		out[k] = r.value(k, v)
+	}
+	return out
+}
+
+func (r *redactor) value(key string, v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	if r.sensitiveKey(key) {
+		return r.mask
+	}
+	switch v := v.(type) {
+	case string:
+		return r.message(v)
+	case error:
+		if len(r.values) == 0 {
+			return v
+		}
+		return r.message(v.Error())
+	case map[string]interface{}:
+		return r.redactedFields(v)
+	case map[string]string:
+		out := make(map[string]string, len(v))
+		for k, s := range v {
+			if r.sensitiveKey(k) {
+				out[k] = r.mask
+			} else {
+				out[k] = r.message(s)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = r.value("", e)
+		}
+		return out
+	case []string:
+		if len(r.values) == 0 {
+			return v
+		}
+		out := make([]string, len(v))
+		for i, s := range v {
+			out[i] = r.message(s)
+		}
+		return out
+	}
+	return v
+}
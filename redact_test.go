@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRedaction_KeysAndValues(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithFormat("json"), WithOutputs(&out), WithRedaction(RedactConfig{
+		Keys:   []string{"password", "token", "*_secret"},
+		Values: []string{EmailPattern, CreditCardPattern},
+	}))
+	profile := map[string]interface{}{"email": "ada@example.com", "Token": "t0k3n"}
+	l.InfofMap(context.Background(), map[string]interface{}{
+		"message":       "signup for ada@example.com",
+		"password":      "hunter2",
+		"client_secret": "s3cr3t",
+		"user.token":    "abc",
+		"card":          "4111 1111 1111 1111",
+		"error":         errors.New("charge declined for bob@example.org"),
+		"profile":       profile,
+		"tags":          []string{"vip", "carol@example.net"},
+		"attempts":      3,
+	})
+	got := out.String()
+	for _, leaked := range []string{"ada@example.com", "hunter2", "s3cr3t", `"abc"`, "4111", "bob@example.org", "t0k3n", "carol@example.net"} {
+		if strings.Contains(got, leaked) {
+			t.Errorf("expected %q to be redacted, got %s", leaked, got)
+		}
+	}
+	for _, want := range []string{`"message":"signup for [REDACTED]"`, `"attempts":3`, `"vip"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %s in %s", want, got)
+		}
+	}
+	if profile["email"] != "ada@example.com" {
+		t.Error("expected nested maps of the caller to be left alone")
+	}
+}
+
+func TestRedaction_PlainEntries(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithOutputs(&out), WithRedaction(RedactConfig{Keys: []string{"api_key"}, Values: []string{EmailPattern}, Mask: "***"}))
+	l.WithField("api_key", "k-123").Warningf("bounce from %s", "dave@example.com")
+	if got := out.String(); strings.Contains(got, "dave@") || strings.Contains(got, "k-123") || !strings.Contains(got, "bounce from *** api_key=***") {
+		t.Errorf("unexpected entry %q", got)
+	}
+}
+
+func TestRedactConfig_Validate(t *testing.T) {
+	err := Config{Redact: &RedactConfig{Keys: []string{"[bad"}}}.Validate()
+	if err == nil || !strings.Contains(err.Error(), `redact key "[bad"`) {
+		t.Errorf("expected an invalid key pattern to be reported, got %v", err)
+	}
+	err = Config{Redact: &RedactConfig{Values: []string{"(unclosed"}}}.Validate()
+	if err == nil || !strings.Contains(err.Error(), `redact value "(unclosed"`) {
+		t.Errorf("expected an invalid value pattern to be reported, got %v", err)
+	}
+
+	var out bytes.Buffer
+	l := New(WithOutputs(&out), WithRedaction(RedactConfig{Values: []string{"(unclosed"}}))
+	l.Info("secret stuff")
+	if strings.Contains(out.String(), "secret stuff") {
+		t.Errorf("expected an invalid configuration to mask entries, got %q", out.String())
+	}
+}
+
+func TestStructuredEntries_LeaveCallerMapsAlone(t *testing.T) {
+	SetKeyCase(KeyCaseSnake)
+	defer SetKeyCase(KeyCaseAsIs)
+	EnableDotExpansion(true)
+	defer EnableDotExpansion(false)
+
+	var out bytes.Buffer
+	l := New(WithFormat("json"), WithOutputs(&out), WithRedaction(RedactConfig{Keys: []string{"password"}}))
+	http := map[string]interface{}{"statusCode": 200}
+	fields := map[string]interface{}{"message": "login", "password": "hunter2", "http": http, "http.method": "POST"}
+	l.InfofMap(context.Background(), fields)
+	l.Logf(LevelInfo, nil, fields, "login %s", "again")
+
+	if len(fields) != 4 || fields["password"] != "hunter2" || fields["message"] != "login" {
+		t.Errorf("caller's map was modified: %v", fields)
+	}
+	if len(http) != 1 || http["statusCode"] != 200 {
+		t.Errorf("caller's nested map was modified: %v", http)
+	}
+	e := decodeLogLines(t, &out)[0]
+	if nested, _ := e["http"].(map[string]interface{}); e["password"] != "[REDACTED]" || nested["status_code"] != float64(200) || nested["method"] != "POST" {
+		t.Errorf("unexpected entry %v", e)
+	}
+}
@@ -0,0 +1,288 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RedisStreamConfig configures the Redis Streams sink.
+type RedisStreamConfig struct {
+	// Addr is the server's host:port; the port defaults to 6379.
+	Addr string
+	// Username and Password authenticate with AUTH; Username needs Redis 6
+	// ACLs. DB selects the database.
+	Username string
+	Password string
+	DB       int
+	// TLS enables TLS with the given configuration.
+	TLS *tls.Config
+	// Stream is the stream key; defaults to "logs".
+	Stream string
+	// MaxLen caps the stream with XADD MAXLEN ~, trimming the oldest
+	// entries. Zero leaves the stream unbounded.
+	MaxLen int64
+	// Timeout bounds connecting and each write; defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// RedisStreamWriter appends entries to a Redis stream with XADD. Pass it to
+// Init as an additional output. The fields of JSON entries become the stream
+// entry's fields, nested values JSON-encoded; other lines are stored as level
+// and message.
+type RedisStreamWriter struct {
+	cfg  RedisStreamConfig
+	addr string
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// NewRedisStreamWriter validates cfg and connects to Redis.
+func NewRedisStreamWriter(cfg RedisStreamConfig) (*RedisStreamWriter, error) {
+	if cfg.Addr == "" {
+		return nil, errors.New("redis: Addr is required")
+	}
+	addr := cfg.Addr
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "6379")
+	}
+	if cfg.Stream == "" {
+		cfg.Stream = "logs"
+	}
+	if cfg.MaxLen < 0 {
+		return nil, fmt.Errorf("redis: invalid MaxLen %d", cfg.MaxLen)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	w := &RedisStreamWriter{cfg: cfg, addr: addr}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *RedisStreamWriter) connect() error {
+	dialer := &net.Dialer{Timeout: w.cfg.Timeout}
+	var conn net.Conn
+	var err error
+	if w.cfg.TLS != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", w.addr, w.cfg.TLS)
+	} else {
+		conn, err = dialer.Dial("tcp", w.addr)
+	}
+	if err != nil {
+		return fmt.Errorf("redis: %w", err)
+	}
+	w.conn = conn
+	w.r = bufio.NewReader(conn)
+
+	var setup [][]string
+	switch {
+	case w.cfg.Username != "":
+		setup = append(setup, []string{"AUTH", w.cfg.Username, w.cfg.Password})
+	case w.cfg.Password != "":
+		setup = append(setup, []string{"AUTH", w.cfg.Password})
+	}
+	if w.cfg.DB != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(w.cfg.DB)})
+	}
+	if err := w.do(setup); err != nil {
+		conn.Close()
+		w.conn = nil
+		return fmt.Errorf("redis: %w", err)
+	}
+	return nil
+}
+
+// do pipelines cmds and reads their replies, returning the first error
+// reply.
+func (w *RedisStreamWriter) do(cmds [][]string) error {
+	if len(cmds) == 0 {
+		return nil
+	}
+	var buf []byte
+	for _, cmd := range cmds {
+		buf = appendRESPCommand(buf, cmd)
+	}
+	_ = w.conn.SetDeadline(time.Now().Add(w.cfg.Timeout))
+	defer w.conn.SetDeadline(time.Time{})
+	if _, err := w.conn.Write(buf); err != nil {
+		return err
+	}
+	var firstErr error
+	for range cmds {
+		err := readRESPReply(w.r)
+		var rerr respError
+		if errors.As(err, &rerr) {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return firstErr
+}
+
+// Write adds each line of p to the stream; the lines are pipelined in one
+// round trip.
+func (w *RedisStreamWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var cmds [][]string
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		cmds = append(cmds, w.xadd(line))
+	}
+	if len(cmds) == 0 {
+		return len(p), nil
+	}
+	if w.conn == nil {
+		if err := w.connect(); err != nil {
+			return 0, err
+		}
+	}
+	if err := w.do(cmds); err != nil {
+		var rerr respError
+		if !errors.As(err, &rerr) {
+			w.conn.Close()
+			w.conn = nil
+		}
+		return 0, fmt.Errorf("redis: %w", err)
+	}
+	return len(p), nil
+}
+
+func (w *RedisStreamWriter) xadd(line []byte) []string {
+	cmd := []string{"XADD", w.cfg.Stream}
+	if w.cfg.MaxLen > 0 {
+		cmd = append(cmd, "MAXLEN", "~", strconv.FormatInt(w.cfg.MaxLen, 10))
+	}
+	cmd = append(cmd, "*")
+
+	var fields map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if len(line) < 2 || line[0] != '{' || dec.Decode(&fields) != nil || len(fields) == 0 {
+		level, _ := syslogEntryMeta(line)
+		return append(cmd, "level", string(level), "message", string(line))
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		cmd = append(cmd, k, redisFieldValue(fields[k]))
+	}
+	return cmd
+}
+
+// redisFieldValue returns v as a stream field value: strings and numbers as
+// is, everything else JSON-encoded.
+func redisFieldValue(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// Close closes the connection to Redis.
+func (w *RedisStreamWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+var _ io.WriteCloser = (*RedisStreamWriter)(nil)
+
+// respError is an error reply from the server, such as WRONGTYPE or
+// NOAUTH. The connection stays usable after one.
+type respError string
+
+func (e respError) Error() string { return string(e) }
+
+func appendRESPCommand(b []byte, args []string) []byte {
+	b = append(b, '*')
+	b = strconv.AppendInt(b, int64(len(args)), 10)
+	b = append(b, "\r\n"...)
+	for _, a := range args {
+		b = append(b, '$')
+		b = strconv.AppendInt(b, int64(len(a)), 10)
+		b = append(b, "\r\n"...)
+		b = append(b, a...)
+		b = append(b, "\r\n"...)
+	}
+	return b
+}
+
+// readRESPReply reads and discards one reply, returning a respError for
+// error replies.
+func readRESPReply(r *bufio.Reader) error {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return errors.New("malformed reply")
+	}
+	kind, rest := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+', ':':
+		return nil
+	case '-':
+		return respError(rest)
+	case '$':
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return errors.New("malformed reply")
+		}
+		if n < 0 {
+			return nil
+		}
+		_, err = r.Discard(n + 2)
+		return err
+	case '*':
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return errors.New("malformed reply")
+		}
+		for i := 0; i < n; i++ {
+			if err := readRESPReply(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unexpected reply type %q", kind)
+}
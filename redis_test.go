@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRedis records the commands it receives and replies +OK, or with reply
+// when it returns a non-empty string.
+type fakeRedis struct {
+	ln    net.Listener
+	reply func(cmd []string) string
+
+	mu   sync.Mutex
+	cmds [][]string
+}
+
+func newFakeRedis(t *testing.T, reply func(cmd []string) string) *fakeRedis {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	s := &fakeRedis{ln: ln, reply: reply}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		header, err := r.ReadString('\n')
+		if err != nil || header[0] != '*' {
+			return
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+		cmd := make([]string, n)
+		for i := range cmd {
+			sizeLine, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			size, _ := strconv.Atoi(strings.TrimSpace(sizeLine[1:]))
+			buf := make([]byte, size+2)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return
+			}
+			cmd[i] = string(buf[:size])
+		}
+		s.mu.Lock()
+		s.cmds = append(s.cmds, cmd)
+		s.mu.Unlock()
+
+		reply := "+OK\r\n"
+		if s.reply != nil {
+			if r := s.reply(cmd); r != "" {
+				reply = r
+			}
+		}
+		if cmd[0] == "XADD" && reply == "+OK\r\n" {
+			reply = "$15\r\n1700000000000-0\r\n"
+		}
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeRedis) commands() [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]string(nil), s.cmds...)
+}
+
+func TestRedisStreamWriter_XAdd(t *testing.T) {
+	srv := newFakeRedis(t, nil)
+	w, err := NewRedisStreamWriter(RedisStreamConfig{
+		Addr: srv.ln.Addr().String(), Username: "app", Password: "secret", DB: 2,
+		Stream: "logs:billing", MaxLen: 10000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	entry := `{"level":"INFO","message":"charged","amount":12.5,"tags":["a"]}` + "\n"
+	if _, err := w.Write([]byte(entry + "ERROR: plain text\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"AUTH app secret",
+		"SELECT 2",
+		"XADD logs:billing MAXLEN ~ 10000 * amount 12.5 level INFO message charged tags [\"a\"]",
+		"XADD logs:billing MAXLEN ~ 10000 * level ERROR message ERROR: plain text",
+	}
+	cmds := srv.commands()
+	if len(cmds) != len(want) {
+		t.Fatalf("got commands %q", cmds)
+	}
+	for i, cmd := range cmds {
+		if got := strings.Join(cmd, " "); got != want[i] {
+			t.Errorf("command %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestRedisStreamWriter_ErrorReply(t *testing.T) {
+	srv := newFakeRedis(t, func(cmd []string) string {
+		if cmd[1] == "taken" {
+			return "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"
+		}
+		return ""
+	})
+	w, err := NewRedisStreamWriter(RedisStreamConfig{Addr: srv.ln.Addr().String(), Stream: "taken"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if _, err := w.Write([]byte(`{"message":"x"}` + "\n")); err == nil || !strings.Contains(err.Error(), "WRONGTYPE") {
+		t.Fatalf("Write() = %v, want the WRONGTYPE reply", err)
+	}
+	if w.conn == nil {
+		t.Error("an error reply should keep the connection")
+	}
+}
+
+func TestRedisStreamWriter_Reconnects(t *testing.T) {
+	srv := newFakeRedis(t, nil)
+	w, err := NewRedisStreamWriter(RedisStreamConfig{Addr: srv.ln.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.conn.Close()
+	if _, err := w.Write([]byte("INFO: lost\n")); err == nil {
+		t.Fatal("expected an error on a closed connection")
+	}
+	if _, err := w.Write([]byte("INFO: delivered\n")); err != nil {
+		t.Fatal(err)
+	}
+	cmds := srv.commands()
+	if len(cmds) != 1 || cmds[0][len(cmds[0])-1] != "INFO: delivered" {
+		t.Errorf("unexpected commands %q", cmds)
+	}
+}
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// ReloadOnSignal calls reload whenever the process receives one of sigs
+// (SIGHUP when none are given), so an init system can change log verbosity
+// with `kill -HUP`. The outcome of every reload is logged. The returned
+// function stops listening.
+//
+//	stop := logger.ReloadOnSignal(logger.ReloadLevelFromFile("/etc/app/log-level"))
+//	defer stop()
+func ReloadOnSignal(reload func() error, sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				if err := reload(); err != nil {
+					ErrorfMap(nil, map[string]interface{}{
+						"message": fmt.Sprintf("log configuration reload failed: %v", err),
+						"signal":  sig.String(),
+					})
+					continue
+				}
+				InfofMap(nil, map[string]interface{}{
+					"message":   "log configuration reloaded",
+					"signal":    sig.String(),
+					"log_level": GetLevel(),
+				})
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// ReloadLevelFromFile returns a reload function for ReloadOnSignal that sets
+// the package-level logger's level from the first line of the file at path.
+func ReloadLevelFromFile(path string) func() error {
+	return func() error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		level, _, _ := strings.Cut(string(data), "\n")
+		return SetLevel(strings.TrimSpace(level))
+	}
+}
@@ -0,0 +1,46 @@
+//go:build unix
+
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReloadOnSignal_LevelFromFile(t *testing.T) {
+	var buf bytes.Buffer
+	out := &lockedWriter{w: &buf}
+	Init("info", "json", "", "", false, false, false, nil, nil, out)
+
+	path := filepath.Join(t.TempDir(), "log-level")
+	if err := os.WriteFile(path, []byte("debug\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stop := ReloadOnSignal(ReloadLevelFromFile(path), syscall.SIGUSR1)
+	defer stop()
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for GetLevel() != "debug" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if GetLevel() != "debug" {
+		t.Fatalf("expected the level to be reloaded, got %q", GetLevel())
+	}
+
+	if err := ReloadLevelFromFile(filepath.Join(t.TempDir(), "missing"))(); err == nil {
+		t.Errorf("expected a missing file to be reported")
+	}
+	if err := os.WriteFile(path, []byte("verbose"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReloadLevelFromFile(path)(); err == nil || GetLevel() != "debug" {
+		t.Errorf("expected an invalid level to be rejected, got %v", err)
+	}
+}
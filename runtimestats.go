@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"time"
+)
+
+// LogRuntimeStats logs a structured snapshot of heap usage, GC activity,
+// goroutine count and open file descriptors at INFO level.
+func LogRuntimeStats() {
+	logWithMap(LevelInfo, nil, runtimeStatsFields())
+}
+
+// StartRuntimeStats logs a runtime stats snapshot every interval until ctx is
+// cancelled or the returned stop function is called.
+func StartRuntimeStats(ctx context.Context, interval time.Duration) (stop func()) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				logWithMap(LevelInfo, ctx, runtimeStatsFields())
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return cancel
+}
+
+func runtimeStatsFields() map[string]interface{} {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	fields := map[string]interface{}{
+		"message":           "runtime stats",
+		"heap_alloc_bytes":  m.HeapAlloc,
+		"heap_sys_bytes":    m.HeapSys,
+		"heap_objects":      m.HeapObjects,
+		"num_gc":            m.NumGC,
+		"gc_pause_total_ms": float64(m.PauseTotalNs) / float64(time.Millisecond),
+		"goroutines":        runtime.NumGoroutine(),
+	}
+	if m.NumGC > 0 {
+		fields["gc_last_pause_ms"] = float64(m.PauseNs[(m.NumGC+255)%256]) / float64(time.Millisecond)
+	}
+	if fds, ok := openFDs(); ok {
+		fields["open_fds"] = fds
+	}
+	return fields
+}
+
+// openFDs counts the entries of /proc/self/fd, which only exists on Linux.
+// The descriptor ReadDir opens on the directory itself is listed too and
+// isn't counted.
+func openFDs() (int, bool) {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return 0, false
+	}
+	return len(entries) - 1, true
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestLogRuntimeStats(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	LogRuntimeStats()
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected one stats entry, got %d", len(entries))
+	}
+	for _, key := range []string{"heap_alloc_bytes", "num_gc", "goroutines"} {
+		if _, ok := entries[0][key]; !ok {
+			t.Errorf("expected %s in runtime stats entry", key)
+		}
+	}
+	if g, _ := entries[0]["goroutines"].(float64); g < 1 {
+		t.Errorf("expected at least one goroutine, got %v", entries[0]["goroutines"])
+	}
+}
+
+func TestOpenFDs_Exact(t *testing.T) {
+	before, ok := openFDs()
+	if !ok {
+		t.Skip("/proc/self/fd is not available")
+	}
+	// Stat doesn't open a descriptor, so probing each number is exact.
+	names, _ := os.ReadDir("/proc/self/fd")
+	probed := 0
+	for _, e := range names {
+		if n, err := strconv.Atoi(e.Name()); err == nil {
+			if _, err := os.Stat("/proc/self/fd/" + strconv.Itoa(n)); err == nil {
+				probed++
+			}
+		}
+	}
+	if before != probed {
+		t.Errorf("openFDs = %d, want %d", before, probed)
+	}
+
+	f, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if after, _ := openFDs(); after != before+1 {
+		t.Errorf("openFDs after opening a file = %d, want %d", after, before+1)
+	}
+}
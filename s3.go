@@ -0,0 +1,337 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// S3Config configures the S3 shipping sink.
+type S3Config struct {
+	// Bucket and Region locate the bucket.
+	Bucket string
+	Region string
+	// Endpoint is an S3-compatible endpoint ("http://minio:9000"), addressed
+	// path-style. Empty means AWS with virtual-hosted addressing.
+	Endpoint string
+	// Credentials default to $AWS_ACCESS_KEY_ID, $AWS_SECRET_ACCESS_KEY and
+	// $AWS_SESSION_TOKEN.
+	Credentials AWSCredentials
+	// KeyTemplate names the objects. {service}, {hostname}, {uuid}, {date}
+	// (2006-01-02), {hour} (15) and {timestamp} (20060102T150405Z) are
+	// replaced, the times being the object's first entry in UTC. Defaults to
+	// "logs/{service}/{date}/{uuid}.json.gz".
+	KeyTemplate string
+	// MaxSize and Interval trigger an upload when the uncompressed object
+	// reaches MaxSize bytes (default 50MB) or Interval (default 5 minutes)
+	// after its first entry.
+	MaxSize  int64
+	Interval time.Duration
+	// BufferDir holds the objects until they are uploaded; defaults to
+	// os.TempDir. Objects that fail to upload stay there and are retried.
+	BufferDir string
+	// Client sends the requests; defaults to a client with a 5 minute
+	// timeout.
+	Client *http.Client
+}
+
+const defaultS3KeyTemplate = "logs/{service}/{date}/{uuid}.json.gz"
+
+// S3Writer buffers entries in gzip-compressed NDJSON files and uploads them
+// to S3 as objects. Writes only touch the local file; upload errors are
+// reported by LastError and by the next Write.
+type S3Writer struct {
+	cfg    S3Config
+	client *http.Client
+
+	mu      sync.Mutex
+	current *s3Object
+	pending []*s3Object
+	lastErr error
+
+	uploadMu sync.Mutex
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// s3Object is a buffered object, written to a local file until it is sealed.
+type s3Object struct {
+	path    string
+	file    *os.File
+	zw      *gzip.Writer
+	size    int64
+	started time.Time
+	service string
+	key     string
+}
+
+// NewS3Writer validates cfg and returns a sink that can be passed to Init as
+// an additional output.
+func NewS3Writer(cfg S3Config) (*S3Writer, error) {
+	if cfg.Bucket == "" || cfg.Region == "" {
+		return nil, errors.New("s3: Bucket and Region are required")
+	}
+	if cfg.Credentials.AccessKeyID == "" {
+		cfg.Credentials = AWSCredentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+	}
+	if cfg.Credentials.AccessKeyID == "" || cfg.Credentials.SecretAccessKey == "" {
+		return nil, errors.New("s3: credentials are required")
+	}
+	if cfg.Endpoint != "" {
+		if u, err := url.Parse(cfg.Endpoint); err != nil || u.Host == "" {
+			return nil, fmt.Errorf("s3: invalid endpoint %q", cfg.Endpoint)
+		}
+	}
+	if cfg.KeyTemplate == "" {
+		cfg.KeyTemplate = defaultS3KeyTemplate
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = 50 << 20
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Minute
+	}
+	if cfg.BufferDir == "" {
+		cfg.BufferDir = os.TempDir()
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+
+	w := &S3Writer{cfg: cfg, client: client, stop: make(chan struct{}), done: make(chan struct{})}
+	go w.loop()
+	return w, nil
+}
+
+// Write appends p to the current object. It returns the last background
+// upload error, if any, after writing p.
+func (w *S3Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.current == nil {
+		obj, err := w.newObject(p)
+		if err != nil {
+			return 0, fmt.Errorf("s3: %w", err)
+		}
+		w.current = obj
+	}
+	if _, err := w.current.zw.Write(p); err != nil {
+		return 0, fmt.Errorf("s3: %w", err)
+	}
+	w.current.size += int64(len(p))
+	if w.current.size >= w.cfg.MaxSize {
+		if err := w.sealLocked(); err != nil {
+			return 0, fmt.Errorf("s3: %w", err)
+		}
+		go w.uploadInBackground()
+	}
+	if err := w.lastErr; err != nil {
+		w.lastErr = nil
+		return len(p), fmt.Errorf("s3: %w", err)
+	}
+	return len(p), nil
+}
+
+func (w *S3Writer) newObject(first []byte) (*s3Object, error) {
+	f, err := os.CreateTemp(w.cfg.BufferDir, "go-logger-s3-*.json.gz")
+	if err != nil {
+		return nil, err
+	}
+	obj := &s3Object{path: f.Name(), file: f, zw: gzip.NewWriter(f), started: time.Now().UTC()}
+	var entry struct {
+		Service string `json:"service"`
+	}
+	if line, _, _ := bytes.Cut(first, []byte{'\n'}); json.Unmarshal(line, &entry) == nil {
+		obj.service = entry.Service
+	}
+	return obj, nil
+}
+
+// sealLocked completes the current object and queues it for upload.
+func (w *S3Writer) sealLocked() error {
+	obj := w.current
+	if obj == nil {
+		return nil
+	}
+	w.current = nil
+	err := obj.zw.Close()
+	if cerr := obj.file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	key, err := w.objectKey(obj)
+	if err != nil {
+		return err
+	}
+	obj.key = key
+	w.pending = append(w.pending, obj)
+	return nil
+}
+
+func (w *S3Writer) loop() {
+	defer close(w.done)
+	// Check more often than Interval so objects are uploaded close to their
+	// deadline.
+	ticker := time.NewTicker(w.cfg.Interval / 10)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			var err error
+			if w.current != nil && time.Since(w.current.started) >= w.cfg.Interval {
+				err = w.sealLocked()
+			}
+			w.mu.Unlock()
+			if err != nil {
+				w.setError(err)
+				continue
+			}
+			w.uploadInBackground()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+func (w *S3Writer) uploadInBackground() {
+	if err := w.uploadPending(); err != nil {
+		w.setError(err)
+	}
+}
+
+func (w *S3Writer) setError(err error) {
+	w.mu.Lock()
+	w.lastErr = err
+	w.mu.Unlock()
+}
+
+// uploadPending uploads the sealed objects in order, stopping at the first
+// failure so it is retried later.
+func (w *S3Writer) uploadPending() error {
+	w.uploadMu.Lock()
+	defer w.uploadMu.Unlock()
+	for {
+		w.mu.Lock()
+		if len(w.pending) == 0 {
+			w.mu.Unlock()
+			return nil
+		}
+		obj := w.pending[0]
+		w.mu.Unlock()
+
+		if err := w.upload(obj); err != nil {
+			return err
+		}
+		_ = os.Remove(obj.path)
+		w.mu.Lock()
+		w.pending = w.pending[1:]
+		w.mu.Unlock()
+	}
+}
+
+func (w *S3Writer) upload(obj *s3Object) error {
+	data, err := os.ReadFile(obj.path)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, w.objectURL(obj.key), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+	sum := sha256.Sum256(data)
+	signSigV4(req, w.cfg.Credentials, w.cfg.Region, "s3", hex.EncodeToString(sum[:]), time.Now())
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("uploading %s: %w", obj.key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("uploading %s: %s: %s", obj.key, resp.Status, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (w *S3Writer) objectURL(key string) string {
+	path := (&url.URL{Path: "/" + key}).EscapedPath()
+	if w.cfg.Endpoint != "" {
+		return strings.TrimSuffix(w.cfg.Endpoint, "/") + "/" + w.cfg.Bucket + path
+	}
+	return "https://" + w.cfg.Bucket + ".s3." + w.cfg.Region + ".amazonaws.com" + path
+}
+
+func (w *S3Writer) objectKey(obj *s3Object) (string, error) {
+	id, err := newChannelID()
+	if err != nil {
+		return "", err
+	}
+	service := obj.service
+	if service == "" {
+		service = "unknown"
+	}
+	hostname, _ := os.Hostname()
+	return strings.NewReplacer(
+		"{service}", service,
+		"{hostname}", hostname,
+		"{uuid}", id,
+		"{date}", obj.started.Format("2006-01-02"),
+		"{hour}", obj.started.Format("15"),
+		"{timestamp}", obj.started.Format(sigV4DateFormat),
+	).Replace(w.cfg.KeyTemplate), nil
+}
+
+// Flush uploads the current object and every object still pending.
+func (w *S3Writer) Flush() error {
+	w.mu.Lock()
+	err := w.sealLocked()
+	w.mu.Unlock()
+	if err == nil {
+		err = w.uploadPending()
+	}
+	if err != nil {
+		return fmt.Errorf("s3: %w", err)
+	}
+	return nil
+}
+
+// LastError returns the most recent background upload error.
+func (w *S3Writer) LastError() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastErr
+}
+
+// Close uploads what is buffered and stops the background uploads. Objects
+// that fail to upload are left in BufferDir.
+func (w *S3Writer) Close() error {
+	w.closeOnce.Do(func() { close(w.stop) })
+	<-w.done
+	return w.Flush()
+}
+
+var _ io.WriteCloser = (*S3Writer)(nil)
@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type s3Upload struct {
+	path, auth, contentType string
+	body                    string
+}
+
+func newS3TestServer(t *testing.T, status int) (*httptest.Server, func() []s3Upload) {
+	var mu sync.Mutex
+	var uploads []s3Upload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("unexpected method %s", r.Method)
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		body, _ := io.ReadAll(zr)
+		mu.Lock()
+		uploads = append(uploads, s3Upload{
+			path:        r.URL.Path,
+			auth:        r.Header.Get("Authorization"),
+			contentType: r.Header.Get("Content-Type"),
+			body:        string(body),
+		})
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []s3Upload {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]s3Upload(nil), uploads...)
+	}
+}
+
+func TestS3Writer_Upload(t *testing.T) {
+	srv, uploads := newS3TestServer(t, http.StatusOK)
+	dir := t.TempDir()
+
+	w, err := NewS3Writer(S3Config{
+		Bucket: "logs", Region: "eu-west-1", Endpoint: srv.URL,
+		Credentials: AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		Interval:    time.Hour, BufferDir: dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte(`{"level":"INFO","service":"billing","message":"charged"}` + "\n"))
+	_, _ = w.Write([]byte(`{"level":"WARN","service":"billing","message":"slow"}` + "\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := uploads()
+	if len(got) != 1 {
+		t.Fatalf("got %d uploads, want 1", len(got))
+	}
+	up := got[0]
+	key := regexp.MustCompile(`^/logs/logs/billing/\d{4}-\d{2}-\d{2}/[0-9a-f-]{36}\.json\.gz$`)
+	if !key.MatchString(up.path) {
+		t.Errorf("unexpected object path %s", up.path)
+	}
+	if !strings.HasPrefix(up.auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(up.auth, "/eu-west-1/s3/aws4_request") {
+		t.Errorf("unexpected Authorization %s", up.auth)
+	}
+	if up.contentType != "application/gzip" {
+		t.Errorf("unexpected Content-Type %s", up.contentType)
+	}
+	if strings.Count(up.body, "\n") != 2 || !strings.Contains(up.body, `"message":"slow"`) {
+		t.Errorf("unexpected object body %q", up.body)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("buffer files left after upload: %v", files)
+	}
+}
+
+func TestS3Writer_MaxSize(t *testing.T) {
+	srv, uploads := newS3TestServer(t, http.StatusOK)
+
+	w, err := NewS3Writer(S3Config{
+		Bucket: "logs", Region: "us-east-1", Endpoint: srv.URL,
+		Credentials: AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		KeyTemplate: "{hostname}/{date}/{hour}/{uuid}.gz",
+		MaxSize:     100, Interval: time.Hour, BufferDir: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := []byte(`{"message":"` + strings.Repeat("x", 80) + `"}` + "\n")
+	for i := 0; i < 4; i++ {
+		_, _ = w.Write(line)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := uploads(); len(got) != 2 {
+		t.Errorf("got %d uploads, want 2", len(got))
+	}
+}
+
+func TestS3Writer_RetriesFailedUploads(t *testing.T) {
+	srv, uploads := newS3TestServer(t, http.StatusServiceUnavailable)
+	dir := t.TempDir()
+
+	w, err := NewS3Writer(S3Config{
+		Bucket: "logs", Region: "us-east-1", Endpoint: srv.URL,
+		Credentials: AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		Interval:    time.Hour, BufferDir: dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte(`{"message":"kept"}` + "\n"))
+	if err := w.Flush(); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("Flush() = %v, want a 503 error", err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Fatalf("got %d buffer files, want the failed object kept", len(files))
+	}
+	_ = w.Flush()
+	if got := uploads(); len(got) != 2 || got[0].path != got[1].path || got[0].body != got[1].body {
+		t.Errorf("want the same object retried, got %v", got)
+	}
+}
+
+func TestNewS3Writer_Validation(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	if _, err := NewS3Writer(S3Config{Region: "us-east-1"}); err == nil {
+		t.Error("expected an error without a bucket")
+	}
+	if _, err := NewS3Writer(S3Config{Bucket: "logs", Region: "us-east-1"}); err == nil {
+		t.Error("expected an error without credentials")
+	}
+}
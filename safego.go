@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RestartPolicy controls whether GoWithRestart restarts a goroutine after it
+// panicked.
+type RestartPolicy struct {
+	// MaxRestarts is the number of restarts after a panic; negative means
+	// unlimited.
+	MaxRestarts int
+	// Backoff is the delay before each restart.
+	Backoff time.Duration
+}
+
+// Go runs fn in a new goroutine. A panic in fn is recovered and logged at
+// ERROR level with its stack trace instead of crashing the process. The
+// returned channel is closed once fn has returned or panicked.
+func Go(ctx context.Context, fn func(ctx context.Context)) <-chan struct{} {
+	return GoWithRestart(ctx, RestartPolicy{}, fn)
+}
+
+// GoWithRestart is like Go, but restarts fn after a panic according to policy
+// until it returns normally, ctx is done or the restarts are used up.
+func GoWithRestart(ctx context.Context, policy RestartPolicy, fn func(ctx context.Context)) <-chan struct{} {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		for attempt := 0; ; attempt++ {
+			if !runRecovered(ctx, attempt, fn) {
+				return
+			}
+			if policy.MaxRestarts >= 0 && attempt >= policy.MaxRestarts {
+				return
+			}
+			if policy.Backoff > 0 {
+				select {
+				case <-time.After(policy.Backoff):
+				case <-ctx.Done():
+					return
+				}
+			} else if ctx.Err() != nil {
+				return
+			}
+			logWithMap(LevelWarn, ctx, map[string]interface{}{
+				"message": "restarting goroutine after panic",
+				"restart": attempt + 1,
+			})
+		}
+	}()
+	return done
+}
+
+// runRecovered runs fn and reports whether it panicked.
+func runRecovered(ctx context.Context, attempt int, fn func(ctx context.Context)) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			logWithMap(LevelError, ctx, map[string]interface{}{
+				"message": fmt.Sprintf("recovered panic in goroutine: %v", r),
+				"panic":   fmt.Sprint(r),
+				"stack":   CaptureStack(1),
+				"attempt": attempt,
+			})
+		}
+	}()
+	fn(ctx)
+	return false
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGo_RecoversPanic(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	<-Go(context.Background(), func(ctx context.Context) { panic("worker exploded") })
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected one panic entry, got %d", len(entries))
+	}
+	if entries[0]["level"] != "ERROR" || entries[0]["panic"] != "worker exploded" {
+		t.Errorf("unexpected panic entry: %v", entries[0])
+	}
+	if stack, _ := entries[0]["stack"].(string); !strings.Contains(stack, "safego_test.go") {
+		t.Errorf("expected stack trace pointing at the panicking function")
+	}
+}
+
+func TestGoWithRestart_StopsAfterMaxRestarts(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	runs := 0
+	<-GoWithRestart(context.Background(), RestartPolicy{MaxRestarts: 2}, func(ctx context.Context) {
+		runs++
+		panic("again")
+	})
+
+	if runs != 3 {
+		t.Errorf("expected initial run plus 2 restarts, got %d runs", runs)
+	}
+}
+
+func TestGoWithRestart_NoRestartOnNormalReturn(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	runs := 0
+	<-GoWithRestart(context.Background(), RestartPolicy{MaxRestarts: -1}, func(ctx context.Context) { runs++ })
+
+	if runs != 1 || buf.Len() != 0 {
+		t.Errorf("expected a single quiet run, got %d runs and %q", runs, buf.String())
+	}
+}
@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// SamplingConfig caps how often the same entry is written, like zap's
+// sampler, so that turning on debug in production doesn't flood the outputs.
+// Entries are counted per level and message in fixed intervals.
+type SamplingConfig struct {
+	// IntervalMS is the counting interval. Defaults to 1000.
+	IntervalMS int `json:"interval_ms" yaml:"interval_ms"`
+	// Levels holds the rule for each sampled level, keyed by level name,
+	// e.g. {"debug": {First: 100, Thereafter: 100}}. Levels without a rule
+	// are not sampled, and fatal entries never are.
+	Levels map[string]SamplingRule `json:"levels" yaml:"levels"`
+}
+
+// SamplingRule writes the first First entries with the same message in each
+// interval and then every Thereafter-th one. A Thereafter of 0 drops the rest
+// of the interval.
+type SamplingRule struct {
+	First      int `json:"first" yaml:"first"`
+	Thereafter int `json:"thereafter" yaml:"thereafter"`
+}
+
+func (sc SamplingConfig) validate() error {
+	var errs []error
+	for name, rule := range sc.Levels {
+		if _, ok := parseLevelName(name); !ok {
+			errs = append(errs, fmt.Errorf("sampling levels: unknown level %q", name))
+		}
+		if rule.First < 0 || rule.Thereafter < 0 {
+			errs = append(errs, fmt.Errorf("sampling rule for %q must not be negative", name))
+		}
+	}
+	if sc.IntervalMS < 0 {
+		errs = append(errs, errors.New("sampling interval_ms must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// samplerBuckets is the number of counters per level. Messages are hashed
+// into them, so two messages occasionally share a counter, as in zap.
+const samplerBuckets = 4096
+
+// sampler decides which entries a SamplingConfig lets through. A nil sampler
+// lets everything through.
+type sampler struct {
+	interval int64 // nanoseconds
+	levels   map[logLevel]*levelSampler
+	dropped  atomic.Uint64
+}
+
+type levelSampler struct {
+	first, thereafter uint64
+	counts            [samplerBuckets]samplerCounter
+}
+
+type samplerCounter struct {
+	resetAt atomic.Int64
+	n       atomic.Uint64
+}
+
+// newSampler returns the sampler for sc, or nil when nothing is sampled.
+func newSampler(sc *SamplingConfig) *sampler {
+	if sc == nil || len(sc.Levels) == 0 {
+		return nil
+	}
+	interval := time.Duration(sc.IntervalMS) * time.Millisecond
+	if interval <= 0 {
+		interval = time.Second
+	}
+	s := &sampler{interval: int64(interval), levels: make(map[logLevel]*levelSampler)}
+	for name, rule := range sc.Levels {
+		level, ok := parseLevelName(name)
+		if !ok || level == LevelFatal {
+			continue
+		}
+		s.levels[level] = &levelSampler{first: uint64(rule.First), thereafter: uint64(rule.Thereafter)}
+	}
+	return s
+}
+
+// allow reports whether an entry with the given level and message is
+// written, counting the ones it drops.
+func (s *sampler) allow(level logLevel, msg string) bool {
+	if s == nil {
+		return true
+	}
+	ls := s.levels[level]
+	if ls == nil {
+		return true
+	}
+	n := ls.counts[fnv32a(msg)%samplerBuckets].incr(time.Now().UnixNano(), s.interval)
+	if n <= ls.first || ls.thereafter > 0 && (n-ls.first)%ls.thereafter == 0 {
+		return true
+	}
+	s.dropped.Add(1)
+	return false
+}
+
+// incr counts an entry at now and returns its number within the current
+// interval, starting a new interval once the previous one has passed.
+func (c *samplerCounter) incr(now, interval int64) uint64 {
+	resetAt := c.resetAt.Load()
+	if resetAt > now {
+		return c.n.Add(1)
+	}
+	c.n.Store(1)
+	if !c.resetAt.CompareAndSwap(resetAt, now+interval) {
+		// Another caller started the interval.
+		return c.n.Add(1)
+	}
+	return 1
+}
+
+func fnv32a(s string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	return h
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSampling_FirstThenEveryNth(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithLevel("debug"), WithFormat("json"), WithOutputs(&out), WithSampling(SamplingConfig{
+		IntervalMS: 60000,
+		Levels:     map[string]SamplingRule{"debug": {First: 3, Thereafter: 5}},
+	}))
+	for i := 0; i < 20; i++ {
+		l.Debug("cache miss")
+		l.Info("request served")
+	}
+	// Entries 1-3, then 8, 13 and 18.
+	if got := strings.Count(out.String(), "cache miss"); got != 6 {
+		t.Errorf("expected 6 sampled debug entries, got %d", got)
+	}
+	if got := strings.Count(out.String(), "request served"); got != 20 {
+		t.Errorf("expected unsampled levels to be kept, got %d", got)
+	}
+	l.Debug("other message")
+	if !strings.Contains(out.String(), "other message") {
+		t.Error("expected messages to be counted separately")
+	}
+	if got := l.load().sampler.dropped.Load(); got != 14 {
+		t.Errorf("expected 14 dropped entries, got %d", got)
+	}
+}
+
+func TestSampling_StructuredAndIntervals(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithLevel("debug"), WithFormat("json"), WithOutputs(&out), WithSampling(SamplingConfig{
+		IntervalMS: 20,
+		Levels:     map[string]SamplingRule{"debug": {First: 1}},
+	}))
+	for i := 0; i < 5; i++ {
+		l.DebugfMap(context.Background(), map[string]interface{}{"message": "poll", "n": i})
+	}
+	if got := strings.Count(out.String(), `"poll"`); got != 1 {
+		t.Errorf("expected 1 entry in the first interval, got %d", got)
+	}
+	time.Sleep(30 * time.Millisecond)
+	l.DebugfMap(context.Background(), map[string]interface{}{"message": "poll"})
+	if got := strings.Count(out.String(), `"poll"`); got != 2 {
+		t.Errorf("expected a new interval to write again, got %d", got)
+	}
+}
+
+func TestSamplingConfig_Validate(t *testing.T) {
+	err := Config{Sampling: &SamplingConfig{IntervalMS: -1, Levels: map[string]SamplingRule{"chatty": {}, "debug": {First: -1}}}}.Validate()
+	for _, want := range []string{`unknown level "chatty"`, `rule for "debug" must not be negative`, "interval_ms must not be negative"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Scrubber rewrites an entry's fields before redaction and encoding. It may
+// change, add or delete fields, and runs for every entry: plain entries are
+// passed as their message field plus the bound and context fields.
+//
+// The map, and any nested values, may be reused once the entry is written,
+// so a Scrubber must not keep them; nested maps belong to the caller, so
+// replace rather than modify them.
+type Scrubber func(level string, fields map[string]interface{})
+
+// HashFields returns a Scrubber replacing the listed fields with a keyed
+// SHA-256 of their value, so entries of the same user can still be
+// correlated without storing the identifier. Keep key secret: without it the
+// hashes of known identifiers can't be recomputed.
+func HashFields(key []byte, fields ...string) Scrubber {
+	return func(_ string, entry map[string]interface{}) {
+		for _, f := range fields {
+			v, ok := entry[f]
+			if !ok || v == nil {
+				continue
+			}
+			mac := hmac.New(sha256.New, key)
+			fmt.Fprint(mac, v)
+			entry[f] = hex.EncodeToString(mac.Sum(nil))
+		}
+	}
+}
+
+// scrubFields runs the scrubbers over a structured entry.
+func scrubFields(scrubbers []Scrubber, level logLevel, fields map[string]interface{}) {
+	for _, s := range scrubbers {
+		s(string(level), fields)
+	}
+}
+
+// scrubPlain runs the scrubbers over a plain entry's message and extra
+// fields, returning the results.
+func scrubPlain(scrubbers []Scrubber, level logLevel, msg string, extra map[string]interface{}) (string, map[string]interface{}) {
+	if len(scrubbers) == 0 {
+		return msg, extra
+	}
+	fields := make(map[string]interface{}, len(extra)+1)
+	for k, v := range extra {
+		fields[k] = v
+	}
+	fields["message"] = msg
+	scrubFields(scrubbers, level, fields)
+	msg, _ = fields["message"].(string)
+	delete(fields, "message")
+	return msg, fields
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestScrubbers_RewriteEntries(t *testing.T) {
+	var out bytes.Buffer
+	dropInternal := func(level string, fields map[string]interface{}) {
+		delete(fields, "internal")
+		if level == "ERROR" {
+			fields["scrubbed"] = true
+		}
+	}
+	l := New(WithFormat("json"), WithOutputs(&out), WithScrubbers(HashFields([]byte("k"), "user_id"), dropInternal))
+	l.ErrorfMap(context.Background(), map[string]interface{}{"message": "payment failed", "user_id": "u-42", "internal": "x"})
+	l.ErrorfMap(context.Background(), map[string]interface{}{"message": "payment failed again", "user_id": "u-42"})
+
+	entries := decodeLogLines(t, &out)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	hash, _ := entries[0]["user_id"].(string)
+	if hash == "u-42" || len(hash) != 64 || entries[1]["user_id"] != hash {
+		t.Errorf("expected the same stable hash for both entries, got %v and %v", entries[0]["user_id"], entries[1]["user_id"])
+	}
+	if _, ok := entries[0]["internal"]; ok || entries[0]["scrubbed"] != true {
+		t.Errorf("unexpected entry %v", entries[0])
+	}
+}
+
+func TestScrubbers_PlainEntries(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithOutputs(&out), WithScrubbers(func(_ string, fields map[string]interface{}) {
+		fields["message"] = strings.ToUpper(fields["message"].(string))
+		delete(fields, "session")
+	}))
+	l.WithField("session", "abc").Info("login ok")
+	if got := out.String(); !strings.Contains(got, "LOGIN OK") || strings.Contains(got, "abc") {
+		t.Errorf("unexpected entry %q", got)
+	}
+}
@@ -0,0 +1,479 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	mrand "math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SentryConfig configures the Sentry sink.
+type SentryConfig struct {
+	// DSN is the project's client key URL,
+	// "https://<key>@o0.ingest.sentry.io/<project>".
+	DSN string
+	// Environment and Release tag every event; Environment defaults to
+	// the entry's environment field. ServerName defaults to os.Hostname.
+	Environment string
+	Release     string
+	ServerName  string
+	// MinLevel is the lowest level forwarded; defaults to ERROR, so ERROR
+	// and FATAL entries are sent.
+	MinLevel logLevel
+	// SampleRate is the fraction of entries sent, in (0, 1]; defaults to 1.
+	SampleRate float64
+	// MaxEventsPerMinute caps the events sent, dropping the rest; defaults
+	// to 60. A negative value removes the cap.
+	MaxEventsPerMinute int
+	// AttachStacktrace captures the logging call's stack for entries that
+	// do not carry a stack field.
+	AttachStacktrace bool
+	// Client sends the events; defaults to a client with a 10 second
+	// timeout.
+	Client *http.Client
+}
+
+// SentryWriter forwards ERROR and FATAL entries to Sentry as events. Pass it
+// to Init as an additional output; other levels are ignored. The message and
+// error fields make up the event title, a stack field (or the captured call
+// stack) its stack trace, and the remaining fields become extras. Events are
+// sampled and rate limited, and Sentry's own rate limits are honoured.
+// Delivery errors are reported by LastError and by the next Write.
+type SentryWriter struct {
+	cfg      SentryConfig
+	endpoint string
+	auth     string
+	dsn      string
+	client   *http.Client
+	batch    *batcher[json.RawMessage]
+
+	mu            sync.Mutex
+	tokens        float64
+	refilled      time.Time
+	disabledUntil time.Time
+
+	dropped atomic.Uint64
+}
+
+// NewSentryWriter validates cfg and returns a sink that can be passed to
+// Init as an additional output.
+func NewSentryWriter(cfg SentryConfig) (*SentryWriter, error) {
+	u, err := url.Parse(cfg.DSN)
+	if err != nil || u.User == nil || u.User.Username() == "" || u.Host == "" {
+		return nil, errors.New("sentry: invalid DSN")
+	}
+	project := strings.Trim(u.Path, "/")
+	prefix := ""
+	if i := strings.LastIndex(project, "/"); i >= 0 {
+		prefix, project = "/"+project[:i], project[i+1:]
+	}
+	if project == "" {
+		return nil, errors.New("sentry: DSN has no project ID")
+	}
+	if cfg.MinLevel == "" {
+		cfg.MinLevel = LevelError
+	}
+	if SeverityNumber(cfg.MinLevel) == 0 {
+		return nil, fmt.Errorf("sentry: unknown level %q", cfg.MinLevel)
+	}
+	if cfg.SampleRate == 0 {
+		cfg.SampleRate = 1
+	}
+	if cfg.SampleRate < 0 || cfg.SampleRate > 1 {
+		return nil, fmt.Errorf("sentry: SampleRate %v is outside (0, 1]", cfg.SampleRate)
+	}
+	if cfg.MaxEventsPerMinute == 0 {
+		cfg.MaxEventsPerMinute = 60
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName, _ = os.Hostname()
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	w := &SentryWriter{
+		cfg:      cfg,
+		endpoint: u.Scheme + "://" + u.Host + prefix + "/api/" + project + "/envelope/",
+		auth:     "Sentry sentry_version=7, sentry_client=go-logger/1.0, sentry_key=" + u.User.Username(),
+		dsn:      cfg.DSN,
+		client:   client,
+		tokens:   float64(cfg.MaxEventsPerMinute),
+		refilled: time.Now(),
+	}
+	w.batch = newBatcher(10, time.Second, w.send)
+	return w, nil
+}
+
+// Write forwards the lines of p at or above MinLevel.
+func (w *SentryWriter) Write(p []byte) (int, error) {
+	var err error
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		fields := sentryFields(line)
+		level := logLevel(fmt.Sprint(fields["level"]))
+		if SeverityNumber(level) < SeverityNumber(w.cfg.MinLevel) {
+			continue
+		}
+		if !w.allow() {
+			w.dropped.Add(1)
+			continue
+		}
+		event, err2 := w.event(level, fields)
+		if err2 == nil {
+			err2 = w.batch.add(event)
+		}
+		if err2 != nil && err == nil {
+			err = err2
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("sentry: %w", err)
+	}
+	return len(p), nil
+}
+
+// sentryFields returns the fields of a JSON entry, or the level and message
+// of a text one.
+func sentryFields(line []byte) map[string]interface{} {
+	var fields map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if len(line) > 1 && line[0] == '{' && dec.Decode(&fields) == nil {
+		if _, ok := fields["level"]; !ok {
+			if v, _, ok := takeFirst(fields, []string{"severity"}); ok {
+				fields["level"] = v
+			} else {
+				fields["level"] = LevelInfo
+			}
+		}
+		return fields
+	}
+	level, _ := syslogEntryMeta(line)
+	return map[string]interface{}{"level": level, "message": string(line)}
+}
+
+// allow applies sampling, Sentry's rate limit responses and the
+// MaxEventsPerMinute token bucket.
+func (w *SentryWriter) allow() bool {
+	if w.cfg.SampleRate < 1 && mrand.Float64() >= w.cfg.SampleRate {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := time.Now()
+	if now.Before(w.disabledUntil) {
+		return false
+	}
+	if w.cfg.MaxEventsPerMinute < 0 {
+		return true
+	}
+	limit := float64(w.cfg.MaxEventsPerMinute)
+	w.tokens += now.Sub(w.refilled).Minutes() * limit
+	if w.tokens > limit {
+		w.tokens = limit
+	}
+	w.refilled = now
+	if w.tokens < 1 {
+		return false
+	}
+	w.tokens--
+	return true
+}
+
+func (w *SentryWriter) event(level logLevel, fields map[string]interface{}) (json.RawMessage, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	ev := map[string]interface{}{
+		"event_id":    hex.EncodeToString(id),
+		"platform":    "go",
+		"level":       sentryLevel(level),
+		"logger":      "go-logger",
+		"server_name": w.cfg.ServerName,
+	}
+	delete(fields, "level")
+
+	at := time.Now()
+	if v, k, ok := takeFirst(fields, passthroughTimestampKeys); ok {
+		if t, ok := parsePassthroughTime(v); ok {
+			at = t
+		} else {
+			fields[k] = v
+		}
+	}
+	ev["timestamp"] = float64(at.UnixNano()) / 1e9
+
+	message := ""
+	if v, _, ok := takeFirst(fields, passthroughMessageKeys); ok {
+		message = fmt.Sprint(v)
+	}
+	errText := ""
+	if v, _, ok := takeFirst(fields, []string{"error"}); ok {
+		errText = fmt.Sprint(v)
+	}
+
+	var frames []StackFrame
+	if v, ok := fields["stack"]; ok {
+		if frames = parseStackField(v); frames != nil {
+			delete(fields, "stack")
+		}
+	} else if w.cfg.AttachStacktrace {
+		frames = sentryCallerFrames()
+	}
+	if frames != nil || errText != "" {
+		exc := map[string]interface{}{"type": message, "value": errText}
+		if errText == "" {
+			exc["value"] = message
+		}
+		if frames != nil {
+			exc["stacktrace"] = map[string]interface{}{"frames": sentryFrames(frames)}
+		}
+		ev["exception"] = map[string]interface{}{"values": []interface{}{exc}}
+	}
+	formatted := message
+	if errText != "" {
+		formatted = strings.TrimPrefix(message+": "+errText, ": ")
+	}
+	ev["message"] = map[string]interface{}{"formatted": formatted}
+
+	tags := map[string]string{}
+	for _, k := range []string{"service", "logger"} {
+		if s, ok := fields[k].(string); ok && s != "" {
+			tags[k] = s
+			delete(fields, k)
+		}
+	}
+	if len(tags) > 0 {
+		ev["tags"] = tags
+	}
+	env := w.cfg.Environment
+	if s, ok := fields["environment"].(string); ok {
+		if env == "" {
+			env = s
+		}
+		delete(fields, "environment")
+	}
+	if env != "" {
+		ev["environment"] = env
+	}
+	if w.cfg.Release != "" {
+		ev["release"] = w.cfg.Release
+	}
+	if fp, ok := fields["fingerprint"].(string); ok {
+		ev["fingerprint"] = []string{fp}
+		delete(fields, "fingerprint")
+	}
+	traceID, _ := fields["trace_id"].(string)
+	spanID, _ := fields["span_id"].(string)
+	if traceID != "" && spanID != "" {
+		ev["contexts"] = map[string]interface{}{"trace": map[string]string{"trace_id": traceID, "span_id": spanID}}
+	}
+	if len(fields) > 0 {
+		ev["extra"] = fields
+	}
+	return json.Marshal(ev)
+}
+
+// sentryLevel maps a level to a Sentry level by its severity number.
+func sentryLevel(level logLevel) string {
+	switch sev := SeverityNumber(level); {
+	case sev >= 21:
+		return "fatal"
+	case sev >= 17:
+		return "error"
+	case sev >= 13:
+		return "warning"
+	case sev >= 9:
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+// parseStackField reads a stack field in any StackFormat.
+func parseStackField(v interface{}) []StackFrame {
+	var frames []StackFrame
+	switch t := v.(type) {
+	case string:
+		lines := strings.Split(strings.TrimSpace(t), "\n")
+		for i := 0; i+1 < len(lines); i += 2 {
+			frames = append(frames, stackFrameAt(strings.TrimSpace(lines[i]), strings.TrimSpace(lines[i+1])))
+		}
+	case []interface{}:
+		for _, e := range t {
+			switch f := e.(type) {
+			case string:
+				fn, loc, _ := strings.Cut(f, " ")
+				frames = append(frames, stackFrameAt(fn, loc))
+			case map[string]interface{}:
+				frame := StackFrame{}
+				frame.Function, _ = f["function"].(string)
+				frame.File, _ = f["file"].(string)
+				if n, ok := f["line"].(json.Number); ok {
+					line, _ := n.Int64()
+					frame.Line = int(line)
+				}
+				frames = append(frames, frame)
+			}
+		}
+	}
+	return frames
+}
+
+// stackFrameAt builds a frame from a function name and a "file:line"
+// location.
+func stackFrameAt(function, location string) StackFrame {
+	frame := StackFrame{Function: function, File: location}
+	if i := strings.LastIndex(location, ":"); i >= 0 {
+		if n, err := strconv.Atoi(location[i+1:]); err == nil {
+			frame.File, frame.Line = location[:i], n
+		}
+	}
+	return frame
+}
+
+// sentryCallerFrames returns the stack of the logging call, outside this
+// package.
+func sentryCallerFrames() []StackFrame {
+	pcs := make([]uintptr, defaultStackFrames+32)
+	n := runtime.Callers(3, pcs)
+	iter := runtime.CallersFrames(pcs[:n])
+	var frames []StackFrame
+	for len(frames) < defaultStackFrames {
+		frame, more := iter.Next()
+		if frame.Function != "" && !isLoggerFrame(frame) {
+			frames = append(frames, StackFrame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		}
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// sentryFrames converts frames, innermost first, to Sentry frames, which are
+// ordered outermost first.
+func sentryFrames(frames []StackFrame) []map[string]interface{} {
+	out := make([]map[string]interface{}, len(frames))
+	for i, f := range frames {
+		module, function := "", f.Function
+		if dot := strings.LastIndex(f.Function, "/"); dot >= 0 {
+			if j := strings.Index(f.Function[dot:], "."); j >= 0 {
+				module, function = f.Function[:dot+j], f.Function[dot+j+1:]
+			}
+		} else if j := strings.Index(f.Function, "."); j >= 0 {
+			module, function = f.Function[:j], f.Function[j+1:]
+		}
+		out[len(frames)-1-i] = map[string]interface{}{
+			"function": function,
+			"module":   module,
+			"abs_path": f.File,
+			"lineno":   f.Line,
+			"in_app":   !strings.HasPrefix(module, "runtime"),
+		}
+	}
+	return out
+}
+
+// send posts each event in its own envelope.
+func (w *SentryWriter) send(events []json.RawMessage) error {
+	var firstErr error
+	for _, ev := range events {
+		if err := w.post(ev); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (w *SentryWriter) post(event json.RawMessage) error {
+	var id struct {
+		EventID string `json:"event_id"`
+	}
+	_ = json.Unmarshal(event, &id)
+	header, _ := json.Marshal(map[string]string{
+		"event_id": id.EventID,
+		"dsn":      w.dsn,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339),
+	})
+	var body bytes.Buffer
+	body.Write(header)
+	fmt.Fprintf(&body, "\n{\"type\":\"event\",\"length\":%d}\n", len(event))
+	body.Write(event)
+	body.WriteByte('\n')
+
+	req, err := http.NewRequest(http.MethodPost, w.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", w.auth)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		wait := time.Minute
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			wait = time.Duration(secs) * time.Second
+		}
+		w.mu.Lock()
+		w.disabledUntil = time.Now().Add(wait)
+		w.mu.Unlock()
+		return fmt.Errorf("rate limited for %s", wait)
+	}
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("event rejected: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Dropped returns the number of events dropped by sampling and rate limits.
+func (w *SentryWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Flush sends the pending events.
+func (w *SentryWriter) Flush() error {
+	if err := w.batch.flush(); err != nil {
+		return fmt.Errorf("sentry: %w", err)
+	}
+	return nil
+}
+
+// LastError returns the most recent asynchronous delivery error.
+func (w *SentryWriter) LastError() error {
+	return w.batch.lastError()
+}
+
+// Close sends the pending events and stops the background sends.
+func (w *SentryWriter) Close() error {
+	if err := w.batch.close(); err != nil {
+		return fmt.Errorf("sentry: %w", err)
+	}
+	return nil
+}
+
+var _ io.WriteCloser = (*SentryWriter)(nil)
@@ -0,0 +1,145 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newSentryTestServer(t *testing.T) (*httptest.Server, func() []map[string]interface{}) {
+	var mu sync.Mutex
+	var events []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/42/envelope/" || !strings.Contains(r.Header.Get("X-Sentry-Auth"), "sentry_key=public") {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Header)
+		}
+		sc := bufio.NewScanner(r.Body)
+		sc.Buffer(nil, 1<<20)
+		var lines []string
+		for sc.Scan() {
+			lines = append(lines, sc.Text())
+		}
+		if len(lines) != 3 || !strings.Contains(lines[1], `"type":"event"`) {
+			t.Errorf("unexpected envelope %q", lines)
+			return
+		}
+		var ev map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[2]), &ev); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		events = append(events, ev)
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []map[string]interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]map[string]interface{}(nil), events...)
+	}
+}
+
+func sentryTestDSN(srv *httptest.Server) string {
+	return strings.Replace(srv.URL, "://", "://public@", 1) + "/42"
+}
+
+func TestSentryWriter_Event(t *testing.T) {
+	srv, events := newSentryTestServer(t)
+	w, err := NewSentryWriter(SentryConfig{DSN: sentryTestDSN(srv), Release: "billing@1.2.3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte(`{"level":"INFO","message":"ignored"}` + "\n"))
+	_, _ = w.Write([]byte(`{"level":"ERROR","message":"charge failed","error":"card declined","service":"billing",` +
+		`"environment":"prod","order":"o-1","stack":"main.charge\n\t/app/main.go:42\nmain.main\n\t/app/main.go:10\n"}` + "\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := events()
+	if len(got) != 1 {
+		t.Fatalf("got %d events, want 1", len(got))
+	}
+	ev := got[0]
+	if ev["level"] != "error" || ev["release"] != "billing@1.2.3" || ev["environment"] != "prod" {
+		t.Errorf("unexpected event %v", ev)
+	}
+	if ev["message"].(map[string]interface{})["formatted"] != "charge failed: card declined" {
+		t.Errorf("unexpected message %v", ev["message"])
+	}
+	if ev["tags"].(map[string]interface{})["service"] != "billing" || ev["extra"].(map[string]interface{})["order"] != "o-1" {
+		t.Errorf("unexpected tags %v or extra %v", ev["tags"], ev["extra"])
+	}
+	exc := ev["exception"].(map[string]interface{})["values"].([]interface{})[0].(map[string]interface{})
+	frames := exc["stacktrace"].(map[string]interface{})["frames"].([]interface{})
+	if exc["value"] != "card declined" || len(frames) != 2 {
+		t.Fatalf("unexpected exception %v", exc)
+	}
+	last := frames[1].(map[string]interface{})
+	if last["function"] != "charge" || last["module"] != "main" || last["lineno"] != 42.0 {
+		t.Errorf("innermost frame should be last, got %v", last)
+	}
+}
+
+func TestSentryWriter_RateLimit(t *testing.T) {
+	srv, events := newSentryTestServer(t)
+	w, err := NewSentryWriter(SentryConfig{DSN: sentryTestDSN(srv), MaxEventsPerMinute: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		_, _ = w.Write([]byte("ERROR: 2025/05/11 main.go:1: storm\n"))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(events()); n != 2 {
+		t.Errorf("got %d events, want 2", n)
+	}
+	if w.Dropped() != 3 {
+		t.Errorf("Dropped() = %d, want 3", w.Dropped())
+	}
+}
+
+func TestSentryWriter_HonoursRetryAfter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	w, err := NewSentryWriter(SentryConfig{DSN: sentryTestDSN(srv)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte(`{"level":"ERROR","message":"first"}` + "\n"))
+	if err := w.Flush(); err == nil {
+		t.Fatal("expected the rate limit error")
+	}
+	_, _ = w.Write([]byte(`{"level":"ERROR","message":"second"}` + "\n"))
+	if w.Dropped() != 1 {
+		t.Errorf("Dropped() = %d, want the entry dropped while rate limited", w.Dropped())
+	}
+	if !w.disabledUntil.After(time.Now().Add(20 * time.Second)) {
+		t.Errorf("unexpected rate limit deadline %v", w.disabledUntil)
+	}
+}
+
+func TestSentryLevel(t *testing.T) {
+	for level, want := range map[logLevel]string{
+		LevelDebug: "debug",
+		LevelWarn:  "warning",
+		LevelError: "error",
+		LevelFatal: "fatal",
+	} {
+		if got := sentryLevel(level); got != want {
+			t.Errorf("sentryLevel(%s) = %s, want %s", level, got, want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package logger
+
+import "sync/atomic"
+
+var severityNumberEnabled atomic.Bool
+
+// EnableSeverityNumber adds a numeric severity_number field next to the level
+// string, using the OpenTelemetry numbering (TRACE=1, DEBUG=5, INFO=9,
+// WARNING=13, ERROR=17, FATAL=21), so backends can run range queries on
+// severity.
+func EnableSeverityNumber(enabled bool) {
+	severityNumberEnabled.Store(enabled)
+}
+
+// SeverityNumber returns the OpenTelemetry severity number of a level,
+// including levels added with RegisterLevel, or 0 for unknown levels.
+func SeverityNumber(level logLevel) int {
+	if sev, ok := builtinSeverity(level); ok {
+		return sev
+	}
+	sev, _ := customSeverity(level)
+	return sev
+}
+
+func addSeverityNumber(level logLevel, fields map[string]interface{}) {
+	if severityNumberEnabled.Load() {
+		fields["severity_number"] = SeverityNumber(level)
+	}
+}
@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSeverityNumber_OptIn(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	Warning("without number")
+	EnableSeverityNumber(true)
+	defer EnableSeverityNumber(false)
+	Warning("with number")
+	ErrorfMap(nil, map[string]interface{}{"event": "failed"})
+
+	entries := decodeLogLines(t, &buf)
+	if _, ok := entries[0]["severity_number"]; ok {
+		t.Errorf("severity_number must be opt-in")
+	}
+	if entries[1]["severity_number"] != float64(13) {
+		t.Errorf("expected WARNING=13, got %v", entries[1]["severity_number"])
+	}
+	if entries[2]["severity_number"] != float64(17) {
+		t.Errorf("expected ERROR=17, got %v", entries[2]["severity_number"])
+	}
+}
@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const shadowQueueSize = 1024
+
+// shadowSinks is attached to the outputs by Init and forwards every entry to
+// the registered shadow sinks.
+var shadowSinks = &shadowSet{}
+
+// ShadowSink is a destination in dry-run mode: it receives a copy of every
+// entry on its own goroutine, and its errors, panics and slowness are recorded
+// in its stats but never affect the other outputs. Entries are dropped (and
+// counted) when the sink falls more than 1024 entries behind.
+type ShadowSink struct {
+	name  string
+	w     io.Writer
+	queue chan []byte
+	done  chan struct{}
+	once  sync.Once
+
+	writes  atomic.Uint64
+	errors  atomic.Uint64
+	dropped atomic.Uint64
+	bytes   atomic.Uint64
+
+	mu          sync.Mutex
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// ShadowStats is a snapshot of a shadow sink's delivery counters.
+type ShadowStats struct {
+	Name        string
+	Writes      uint64
+	Errors      uint64
+	Dropped     uint64
+	Bytes       uint64
+	LastError   string
+	LastErrorAt time.Time
+}
+
+type shadowSet struct {
+	mu    sync.RWMutex
+	sinks []*ShadowSink
+}
+
+// AddShadowSink attaches w as a shadow sink under the given name.
+func AddShadowSink(name string, w io.Writer) *ShadowSink {
+	s := &ShadowSink{
+		name:  name,
+		w:     w,
+		queue: make(chan []byte, shadowQueueSize),
+		done:  make(chan struct{}),
+	}
+	go s.run()
+	registerQueue(s)
+
+	shadowSinks.mu.Lock()
+	shadowSinks.sinks = append(shadowSinks.sinks, s)
+	shadowSinks.mu.Unlock()
+	return s
+}
+
+// ShadowSinks returns the attached shadow sinks.
+func ShadowSinks() []*ShadowSink {
+	shadowSinks.mu.RLock()
+	defer shadowSinks.mu.RUnlock()
+	return append([]*ShadowSink(nil), shadowSinks.sinks...)
+}
+
+// Name returns the name the sink was attached with.
+func (s *ShadowSink) Name() string { return s.name }
+
+// Stats returns the sink's delivery counters.
+func (s *ShadowSink) Stats() ShadowStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ShadowStats{
+		Name:        s.name,
+		Writes:      s.writes.Load(),
+		Errors:      s.errors.Load(),
+		Dropped:     s.dropped.Load(),
+		Bytes:       s.bytes.Load(),
+		LastError:   s.lastError,
+		LastErrorAt: s.lastErrorAt,
+	}
+}
+
+// Remove detaches the sink and waits for its queued entries to be written.
+func (s *ShadowSink) Remove() {
+	s.once.Do(func() {
+		shadowSinks.mu.Lock()
+		for i, other := range shadowSinks.sinks {
+			if other == s {
+				shadowSinks.sinks = append(shadowSinks.sinks[:i:i], shadowSinks.sinks[i+1:]...)
+				break
+			}
+		}
+		close(s.queue)
+		shadowSinks.mu.Unlock()
+		<-s.done
+		unregisterQueue(s)
+		checkPressure()
+	})
+}
+
+func (s *ShadowSink) queueStats() QueueStats {
+	return QueueStats{
+		Name:     "shadow:" + s.name,
+		Depth:    len(s.queue),
+		Capacity: cap(s.queue),
+		Dropped:  s.dropped.Load(),
+	}
+}
+
+func (s *ShadowSink) run() {
+	defer close(s.done)
+	for p := range s.queue {
+		s.deliver(p)
+		checkPressure()
+	}
+}
+
+func (s *ShadowSink) deliver(p []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.recordError(fmt.Errorf("panic: %v", r))
+		}
+	}()
+	n, err := s.w.Write(p)
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		s.recordError(err)
+		return
+	}
+	s.writes.Add(1)
+	s.bytes.Add(uint64(n))
+}
+
+func (s *ShadowSink) recordError(err error) {
+	s.errors.Add(1)
+	s.mu.Lock()
+	s.lastError = err.Error()
+	s.lastErrorAt = time.Now()
+	s.mu.Unlock()
+}
+
+// Write hands a copy of p to every shadow sink without blocking. It never
+// fails.
+func (set *shadowSet) Write(p []byte) (int, error) {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	for _, s := range set.sinks {
+		select {
+		case s.queue <- append([]byte(nil), p...):
+		default:
+			s.dropped.Add(1)
+		}
+	}
+	checkPressure()
+	return len(p), nil
+}
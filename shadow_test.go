@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errors.New("loki unreachable") }
+
+func TestShadowSink_ReceivesEntriesAndIsolatesFailures(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	var shadowBuf bytes.Buffer
+	ok := AddShadowSink("loki-candidate", &lockedWriter{w: &shadowBuf})
+	bad := AddShadowSink("otlp-candidate", failingWriter{})
+
+	InfofMap(nil, map[string]interface{}{"event": "one"})
+	InfofMap(nil, map[string]interface{}{"event": "two"})
+
+	ok.Remove()
+	bad.Remove()
+
+	if n := len(decodeLogLines(t, &buf)); n != 2 {
+		t.Fatalf("primary output must receive every entry, got %d", n)
+	}
+	if n := len(decodeLogLines(t, &shadowBuf)); n != 2 {
+		t.Errorf("shadow sink must receive every entry, got %d", n)
+	}
+
+	if st := ok.Stats(); st.Writes != 2 || st.Errors != 0 {
+		t.Errorf("unexpected healthy shadow stats: %+v", st)
+	}
+	if st := bad.Stats(); st.Errors != 2 || st.LastError != "loki unreachable" {
+		t.Errorf("unexpected failing shadow stats: %+v", st)
+	}
+	if len(ShadowSinks()) != 0 {
+		t.Errorf("removed shadow sinks must be detached")
+	}
+}
+
+func TestShadowSink_IgnoresIndependentLoggers(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	var shadowBuf bytes.Buffer
+	s := AddShadowSink("loki-candidate", &lockedWriter{w: &shadowBuf})
+	KeepRecentEntries(10)
+	defer KeepRecentEntries(0)
+
+	var out bytes.Buffer
+	New(WithFormat("json"), WithOutputs(&out)).Info("separate")
+	Info("package level")
+	s.Remove()
+
+	if n := len(decodeLogLines(t, &out)); n != 1 {
+		t.Fatalf("separate logger must write to its own output, got %d entries", n)
+	}
+	entries := decodeLogLines(t, &shadowBuf)
+	if len(entries) != 1 || entries[0]["message"] != "package level" {
+		t.Errorf("shadow sink must only see the package-level logger, got %v", entries)
+	}
+	if recent := RecentEntries(); len(recent) != 1 || !strings.Contains(recent[0], "package level") {
+		t.Errorf("recent entries must only hold the package-level logger's, got %q", recent)
+	}
+}
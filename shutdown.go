@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultShutdownTimeout bounds Flush and Close unless SetShutdownTimeout
+// changes it.
+const DefaultShutdownTimeout = 5 * time.Second
+
+// ErrShutdownTimeout is returned by Flush and Close when the outputs didn't
+// drain within the shutdown timeout.
+var ErrShutdownTimeout = errors.New("logger: outputs did not drain before the shutdown timeout")
+
+var shutdownTimeout atomic.Int64
+
+func init() {
+	shutdownTimeout.Store(int64(DefaultShutdownTimeout))
+}
+
+// SetShutdownTimeout sets how long Flush and Close wait for the outputs. Zero
+// or negative waits indefinitely.
+func SetShutdownTimeout(d time.Duration) {
+	shutdownTimeout.Store(int64(d))
+}
+
+// Flush drains the package-level logger's buffered outputs. See Logger.Flush.
+func Flush() error { return std.Flush() }
+
+// Close flushes and closes the package-level logger's outputs. See
+// Logger.Close.
+func Close() error { return std.Close() }
+
+// Flush syncs every output that buffers entries, such as the rotating file
+// or writers with a Flush or Sync method.
+func (l *Logger) Flush() error {
+	return l.drain(false)
+}
+
+// Close flushes every output and closes those the logger owns: the Kafka
+// writer, the rotating file and writers passed to WithOwnedOutputs. Standard
+// output and writers passed to WithOutputs are left open. Fatal calls Close
+// before exiting.
+func (l *Logger) Close() error {
+	return l.drain(true)
+}
+
+func (l *Logger) drain(closeSinks bool) error {
+	st := l.load()
+	f := st.fanout
+	if f == nil {
+		return nil
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		if a := st.async; a != nil {
+			if closeSinks {
+				a.close()
+			} else {
+				a.flush()
+			}
+		}
+		var errs []SinkError
+		for _, s := range f.sinks {
+			if err := drainSink(s, closeSinks); err != nil {
+				errs = append(errs, SinkError{Sink: s.name, Err: err})
+			}
+		}
+		if len(errs) > 0 {
+			done <- &FanoutError{Errors: errs}
+			return
+		}
+		done <- nil
+	}()
+
+	timeout := time.Duration(shutdownTimeout.Load())
+	if timeout <= 0 {
+		return <-done
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return ErrShutdownTimeout
+	}
+}
+
+func drainSink(s *sinkWriter, closeSink bool) error {
+	var err error
+	switch w := s.w.(type) {
+	case interface{ Flush() error }:
+		err = w.Flush()
+	case interface{ Sync() error }:
+		// Terminals and pipes reject fsync.
+		if w != os.Stdout && w != os.Stderr {
+			err = w.Sync()
+		}
+	}
+	if closeSink && s.owned {
+		if c, ok := s.w.(io.Closer); ok {
+			err = errors.Join(err, c.Close())
+		}
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+type bufferedSink struct {
+	bytes.Buffer
+	flushes, closes int
+	block           chan struct{}
+}
+
+func (b *bufferedSink) Flush() error {
+	if b.block != nil {
+		<-b.block
+	}
+	b.flushes++
+	return nil
+}
+
+func (b *bufferedSink) Close() error {
+	b.closes++
+	return errors.New("already closed")
+}
+
+func TestFlushAndClose(t *testing.T) {
+	sink := &bufferedSink{}
+	InitWithOptions(WithLevel("debug"), WithFormat("json"), WithOwnedOutputs(sink))
+
+	if err := Flush(); err != nil {
+		t.Fatalf("unexpected flush error: %v", err)
+	}
+	if sink.flushes != 1 || sink.closes != 0 {
+		t.Errorf("expected Flush to flush without closing, got %d/%d", sink.flushes, sink.closes)
+	}
+
+	err := Close()
+	var fe *FanoutError
+	if !errors.As(err, &fe) || fe.Errors[0].Sink != "custom-0" {
+		t.Errorf("expected close error attributed to the sink, got %v", err)
+	}
+	if sink.flushes != 2 || sink.closes != 1 {
+		t.Errorf("expected Close to flush and close, got %d/%d", sink.flushes, sink.closes)
+	}
+}
+
+func TestFlush_Timeout(t *testing.T) {
+	sink := &bufferedSink{block: make(chan struct{})}
+	defer close(sink.block)
+	Init("debug", "json", "", "", false, false, false, nil, nil, sink)
+	SetShutdownTimeout(20 * time.Millisecond)
+	defer SetShutdownTimeout(DefaultShutdownTimeout)
+
+	if err := Flush(); !errors.Is(err, ErrShutdownTimeout) {
+		t.Errorf("expected ErrShutdownTimeout, got %v", err)
+	}
+}
+
+func TestFatal_ClosesOutputs(t *testing.T) {
+	sink := &bufferedSink{}
+	l := New(WithFormat("json"), WithOwnedOutputs(sink))
+	code := stubExit(t)
+
+	l.Fatal("shutting down")
+
+	if *code != 1 || sink.closes != 1 {
+		t.Errorf("expected Fatal to close outputs before exiting, got exit=%d closes=%d", *code, sink.closes)
+	}
+}
+
+func TestClose_LeavesCallerOutputsOpen(t *testing.T) {
+	sink := &bufferedSink{}
+	InitWithOptions(WithFormat("json"), WithOutputs(os.Stderr, sink))
+	Info("before close")
+
+	if err := Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+	if sink.flushes != 1 || sink.closes != 0 {
+		t.Errorf("expected Close to flush a caller's output without closing it, got %d/%d", sink.flushes, sink.closes)
+	}
+	if _, err := os.Stderr.Write(nil); err != nil {
+		t.Errorf("stderr closed by the logger: %v", err)
+	}
+}
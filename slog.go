@@ -0,0 +1,145 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// SlogHandler returns a slog.Handler that writes through the package-level
+// logger, so code and libraries using log/slog share its outputs, level and
+// enrichment:
+//
+//	slog.SetDefault(slog.New(logger.SlogHandler()))
+func SlogHandler() slog.Handler {
+	return std.SlogHandler()
+}
+
+// SlogHandler returns a slog.Handler that writes through l. slog levels map
+// to DEBUG (and TRACE below slog.LevelDebug), INFO, WARNING and ERROR;
+// attributes become fields and groups become nested objects. The record's
+// message and time are kept, and its context is used for correlation fields.
+func (l *Logger) SlogHandler() slog.Handler {
+	return &slogHandler{l: l}
+}
+
+type slogAttrs struct {
+	groups []string
+	attrs  []slog.Attr
+}
+
+type slogHandler struct {
+	l      *Logger
+	groups []string
+	preset []slogAttrs
+}
+
+var _ slog.Handler = (*slogHandler)(nil)
+
+func slogLevel(level slog.Level) logLevel {
+	switch {
+	case level < slog.LevelDebug:
+		return LevelTrace
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarn
+	default:
+		return LevelError
+	}
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.l.shouldLogCtx(ctx, slogLevel(level))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make(map[string]interface{}, r.NumAttrs()+8)
+	for _, p := range h.preset {
+		addSlogAttrs(slogGroup(fields, p.groups), p.attrs)
+	}
+	target := slogGroup(fields, h.groups)
+	r.Attrs(func(a slog.Attr) bool {
+		addSlogAttr(target, a)
+		return true
+	})
+	fields["message"] = r.Message
+	if r.PC != 0 && h.l.load().caller {
+		// The record knows its call site; the stack here is slog's.
+		addCallerPC(fields, r.PC)
+	}
+
+	at := r.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	// A slog error must never terminate the process, so FATAL isn't used.
+	h.l.logWithMapAt(slogLevel(r.Level), ctx, fields, at)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	next := *h
+	next.preset = append(append([]slogAttrs(nil), h.preset...), slogAttrs{groups: h.groups, attrs: attrs})
+	return &next
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	next := *h
+	next.groups = append(append([]string(nil), h.groups...), name)
+	return &next
+}
+
+// slogGroup returns the nested map for the group path, creating it as needed.
+func slogGroup(fields map[string]interface{}, groups []string) map[string]interface{} {
+	for _, g := range groups {
+		nested, ok := fields[g].(map[string]interface{})
+		if !ok {
+			nested = make(map[string]interface{})
+			fields[g] = nested
+		}
+		fields = nested
+	}
+	return fields
+}
+
+func addSlogAttrs(fields map[string]interface{}, attrs []slog.Attr) {
+	for _, a := range attrs {
+		addSlogAttr(fields, a)
+	}
+}
+
+func addSlogAttr(fields map[string]interface{}, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		if len(group) == 0 {
+			return
+		}
+		if a.Key == "" {
+			addSlogAttrs(fields, group)
+			return
+		}
+		addSlogAttrs(slogGroup(fields, []string{a.Key}), group)
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	v := a.Value.Any()
+	if err, ok := v.(error); ok {
+		v = err.Error()
+	}
+	fields[a.Key] = v
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	log := slog.New(SlogHandler()).With("component", "cache").WithGroup("req")
+	ctx := context.WithValue(context.Background(), "trace_id", "t-7")
+	log.InfoContext(ctx, "cache miss", "key", "user:1", slog.Group("timing", "ms", 12))
+	log.Debug("verbose", "n", 1)
+	log.Log(ctx, slog.LevelDebug-4, "trace filtered at debug")
+	slog.New(SlogHandler()).Error("write failed", "error", errors.New("disk full"), "", "ignored")
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %v", entries)
+	}
+	e := entries[0]
+	req, _ := e["req"].(map[string]interface{})
+	timing, _ := req["timing"].(map[string]interface{})
+	if e["message"] != "cache miss" || e["level"] != "INFO" || e["component"] != "cache" || e["trace_id"] != "t-7" {
+		t.Errorf("unexpected entry: %v", e)
+	}
+	if req["key"] != "user:1" || timing["ms"] != float64(12) {
+		t.Errorf("expected grouped attributes, got %v", e["req"])
+	}
+	if _, err := time.Parse(time.RFC3339, e["timestamp"].(string)); err != nil {
+		t.Errorf("unexpected timestamp %v", e["timestamp"])
+	}
+	if entries[1]["level"] != "DEBUG" {
+		t.Errorf("expected DEBUG, got %v", entries[1])
+	}
+	if e := entries[2]; e["level"] != "ERROR" || e["error"] != "disk full" {
+		t.Errorf("expected error attribute as message, got %v", e)
+	}
+}
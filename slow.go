@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"context"
+	"time"
+)
+
+// WarnIfSlow starts timing the named operation and returns a function to be
+// called with the operation's result. That function logs a WARNING entry when
+// the operation took longer than threshold, an ERROR entry when err is
+// non-nil, and nothing otherwise:
+//
+//	done := logger.WarnIfSlow(ctx, "db.query", 200*time.Millisecond)
+//	rows, err := db.QueryContext(ctx, q)
+//	done(err)
+func WarnIfSlow(ctx context.Context, name string, threshold time.Duration) func(err error) {
+	started := time.Now()
+	return func(err error) {
+		logIfSlow(ctx, name, threshold, time.Since(started), err)
+	}
+}
+
+// TimeIfSlow runs fn and reports it like WarnIfSlow, returning fn's error.
+func TimeIfSlow(ctx context.Context, name string, threshold time.Duration, fn func() error) error {
+	done := WarnIfSlow(ctx, name, threshold)
+	err := fn()
+	done(err)
+	return err
+}
+
+func logIfSlow(ctx context.Context, name string, threshold, elapsed time.Duration, err error) {
+	if err == nil && elapsed <= threshold {
+		return
+	}
+
+	fields := map[string]interface{}{
+		"operation":    name,
+		"duration_ms":  elapsed.Milliseconds(),
+		"threshold_ms": threshold.Milliseconds(),
+	}
+
+	level := LevelWarn
+	if err != nil {
+		level = LevelError
+		fields["message"] = name + " failed"
+		fields["error"] = err.Error()
+	} else {
+		fields["message"] = name + " exceeded latency threshold"
+	}
+	if elapsed > threshold {
+		fields["slow"] = true
+	}
+
+	logWithMap(level, ctx, fields)
+}
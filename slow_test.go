@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWarnIfSlow_QuietWhenFast(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	WarnIfSlow(context.Background(), "db.query", time.Hour)(nil)
+	if buf.Len() != 0 {
+		t.Fatalf("fast successful operation must not log, got %s", buf.String())
+	}
+}
+
+func TestWarnIfSlow_SlowAndFailed(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	logIfSlow(context.Background(), "db.query", 10*time.Millisecond, 50*time.Millisecond, nil)
+	err := TimeIfSlow(context.Background(), "db.exec", time.Hour, func() error { return errors.New("timeout") })
+	if err == nil || err.Error() != "timeout" {
+		t.Fatalf("expected TimeIfSlow to return fn's error, got %v", err)
+	}
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected two entries, got %d", len(entries))
+	}
+	if entries[0]["level"] != "WARNING" || entries[0]["slow"] != true || entries[0]["duration_ms"] != float64(50) {
+		t.Errorf("unexpected slow entry: %v", entries[0])
+	}
+	if entries[1]["level"] != "ERROR" || entries[1]["error"] != "timeout" {
+		t.Errorf("unexpected failure entry: %v", entries[1])
+	}
+}
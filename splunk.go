@@ -0,0 +1,303 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SplunkConfig configures the Splunk HTTP Event Collector sink.
+type SplunkConfig struct {
+	// URL is the HEC base URL ("https://splunk:8088").
+	URL string
+	// Token is the HEC token.
+	Token string
+	// Index, Source and SourceType are set on every event; SourceType
+	// defaults to "_json". Empty values use the token's defaults.
+	Index      string
+	Source     string
+	SourceType string
+	// Host is reported as the event host; defaults to os.Hostname.
+	Host string
+	// DisableCompression sends batches without gzip.
+	DisableCompression bool
+	// UseAck enables indexer acknowledgment: every batch is polled until
+	// Splunk confirms it was indexed, or AckTimeout (default 30 seconds)
+	// passes. The token must have acknowledgment enabled.
+	UseAck          bool
+	AckTimeout      time.Duration
+	AckPollInterval time.Duration
+	// BatchSize and BatchInterval control batching; defaults are 500 entries
+	// and 1 second.
+	BatchSize     int
+	BatchInterval time.Duration
+	// Client sends the requests; defaults to a client with a 30 second
+	// timeout.
+	Client *http.Client
+}
+
+// ErrSplunkNotAcknowledged is returned when Splunk didn't acknowledge a
+// batch within the acknowledgment timeout.
+var ErrSplunkNotAcknowledged = errors.New("splunk: batch not acknowledged")
+
+// SplunkWriter sends entries to a Splunk HTTP Event Collector in batches.
+// Writes do not block on the network; delivery errors are reported by
+// LastError and by the next Write.
+type SplunkWriter struct {
+	cfg      SplunkConfig
+	eventURL string
+	ackURL   string
+	channel  string
+	client   *http.Client
+	batch    *batcher[[]byte]
+}
+
+// NewSplunkWriter validates cfg and returns a sink that can be passed to
+// Init as an additional output.
+func NewSplunkWriter(cfg SplunkConfig) (*SplunkWriter, error) {
+	if cfg.URL == "" || cfg.Token == "" {
+		return nil, errors.New("splunk: URL and Token are required")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("splunk: invalid URL %q", cfg.URL)
+	}
+	base := strings.TrimSuffix(u.String(), "/")
+	base = strings.TrimSuffix(base, "/services/collector/event")
+
+	if cfg.SourceType == "" {
+		cfg.SourceType = "_json"
+	}
+	if cfg.Host == "" {
+		cfg.Host, _ = os.Hostname()
+	}
+	if cfg.AckTimeout <= 0 {
+		cfg.AckTimeout = 30 * time.Second
+	}
+	if cfg.AckPollInterval <= 0 {
+		cfg.AckPollInterval = time.Second
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	interval := cfg.BatchInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	w := &SplunkWriter{
+		cfg:      cfg,
+		eventURL: base + "/services/collector/event",
+		ackURL:   base + "/services/collector/ack",
+		client:   client,
+	}
+	if cfg.UseAck {
+		if w.channel, err = newChannelID(); err != nil {
+			return nil, fmt.Errorf("splunk: %w", err)
+		}
+	}
+	w.batch = newBatcher(batchSize, interval, w.send)
+	return w, nil
+}
+
+// newChannelID returns a random UUID for the X-Splunk-Request-Channel header.
+func newChannelID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// Write queues each line of p as one event.
+func (w *SplunkWriter) Write(p []byte) (int, error) {
+	var err error
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		event, err2 := w.event(line, time.Now())
+		if err2 == nil {
+			err2 = w.batch.add(event)
+		}
+		if err2 != nil && err == nil {
+			err = err2
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("splunk: %w", err)
+	}
+	return len(p), nil
+}
+
+// event wraps line in the HEC event envelope. JSON entries are sent as
+// structured events, other lines as raw strings.
+func (w *SplunkWriter) event(line []byte, now time.Time) ([]byte, error) {
+	var event interface{} = string(line)
+	at := now
+	var fields map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if len(line) > 1 && line[0] == '{' && dec.Decode(&fields) == nil {
+		event = json.RawMessage(line)
+		if v, ok := fields["timestamp"]; ok {
+			if t, ok := parsePassthroughTime(v); ok {
+				at = t
+			}
+		}
+	}
+
+	envelope := map[string]interface{}{
+		"time":       math.Round(float64(at.UnixNano())/1e6) / 1e3,
+		"host":       w.cfg.Host,
+		"sourcetype": w.cfg.SourceType,
+		"event":      event,
+	}
+	if w.cfg.Index != "" {
+		envelope["index"] = w.cfg.Index
+	}
+	if w.cfg.Source != "" {
+		envelope["source"] = w.cfg.Source
+	}
+	return json.Marshal(envelope)
+}
+
+func (w *SplunkWriter) newRequest(u string, body []byte, compress bool) (*http.Request, error) {
+	if compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write(body)
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		body = buf.Bytes()
+	}
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Splunk "+w.cfg.Token)
+	req.Header.Set("Content-Type", "application/json")
+	if compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if w.channel != "" {
+		req.Header.Set("X-Splunk-Request-Channel", w.channel)
+	}
+	return req, nil
+}
+
+type splunkResponse struct {
+	Text  string `json:"text"`
+	Code  int    `json:"code"`
+	AckID *int64 `json:"ackId"`
+}
+
+// send posts events as one batch and, with UseAck, waits for Splunk to
+// acknowledge it.
+func (w *SplunkWriter) send(events [][]byte) error {
+	req, err := w.newRequest(w.eventURL, bytes.Join(events, []byte{'\n'}), !w.cfg.DisableCompression)
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result splunkResponse
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result)
+	if resp.StatusCode/100 != 2 || result.Code != 0 {
+		return fmt.Errorf("batch rejected: %s: %s (code %d)", resp.Status, result.Text, result.Code)
+	}
+	if !w.cfg.UseAck {
+		return nil
+	}
+	if result.AckID == nil {
+		return errors.New("acknowledgment requested but not enabled for the token")
+	}
+	return w.waitForAck(*result.AckID)
+}
+
+func (w *SplunkWriter) waitForAck(id int64) error {
+	body, _ := json.Marshal(map[string][]int64{"acks": {id}})
+	deadline := time.Now().Add(w.cfg.AckTimeout)
+	for {
+		acked, err := w.pollAck(id, body)
+		if err != nil {
+			return err
+		}
+		if acked {
+			return nil
+		}
+		if time.Now().Add(w.cfg.AckPollInterval).After(deadline) {
+			return fmt.Errorf("%w: ack %d after %s", ErrSplunkNotAcknowledged, id, w.cfg.AckTimeout)
+		}
+		time.Sleep(w.cfg.AckPollInterval)
+	}
+}
+
+func (w *SplunkWriter) pollAck(id int64, body []byte) (bool, error) {
+	req, err := w.newRequest(w.ackURL, body, false)
+	if err != nil {
+		return false, err
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return false, fmt.Errorf("ack poll rejected: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	var result struct {
+		Acks map[string]bool `json:"acks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("decoding ack response: %w", err)
+	}
+	return result.Acks[strconv.FormatInt(id, 10)], nil
+}
+
+// Flush sends the pending entries.
+func (w *SplunkWriter) Flush() error {
+	if err := w.batch.flush(); err != nil {
+		return fmt.Errorf("splunk: %w", err)
+	}
+	return nil
+}
+
+// LastError returns the most recent asynchronous delivery error.
+func (w *SplunkWriter) LastError() error {
+	return w.batch.lastError()
+}
+
+// Close sends the pending entries and stops the background sends.
+func (w *SplunkWriter) Close() error {
+	if err := w.batch.close(); err != nil {
+		return fmt.Errorf("splunk: %w", err)
+	}
+	return nil
+}
+
+var _ io.WriteCloser = (*SplunkWriter)(nil)
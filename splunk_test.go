@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSplunkWriter_GzipBatchWithAck(t *testing.T) {
+	var polls atomic.Int32
+	events := make(chan []map[string]interface{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Splunk tok" || r.Header.Get("X-Splunk-Request-Channel") == "" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		switch r.URL.Path {
+		case "/services/collector/event":
+			if r.Header.Get("Content-Encoding") != "gzip" {
+				t.Errorf("expected a gzip batch")
+			}
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var batch []map[string]interface{}
+			sc := bufio.NewScanner(zr)
+			for sc.Scan() {
+				var e map[string]interface{}
+				_ = json.Unmarshal(sc.Bytes(), &e)
+				batch = append(batch, e)
+			}
+			events <- batch
+			_, _ = w.Write([]byte(`{"text":"Success","code":0,"ackId":7}`))
+		case "/services/collector/ack":
+			acked := polls.Add(1) > 1
+			_, _ = w.Write([]byte(`{"acks":{"7":` + map[bool]string{true: "true", false: "false"}[acked] + `}}`))
+		}
+	}))
+	defer srv.Close()
+
+	w, err := NewSplunkWriter(SplunkConfig{
+		URL: srv.URL, Token: "tok", Index: "app", Host: "node-1",
+		UseAck: true, AckPollInterval: time.Millisecond, BatchInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte(`{"timestamp":"2025-05-11T19:30:12Z","level":"INFO","message":"a"}` + "\n"))
+	_, _ = w.Write([]byte("WARNING: plain line\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	batch := <-events
+	if len(batch) != 2 {
+		t.Fatalf("unexpected batch %v", batch)
+	}
+	if batch[0]["index"] != "app" || batch[0]["host"] != "node-1" || batch[0]["sourcetype"] != "_json" || batch[0]["time"] != float64(1746991812) {
+		t.Errorf("unexpected envelope %v", batch[0])
+	}
+	if e, _ := batch[0]["event"].(map[string]interface{}); e["message"] != "a" {
+		t.Errorf("expected a structured event, got %v", batch[0]["event"])
+	}
+	if batch[1]["event"] != "WARNING: plain line" {
+		t.Errorf("expected a raw event, got %v", batch[1]["event"])
+	}
+	if polls.Load() != 2 {
+		t.Errorf("expected polling until acknowledged, got %d polls", polls.Load())
+	}
+}
+
+func TestSplunkWriter_AckTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/services/collector/ack" {
+			_, _ = w.Write([]byte(`{"acks":{"1":false}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"text":"Success","code":0,"ackId":1}`))
+	}))
+	defer srv.Close()
+
+	w, err := NewSplunkWriter(SplunkConfig{
+		URL: srv.URL, Token: "tok", UseAck: true, DisableCompression: true,
+		AckTimeout: 20 * time.Millisecond, AckPollInterval: 5 * time.Millisecond, BatchInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte(`{"message":"a"}` + "\n"))
+	if err := w.Flush(); !errors.Is(err, ErrSplunkNotAcknowledged) {
+		t.Errorf("expected ErrSplunkNotAcknowledged, got %v", err)
+	}
+}
+
+func TestSplunkWriter_RejectedToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"text":"Invalid token","code":4}`))
+	}))
+	defer srv.Close()
+
+	w, err := NewSplunkWriter(SplunkConfig{URL: srv.URL, Token: "bad", BatchInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte(`{"message":"a"}` + "\n"))
+	if err := w.Flush(); err == nil {
+		t.Errorf("expected the rejected batch to be reported")
+	}
+	if _, err := NewSplunkWriter(SplunkConfig{URL: srv.URL}); err == nil {
+		t.Errorf("expected an error without a token")
+	}
+}
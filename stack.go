@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// StackFormat selects how stack traces are encoded in entries.
+type StackFormat int
+
+const (
+	// StackString encodes the trace as a single string with one
+	// "function\n\tfile:line" pair per frame, like runtime/debug.Stack.
+	StackString StackFormat = iota
+	// StackFrames encodes the trace as an array of "function file:line"
+	// strings.
+	StackFrames
+	// StackObjects encodes the trace as an array of {function, file, line}
+	// objects.
+	StackObjects
+)
+
+// StackOptions configures stack trace representation.
+type StackOptions struct {
+	Format StackFormat
+	// MaxFrames caps the number of frames; zero means 32.
+	MaxFrames int
+	// TrimPrefixes are removed from file paths, e.g. the module root.
+	TrimPrefixes []string
+	// ShortPaths keeps only the last directory and the file name.
+	ShortPaths bool
+}
+
+// StackFrame is one frame of a StackObjects trace.
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+const defaultStackFrames = 32
+
+var (
+	stackOptionsMu sync.RWMutex
+	stackOptions   = StackOptions{}
+)
+
+// SetStackOptions sets how stack traces logged by this package (recovered
+// panics, crash entries) and CaptureStack are encoded.
+func SetStackOptions(opts StackOptions) {
+	stackOptionsMu.Lock()
+	defer stackOptionsMu.Unlock()
+	stackOptions = opts
+}
+
+// CaptureStack returns the current goroutine's stack in the configured
+// representation, ready to be stored in a field. skip=0 starts at the caller
+// of CaptureStack. Frames of the runtime and of this package are omitted.
+func CaptureStack(skip int) interface{} {
+	stackOptionsMu.RLock()
+	opts := stackOptions
+	stackOptionsMu.RUnlock()
+
+	max := opts.MaxFrames
+	if max <= 0 {
+		max = defaultStackFrames
+	}
+
+	pcs := make([]uintptr, max+32)
+	n := runtime.Callers(skip+2, pcs)
+	iter := runtime.CallersFrames(pcs[:n])
+
+	frames := make([]StackFrame, 0, max)
+	for len(frames) < max {
+		frame, more := iter.Next()
+		if frame.Function != "" && !isLoggerFrame(frame) {
+			frames = append(frames, StackFrame{
+				Function: frame.Function,
+				File:     trimStackPath(frame.File, opts),
+				Line:     frame.Line,
+			})
+		}
+		if !more {
+			break
+		}
+	}
+
+	switch opts.Format {
+	case StackFrames:
+		out := make([]string, len(frames))
+		for i, f := range frames {
+			out[i] = fmt.Sprintf("%s %s:%d", f.Function, f.File, f.Line)
+		}
+		return out
+	case StackObjects:
+		return frames
+	default:
+		var b strings.Builder
+		for _, f := range frames {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		}
+		return b.String()
+	}
+}
+
+func trimStackPath(file string, opts StackOptions) string {
+	for _, prefix := range opts.TrimPrefixes {
+		if strings.HasPrefix(file, prefix) {
+			file = strings.TrimPrefix(strings.TrimPrefix(file, prefix), "/")
+			break
+		}
+	}
+	if opts.ShortPaths {
+		dir, name := filepath.Split(file)
+		file = filepath.Join(filepath.Base(dir), name)
+	}
+	return file
+}
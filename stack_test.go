@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCaptureStack_Formats(t *testing.T) {
+	defer SetStackOptions(StackOptions{})
+
+	s, ok := CaptureStack(0).(string)
+	if !ok || !strings.Contains(s, "TestCaptureStack_Formats") || !strings.Contains(s, "stack_test.go:") {
+		t.Errorf("unexpected string stack: %v", s)
+	}
+
+	_, file, _, _ := runtime.Caller(0)
+	dir := filepath.Dir(file)
+	SetStackOptions(StackOptions{Format: StackFrames, MaxFrames: 1, ShortPaths: true})
+	frames, ok := CaptureStack(0).([]string)
+	if !ok || len(frames) != 1 || !strings.Contains(frames[0], " "+filepath.Base(dir)+"/stack_test.go:") {
+		t.Errorf("unexpected frame list: %v", frames)
+	}
+
+	SetStackOptions(StackOptions{Format: StackObjects, TrimPrefixes: []string{"/nonexistent"}})
+	objects, ok := CaptureStack(0).([]StackFrame)
+	if !ok || len(objects) == 0 || !strings.HasSuffix(objects[0].Function, "TestCaptureStack_Formats") || objects[0].Line == 0 {
+		t.Errorf("unexpected frame objects: %+v", objects)
+	}
+}
+
+func TestTrimStackPath(t *testing.T) {
+	opts := StackOptions{TrimPrefixes: []string{"/home/ci/src"}}
+	if got := trimStackPath("/home/ci/src/app/main.go", opts); got != "app/main.go" {
+		t.Errorf("unexpected trimmed path %q", got)
+	}
+}
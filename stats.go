@@ -0,0 +1,52 @@
+package logger
+
+import "expvar"
+
+// Stats is a snapshot of a logger's activity, for environments without
+// Prometheus.
+type Stats struct {
+	// Entries counts the entries written, by level.
+	Entries map[string]uint64
+	// Sinks are the delivery counters of every output.
+	Sinks []SinkStats
+	// Queues are the internal queues, which are process-wide.
+	Queues []QueueStats
+	// Sampled and Throttled count the entries left out by sampling and the
+	// repeats suppressed by throttling.
+	Sampled   uint64
+	Throttled uint64
+}
+
+// GetStats returns the activity of the package-level logger.
+func GetStats() Stats {
+	return std.Stats()
+}
+
+// Stats returns l's activity. The counters cover l and its children.
+func (l *Logger) Stats() Stats {
+	root := l.root()
+	s := Stats{Entries: map[string]uint64{}, Sinks: root.SinkStatistics(), Queues: Queues()}
+	for level, n := range root.written.snapshot() {
+		s.Entries[string(level)] = n
+	}
+	st := root.load()
+	if st.sampler != nil {
+		s.Sampled = st.sampler.dropped.Load()
+	}
+	if st.throttle != nil {
+		s.Throttled = st.throttle.suppressed.Load()
+	}
+	return s
+}
+
+// PublishExpvar publishes the package-level logger's Stats as the expvar
+// variable name, served as JSON on /debug/vars by the expvar package. Like
+// expvar.Publish, it panics if name is already in use.
+func PublishExpvar(name string) {
+	std.PublishExpvar(name)
+}
+
+// PublishExpvar publishes l's Stats as the expvar variable name.
+func (l *Logger) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} { return l.Stats() }))
+}
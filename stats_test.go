@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithFormat("json"), WithOutputs(&out, failingWriter{}))
+	l.Info("first")
+	l.Info("second")
+	l.Error("third")
+
+	s := l.Stats()
+	if s.Entries["INFO"] != 2 || s.Entries["ERROR"] != 1 {
+		t.Errorf("Entries = %v, want INFO=2 ERROR=1", s.Entries)
+	}
+	if len(s.Sinks) != 2 {
+		t.Fatalf("Sinks = %+v, want 2 outputs", s.Sinks)
+	}
+	if s.Sinks[0].Writes != 3 || s.Sinks[0].Errors != 0 {
+		t.Errorf("working output = %+v, want 3 writes", s.Sinks[0])
+	}
+	if s.Sinks[1].Writes != 0 || s.Sinks[1].Errors != 3 {
+		t.Errorf("failing output = %+v, want 3 errors", s.Sinks[1])
+	}
+	if child := l.With(map[string]interface{}{"k": "v"}); child.Stats().Entries["INFO"] != 2 {
+		t.Errorf("child Stats = %v, want the root's counters", child.Stats().Entries)
+	}
+}
+
+// expvarRuns gives each run of TestPublishExpvar its own name, since
+// expvar.Publish panics on duplicates and -count reruns tests in one process.
+var expvarRuns atomic.Int64
+
+func TestPublishExpvar(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithFormat("json"), WithOutputs(&out))
+	name := fmt.Sprintf("logger_test_stats_%d", expvarRuns.Add(1))
+	l.PublishExpvar(name)
+	l.Warning("published")
+
+	v := expvar.Get(name)
+	if v == nil {
+		t.Fatal("variable not published")
+	}
+	var got Stats
+	if err := json.Unmarshal([]byte(v.String()), &got); err != nil {
+		t.Fatalf("expvar value %s: %v", v.String(), err)
+	}
+	if got.Entries["WARNING"] != 1 || len(got.Sinks) != 1 || got.Sinks[0].Writes != 1 {
+		t.Errorf("expvar value = %+v, want 1 warning written to 1 output", got)
+	}
+}
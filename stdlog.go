@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"log"
+	"strings"
+)
+
+// RedirectStdLog points the global log package at this logger: every line
+// written through log.Print*, log.Fatal* or log.Panic* becomes an entry at the
+// given level (WARNING when omitted) in the configured format, so stray
+// output from dependencies is captured instead of going to stderr. It returns
+// a function that restores the previous output, flags and prefix.
+func RedirectStdLog(level ...logLevel) (restore func()) {
+	lvl := LevelWarn
+	if len(level) > 0 {
+		lvl = level[0]
+	}
+	prevOut, prevFlags, prevPrefix := log.Writer(), log.Flags(), log.Prefix()
+
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(&stdLogWriter{l: std, level: lvl})
+
+	return func() {
+		log.SetOutput(prevOut)
+		log.SetFlags(prevFlags)
+		log.SetPrefix(prevPrefix)
+	}
+}
+
+// StdLogger returns a *log.Logger whose output becomes entries of the
+// package-level logger at level, for libraries that take a *log.Logger such
+// as http.Server.ErrorLog.
+func StdLogger(level logLevel) *log.Logger {
+	return std.StdLogger(level)
+}
+
+// StdLogger returns a *log.Logger whose output becomes entries of l at level.
+// Fields bound with With are attached to every entry.
+//
+//	srv := &http.Server{ErrorLog: logger.Named("http").StdLogger(logger.LevelWarn)}
+func (l *Logger) StdLogger(level logLevel) *log.Logger {
+	return log.New(&stdLogWriter{l: l, level: level}, "", 0)
+}
+
+type stdLogWriter struct {
+	l     *Logger
+	level logLevel
+}
+
+// stdLogCallDepth skips Write, log.(*Logger).output and log.Printf so that
+// Lshortfile reports the caller of the log package.
+const stdLogCallDepth = 4
+
+// Write runs each line through the same pipeline as Info and friends, so
+// redaction, hooks, rules and limits apply to it.
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	if !w.l.shouldLog(w.level) {
+		return len(p), nil
+	}
+	msg := strings.TrimSuffix(string(p), "\n")
+	w.l.emitPlain(stdLogCallDepth, nil, w.level, msg, msg)
+	return len(p), nil
+}
+
+func loggerFor(level logLevel) *log.Logger {
+	return std.loggerFor(level)
+}
+
+func (l *Logger) loggerFor(level logLevel) *log.Logger {
+	st := l.load()
+	switch level {
+	case LevelTrace:
+		return st.traceLogger
+	case LevelDebug:
+		return st.debugLogger
+	case LevelInfo:
+		return st.infoLogger
+	case LevelWarn:
+		return st.warningLogger
+	default:
+		return st.errorLogger
+	}
+}
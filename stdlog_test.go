@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestRedirectStdLog(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	restore := RedirectStdLog(LevelWarn)
+	log.Printf("legacy %s", "line")
+	restore()
+
+	checkLogJSON(t, buf.String(), "WARNING", "legacy line")
+}
+
+func TestRedirectStdLog_RespectsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "warn")
+
+	restore := RedirectStdLog(LevelDebug)
+	defer restore()
+	log.Print("too verbose")
+
+	if buf.Len() != 0 {
+		t.Errorf("expected debug stdlib output to be filtered, got %s", buf.String())
+	}
+}
+
+func TestRedirectStdLog_DefaultsToWarning(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	restore := RedirectStdLog()
+	log.Print("from a dependency")
+	restore()
+
+	checkLogJSON(t, buf.String(), "WARNING", "from a dependency")
+}
+
+func TestStdLogger_Levels(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "text", "info")
+
+	StdLogger(LevelError).Printf("tls handshake error from %s", "10.0.0.1")
+	StdLogger(LevelDebug).Print("filtered")
+	Named("http").StdLogger(LevelWarn).Print("slow client")
+
+	out := buf.String()
+	if !strings.Contains(out, "ERROR: ") || !strings.Contains(out, "stdlog_test.go") || !strings.Contains(out, "tls handshake error from 10.0.0.1") {
+		t.Errorf("unexpected output: %q", out)
+	}
+	if strings.Contains(out, "filtered") {
+		t.Errorf("expected debug output to be filtered")
+	}
+	if !strings.Contains(out, "slow client logger=http") {
+		t.Errorf("expected named logger fields on the entry, got %q", out)
+	}
+}
+
+func TestStdLogger_RunsThePipeline(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithFormat("json"), WithOutputs(&buf), WithRedaction(RedactConfig{Values: []string{EmailPattern}}))
+	l.StdLogger(LevelWarn).Print("user bob@example.com")
+
+	e := decodeLogLines(t, &buf)[0]
+	if e["message"] != "user [REDACTED]" || e["level"] != "WARNING" {
+		t.Errorf("expected the bridged line to be redacted, got %v", e)
+	}
+}
@@ -0,0 +1,80 @@
+package logger
+
+import "time"
+
+// Infow writes a structured INFO entry whose message is msg, with
+// keysAndValues as alternating key/value pairs. It is a lighter alternative to
+// building a map for InfofMap.
+//
+//	logger.Infow("user signed up", "user", id, "plan", "pro")
+//
+// Error values are recorded by their message.
+func Infow(msg string, keysAndValues ...interface{}) {
+	std.logw(LevelInfo, msg, keysAndValues)
+}
+
+// Warningw writes a structured WARNING entry. See Infow.
+func Warningw(msg string, keysAndValues ...interface{}) {
+	std.logw(LevelWarn, msg, keysAndValues)
+}
+
+// Errorw writes a structured ERROR entry. See Infow.
+func Errorw(msg string, keysAndValues ...interface{}) {
+	std.logw(LevelError, msg, keysAndValues)
+}
+
+// Debugw writes a structured DEBUG entry. See Infow.
+func Debugw(msg string, keysAndValues ...interface{}) {
+	std.logw(LevelDebug, msg, keysAndValues)
+}
+
+// Tracew writes a structured TRACE entry. See Infow.
+func Tracew(msg string, keysAndValues ...interface{}) {
+	std.logw(LevelTrace, msg, keysAndValues)
+}
+
+// Fatalw writes a structured FATAL entry and exits. See Infow.
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	std.logw(LevelFatal, msg, keysAndValues)
+}
+
+// Infow writes a structured entry at LevelInfo with alternating key/value pairs.
+func (l *Logger) Infow(msg string, keysAndValues ...interface{}) {
+	l.logw(LevelInfo, msg, keysAndValues)
+}
+
+// Warningw writes a structured entry at LevelWarn with alternating key/value pairs.
+func (l *Logger) Warningw(msg string, keysAndValues ...interface{}) {
+	l.logw(LevelWarn, msg, keysAndValues)
+}
+
+// Errorw writes a structured entry at LevelError with alternating key/value pairs.
+func (l *Logger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.logw(LevelError, msg, keysAndValues)
+}
+
+// Debugw writes a structured entry at LevelDebug with alternating key/value pairs.
+func (l *Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	l.logw(LevelDebug, msg, keysAndValues)
+}
+
+// Tracew writes a structured entry at LevelTrace with alternating key/value pairs.
+func (l *Logger) Tracew(msg string, keysAndValues ...interface{}) {
+	l.logw(LevelTrace, msg, keysAndValues)
+}
+
+// Fatalw writes a structured entry at LevelFatal with alternating key/value
+// pairs and exits.
+func (l *Logger) Fatalw(msg string, keysAndValues ...interface{}) {
+	l.logw(LevelFatal, msg, keysAndValues)
+}
+
+func (l *Logger) logw(level logLevel, msg string, keysAndValues []interface{}) {
+	if !l.shouldLog(level) {
+		return
+	}
+	fields := make(map[string]interface{}, len(keysAndValues)/2+6)
+	addKeysAndValues(fields, keysAndValues)
+	fields["message"] = msg
+	l.logWithMapAt(level, nil, fields, time.Now())
+}
@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestInfow_KeyValuePairs(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "info")
+
+	Infow("user signed up", "user", "alice", "plan", "pro", 42, true)
+	Errorw("charge failed", "error", errors.New("card declined"), "dangling")
+	Debugw("filtered", "x", 1)
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	e := entries[0]
+	if e["message"] != "user signed up" || e["level"] != "INFO" || e["user"] != "alice" || e["plan"] != "pro" || e["42"] != true {
+		t.Errorf("unexpected entry: %v", e)
+	}
+	if e := entries[1]; e["error"] != "card declined" || e["!BADKEY"] != "dangling" {
+		t.Errorf("expected error message and bad key marker, got %v", e)
+	}
+}
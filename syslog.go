@@ -0,0 +1,255 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogConfig configures the syslog sink.
+type SyslogConfig struct {
+	// Network is "udp", "tcp", "unix" or "unixgram". With both Network and
+	// Addr empty the local syslog daemon is used (/dev/log,
+	// /var/run/syslog or /var/run/log).
+	Network string
+	Addr    string
+	// Facility is a facility name such as "user" (the default), "daemon" or
+	// "local0" to "local7".
+	Facility string
+	// AppName and Hostname fill the RFC 5424 header; they default to the
+	// program name and os.Hostname.
+	AppName  string
+	Hostname string
+}
+
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+var localSyslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// syslogTimeFormat is RFC 3339 limited to microseconds, as RFC 5424 requires.
+const syslogTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// SyslogWriter sends entries to a syslog daemon as RFC 5424 messages. Pass
+// it to Init as an additional output. Each entry's level selects the syslog
+// severity; the entry itself, JSON or text, is the message.
+type SyslogWriter struct {
+	network, addr string
+	facility      int
+	header        string // " HOSTNAME APP-NAME PROCID MSGID SD "
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogWriter validates cfg and connects to the syslog daemon.
+func NewSyslogWriter(cfg SyslogConfig) (*SyslogWriter, error) {
+	facility := 1
+	if cfg.Facility != "" {
+		f, ok := syslogFacilities[strings.ToLower(cfg.Facility)]
+		if !ok {
+			return nil, fmt.Errorf("syslog: unknown facility %q", cfg.Facility)
+		}
+		facility = f
+	}
+	switch cfg.Network {
+	case "", "udp", "tcp", "unix", "unixgram":
+	default:
+		return nil, fmt.Errorf("syslog: unknown network %q (want udp, tcp, unix or unixgram)", cfg.Network)
+	}
+	if (cfg.Network == "") != (cfg.Addr == "") {
+		return nil, errors.New("syslog: Network and Addr must be set together")
+	}
+
+	appName := cfg.AppName
+	if appName == "" {
+		appName = filepath.Base(os.Args[0])
+	}
+	hostname := cfg.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+
+	w := &SyslogWriter{
+		network:  cfg.Network,
+		addr:     cfg.Addr,
+		facility: facility,
+		header: " " + syslogHeaderField(hostname, 255) +
+			" " + syslogHeaderField(appName, 48) +
+			" " + strconv.Itoa(os.Getpid()) + " - - ",
+	}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *SyslogWriter) connect() error {
+	if w.addr != "" {
+		conn, err := net.DialTimeout(w.network, w.addr, 5*time.Second)
+		if err != nil {
+			return fmt.Errorf("syslog: %w", err)
+		}
+		w.conn = conn
+		return nil
+	}
+
+	// Local daemon: prefer datagrams, fall back to a stream socket.
+	var lastErr error
+	for _, path := range localSyslogSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.Dial(network, path)
+			if err == nil {
+				w.network = network
+				w.conn = conn
+				return nil
+			}
+			lastErr = err
+		}
+	}
+	return fmt.Errorf("syslog: no local syslog daemon: %w", lastErr)
+}
+
+// Write sends each line of p as one syslog message.
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if w.conn == nil {
+			if err := w.connect(); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := w.conn.Write(w.frame(line)); err != nil {
+			w.conn.Close()
+			w.conn = nil
+			return 0, fmt.Errorf("syslog: %w", err)
+		}
+	}
+	return len(p), nil
+}
+
+// frame formats line as an RFC 5424 message with the framing of the
+// transport: octet counting over TCP (RFC 6587), a trailing newline on unix
+// stream sockets and none for datagrams.
+func (w *SyslogWriter) frame(line []byte) []byte {
+	level, at := syslogEntryMeta(line)
+	msg := fmt.Sprintf("<%d>1 %s%s%s", w.facility*8+syslogSeverity(level), at.Format(syslogTimeFormat), w.header, line)
+	switch w.network {
+	case "tcp":
+		return []byte(strconv.Itoa(len(msg)) + " " + msg)
+	case "unix":
+		return []byte(msg + "\n")
+	default:
+		return []byte(msg)
+	}
+}
+
+// Close closes the connection to the syslog daemon.
+func (w *SyslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+var _ io.WriteCloser = (*SyslogWriter)(nil)
+
+// syslogEntryMeta returns the level and time of an entry written by this
+// package: the level and timestamp fields of JSON and logfmt entries, or the
+// "LEVEL: " prefix of text entries.
+func syslogEntryMeta(line []byte) (logLevel, time.Time) {
+	level, at := LevelInfo, time.Now()
+
+	var entry map[string]interface{}
+	if len(line) > 1 && line[0] == '{' && json.Unmarshal(line, &entry) == nil {
+		if v, _, ok := takeFirst(entry, []string{"level", "severity"}); ok {
+			level = logLevel(fmt.Sprint(v))
+		}
+		if v, _, ok := takeFirst(entry, passthroughTimestampKeys); ok {
+			if t, ok := parsePassthroughTime(v); ok {
+				at = t
+			}
+		}
+		return level, at
+	}
+	if bytes.HasPrefix(line, []byte("time=")) {
+		for _, pair := range strings.Fields(string(line)) {
+			k, v, _ := strings.Cut(pair, "=")
+			switch k {
+			case "time":
+				if t, ok := parsePassthroughTime(v); ok {
+					at = t
+				}
+			case "level":
+				level = logLevel(v)
+				return level, at
+			}
+		}
+		return level, at
+	}
+	if prefix, _, ok := strings.Cut(string(line), ": "); ok && SeverityNumber(logLevel(prefix)) != 0 {
+		level = logLevel(prefix)
+	}
+	return level, at
+}
+
+// syslogHeaderField returns s as a header field: printable ASCII without
+// spaces, at most max bytes, or the NILVALUE "-" when empty.
+func syslogHeaderField(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// syslogSeverity maps a level to a syslog severity (0 emergency to 7 debug)
+// by its severity number.
+func syslogSeverity(level logLevel) int {
+	switch sev := SeverityNumber(level); {
+	case sev == 0:
+		return 6
+	case sev < 9:
+		return 7
+	case sev < 11:
+		return 6
+	case sev < 13:
+		return 5
+	case sev < 17:
+		return 4
+	case sev < 21:
+		return 3
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"bufio"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogWriter_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	w, err := NewSyslogWriter(SyslogConfig{Network: "udp", Addr: pc.LocalAddr().String(), Facility: "local3", AppName: "billing", Hostname: "node 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte(`{"timestamp":"2025-05-11T19:30:12Z","level":"ERROR","message":"payment failed"}` + "\n"))
+
+	buf := make([]byte, 1024)
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// local3 (19) * 8 + err (3) = 155
+	want := regexp.MustCompile(`^<155>1 2025-05-11T19:30:12\.000000Z node_1 billing \d+ - - \{"timestamp":.*"message":"payment failed"\}$`)
+	if got := string(buf[:n]); !want.MatchString(got) {
+		t.Errorf("unexpected syslog message %q", got)
+	}
+}
+
+func TestSyslogWriter_TCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	got := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		length, _ := r.ReadString(' ')
+		rest := make([]byte, 0, 256)
+		for len(rest) < 256 {
+			b, err := r.ReadByte()
+			if err != nil {
+				break
+			}
+			rest = append(rest, b)
+			if strings.HasSuffix(string(rest), "disk low") {
+				break
+			}
+		}
+		got <- length + string(rest)
+	}()
+
+	w, err := NewSyslogWriter(SyslogConfig{Network: "tcp", Addr: ln.Addr().String(), AppName: "app", Hostname: "h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte("WARNING: 2025/05/11 19:30:12.000000 main.go:10: disk low\n"))
+
+	select {
+	case frame := <-got:
+		length, msg, _ := strings.Cut(frame, " ")
+		if !strings.HasPrefix(msg, "<12>1 ") || length != strconv.Itoa(len(msg)) {
+			t.Errorf("unexpected frame %q", frame)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for frame")
+	}
+}
+
+func TestSyslogWriter_UnixDatagram(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	pc, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Skipf("unixgram not supported: %v", err)
+	}
+	defer pc.Close()
+
+	w, err := NewSyslogWriter(SyslogConfig{Network: "unixgram", Addr: path, Facility: "daemon"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte(`{"level":"DEBUG","message":"tick"}` + "\n"))
+
+	buf := make([]byte, 1024)
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// daemon (3) * 8 + debug (7) = 31
+	if got := string(buf[:n]); !strings.HasPrefix(got, "<31>1 ") || strings.HasSuffix(got, "\n") {
+		t.Errorf("unexpected syslog message %q", got)
+	}
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	for level, want := range map[logLevel]int{
+		LevelTrace: 7,
+		LevelDebug: 7,
+		LevelInfo:  6,
+		LevelWarn:  4,
+		LevelError: 3,
+		LevelFatal: 2,
+	} {
+		if got := syslogSeverity(level); got != want {
+			t.Errorf("syslogSeverity(%s) = %d, want %d", level, got, want)
+		}
+	}
+}
+
+func TestNewSyslogWriter_Validation(t *testing.T) {
+	for _, cfg := range []SyslogConfig{
+		{Network: "udp", Addr: "localhost:514", Facility: "nope"},
+		{Network: "http", Addr: "localhost:514"},
+		{Network: "udp"},
+	} {
+		if _, err := NewSyslogWriter(cfg); err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package logger
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ThrottleConfig suppresses repeats of the same entry: once an entry with the
+// same level and message template has been written Limit times in an
+// interval, further ones are dropped until the interval ends, when a single
+// summary entry with a suppressed_count field is written in their place.
+//
+// The template of Infof("retry %d", n) is "retry %d", so entries that differ
+// only in their arguments are counted together; for other entries it is the
+// message.
+type ThrottleConfig struct {
+	// Limit is the number of entries written per template and interval.
+	Limit int `json:"limit" yaml:"limit"`
+	// IntervalMS is the interval. Defaults to 60000.
+	IntervalMS int `json:"interval_ms" yaml:"interval_ms"`
+}
+
+func (tc ThrottleConfig) validate() error {
+	var errs []error
+	if tc.Limit < 1 {
+		errs = append(errs, errors.New("throttle limit must be at least 1"))
+	}
+	if tc.IntervalMS < 0 {
+		errs = append(errs, errors.New("throttle interval_ms must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// maxThrottleKeys bounds the templates tracked at once. Templates beyond it
+// are not throttled until expired ones are swept.
+const maxThrottleKeys = 10000
+
+// suppressedCount marks the suppressed_count field of summary entries, which
+// the throttle lets through.
+type suppressedCount uint64
+
+// throttle implements ThrottleConfig. A nil throttle lets everything through.
+type throttle struct {
+	limit    uint64
+	interval time.Duration
+	// summarize writes the summary entry for a template whose repeats were
+	// suppressed.
+	summarize func(level logLevel, msg string, suppressed uint64)
+
+	suppressed atomic.Uint64 // total, for metrics
+
+	mu      sync.Mutex
+	windows map[throttleKey]*throttleWindow
+}
+
+type throttleKey struct {
+	level    logLevel
+	template string
+}
+
+type throttleWindow struct {
+	start      time.Time
+	n          uint64
+	suppressed uint64
+	lastMsg    string
+}
+
+func newThrottle(tc *ThrottleConfig, summarize func(level logLevel, msg string, suppressed uint64)) *throttle {
+	if tc == nil {
+		return nil
+	}
+	interval := time.Duration(tc.IntervalMS) * time.Millisecond
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &throttle{
+		limit:     uint64(tc.Limit),
+		interval:  interval,
+		summarize: summarize,
+		windows:   make(map[throttleKey]*throttleWindow),
+	}
+}
+
+// allow reports whether an entry is written. The first suppressed entry of
+// an interval schedules the summary for the end of it.
+func (t *throttle) allow(level logLevel, template, msg string) bool {
+	if t == nil || level == LevelFatal {
+		return true
+	}
+	k := throttleKey{level, template}
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	w := t.windows[k]
+	if w == nil {
+		if len(t.windows) >= maxThrottleKeys {
+			t.sweep(now)
+			if len(t.windows) >= maxThrottleKeys {
+				return true
+			}
+		}
+		w = &throttleWindow{start: now}
+		t.windows[k] = w
+	} else if now.Sub(w.start) >= t.interval {
+		w.start, w.n = now, 0
+	}
+	w.n++
+	if w.n <= t.limit {
+		return true
+	}
+	w.suppressed++
+	w.lastMsg = msg
+	t.suppressed.Add(1)
+	if w.suppressed == 1 {
+		time.AfterFunc(w.start.Add(t.interval).Sub(now), func() { t.flush(k, w) })
+	}
+	return false
+}
+
+// flush writes the summary for w's suppressed entries.
+func (t *throttle) flush(k throttleKey, w *throttleWindow) {
+	t.mu.Lock()
+	n, msg := w.suppressed, w.lastMsg
+	w.suppressed = 0
+	t.mu.Unlock()
+	if n > 0 && t.summarize != nil {
+		t.summarize(k.level, msg, n)
+	}
+}
+
+// sweep forgets the templates whose interval has passed with nothing left to
+// summarize. t.mu must be held.
+func (t *throttle) sweep(now time.Time) {
+	for k, w := range t.windows {
+		if w.suppressed == 0 && now.Sub(w.start) >= t.interval {
+			delete(t.windows, k)
+		}
+	}
+}
+
+// writeThrottleSummary writes the entry that stands in for suppressed
+// repeats: the last suppressed message with a suppressed_count field.
+func (l *Logger) writeThrottleSummary(level logLevel, msg string, suppressed uint64) {
+	if !l.load().structuredFormat() {
+		if pl := l.loggerFor(level); pl != nil {
+			_ = pl.Output(2, l.load().redactor.message(msg)+formatFieldSuffix(map[string]interface{}{"suppressed_count": suppressed}))
+		}
+		return
+	}
+	l.writeStructured(level, nil, map[string]interface{}{
+		"message":          msg,
+		"suppressed_count": suppressedCount(suppressed),
+	}, time.Now(), "")
+}
@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for the writes of throttle summaries,
+// which come from a timer goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestThrottle_SuppressesAndSummarizes(t *testing.T) {
+	out := &syncBuffer{}
+	l := New(WithFormat("json"), WithOutputs(out), WithThrottle(ThrottleConfig{Limit: 2, IntervalMS: 50}))
+	for i := 0; i < 10; i++ {
+		l.Errorf("upstream %s failed, attempt %d", "billing", i)
+	}
+	l.Error("a different message")
+	if got := strings.Count(out.String(), "upstream billing failed"); got != 2 {
+		t.Fatalf("expected 2 entries before suppression, got %d:\n%s", got, out)
+	}
+	if !strings.Contains(out.String(), "a different message") {
+		t.Error("expected other templates to be written")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(out.String(), "suppressed_count") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !strings.Contains(out.String(), `"message":"upstream billing failed, attempt 9","service":"","suppressed_count":8`) {
+		t.Fatalf("expected a summary of the 8 suppressed entries, got:\n%s", out)
+	}
+
+	l.Errorf("upstream %s failed, attempt %d", "billing", 10)
+	if got := strings.Count(out.String(), "attempt 10"); got != 1 {
+		t.Errorf("expected a new interval to write again, got %d", got)
+	}
+}
+
+func TestThrottle_StructuredAndText(t *testing.T) {
+	out := &syncBuffer{}
+	l := New(WithFormat("text"), WithOutputs(out), WithThrottle(ThrottleConfig{Limit: 1, IntervalMS: 20}))
+	l.Warning("disk almost full")
+	l.Warning("disk almost full")
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(out.String(), "suppressed_count=1") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !strings.Contains(out.String(), "throttle_test.go:") {
+		t.Errorf("expected the caller's file in text entries, got:\n%s", out)
+	}
+	if !strings.Contains(out.String(), "disk almost full suppressed_count=1") {
+		t.Errorf("expected a text summary, got:\n%s", out)
+	}
+
+	out = &syncBuffer{}
+	l = New(WithFormat("json"), WithOutputs(out), WithThrottle(ThrottleConfig{Limit: 1, IntervalMS: 60000}))
+	for i := 0; i < 3; i++ {
+		l.InfoMapf(context.Background(), map[string]interface{}{"shard": i}, "rebalanced shard %d", i)
+	}
+	if got := strings.Count(out.String(), "rebalanced shard"); got != 1 {
+		t.Errorf("expected map entries to be throttled by template, got %d", got)
+	}
+}
+
+func TestThrottleConfig_Validate(t *testing.T) {
+	err := Config{Throttle: &ThrottleConfig{IntervalMS: -1}}.Validate()
+	for _, want := range []string{"throttle limit must be at least 1", "throttle interval_ms must not be negative"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"sync/atomic"
+)
+
+// UUIDPattern matches canonical UUIDs, for use as a Transform MessagePattern.
+const UUIDPattern = `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`
+
+// Transform rewrites entries before they are encoded. Field operations apply
+// to structured entries; message normalization applies to every entry.
+//
+// For example, to move UUIDs out of messages into an id field so that
+// aggregations group identical messages:
+//
+//	logger.Transform{MessagePattern: logger.UUIDPattern, MessageReplacement: "<id>", ExtractField: "id"}
+type Transform struct {
+	// When restricts the transform to entries matching a filter expression
+	// (see CompileFilter). Empty means every entry.
+	When string
+	// DropFields removes these fields.
+	DropFields []string
+	// RenameFields renames fields from key to value.
+	RenameFields map[string]string
+	// SetFields sets these fields, overwriting existing values.
+	SetFields map[string]interface{}
+	// MessagePattern is a regular expression whose matches in the message are
+	// replaced with MessageReplacement.
+	MessagePattern     string
+	MessageReplacement string
+	// ExtractField stores the MessagePattern matches in this field: a string
+	// for a single match, a list for several.
+	ExtractField string
+}
+
+type compiledTransform struct {
+	Transform
+	when    *FilterRule
+	pattern *regexp.Regexp
+}
+
+var transforms atomic.Pointer[[]compiledTransform]
+
+// SetTransforms compiles and atomically installs entry transforms, replacing
+// any previous ones. Transforms run in order.
+func SetTransforms(ts ...Transform) error {
+	compiled := make([]compiledTransform, 0, len(ts))
+	for i, t := range ts {
+		c := compiledTransform{Transform: t}
+		if t.When != "" {
+			rule, err := CompileFilter(t.When)
+			if err != nil {
+				return fmt.Errorf("transform %d: %w", i, err)
+			}
+			c.when = rule
+		}
+		if t.MessagePattern != "" {
+			re, err := regexp.Compile(t.MessagePattern)
+			if err != nil {
+				return fmt.Errorf("transform %d: %w", i, err)
+			}
+			c.pattern = re
+		}
+		compiled = append(compiled, c)
+	}
+	if len(compiled) == 0 {
+		transforms.Store(nil)
+		return nil
+	}
+	transforms.Store(&compiled)
+	return nil
+}
+
+// transformMessage applies message normalization to a plain-message entry.
+func transformMessage(level logLevel, msg string) string {
+	ts := transforms.Load()
+	if ts == nil {
+		return msg
+	}
+	for _, t := range *ts {
+		if t.pattern == nil || (t.when != nil && !t.when.Match(string(level), msg, nil)) {
+			continue
+		}
+		msg = t.pattern.ReplaceAllString(msg, t.MessageReplacement)
+	}
+	return msg
+}
+
+// transformFields applies every transform to a structured entry in place.
+func transformFields(level logLevel, fields map[string]interface{}) {
+	ts := transforms.Load()
+	if ts == nil {
+		return
+	}
+	for _, t := range *ts {
+		if t.when != nil && !t.when.Match(string(level), "", fields) {
+			continue
+		}
+
+		if t.pattern != nil {
+			if msg, ok := fields["message"].(string); ok {
+				matches := t.pattern.FindAllString(msg, -1)
+				if len(matches) > 0 {
+					fields["message"] = t.pattern.ReplaceAllString(msg, t.MessageReplacement)
+					if t.ExtractField != "" {
+						if len(matches) == 1 {
+							fields[t.ExtractField] = matches[0]
+						} else {
+							fields[t.ExtractField] = matches
+						}
+					}
+				}
+			}
+		}
+
+		for from, to := range t.RenameFields {
+			if v, ok := fields[from]; ok {
+				delete(fields, from)
+				fields[to] = v
+			}
+		}
+		for _, k := range t.DropFields {
+			delete(fields, k)
+		}
+		for k, v := range t.SetFields {
+			fields[k] = v
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestTransforms_ExtractUUIDs(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	err := SetTransforms(Transform{MessagePattern: UUIDPattern, MessageReplacement: "<id>", ExtractField: "id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer SetTransforms()
+
+	InfofMap(context.Background(), map[string]interface{}{"message": "order 3f2b8c1e-9d4a-4b7e-8f00-1a2b3c4d5e6f shipped"})
+	Info("user 3f2b8c1e-9d4a-4b7e-8f00-1a2b3c4d5e6f logged in")
+
+	entries := decodeLogLines(t, &buf)
+	if entries[0]["message"] != "order <id> shipped" || entries[0]["id"] != "3f2b8c1e-9d4a-4b7e-8f00-1a2b3c4d5e6f" {
+		t.Errorf("unexpected structured entry: %v", entries[0])
+	}
+	if entries[1]["message"] != "user <id> logged in" {
+		t.Errorf("expected plain message to be normalized, got %v", entries[1]["message"])
+	}
+}
+
+func TestTransforms_FieldOperations(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	err := SetTransforms(Transform{
+		When:         `fields.component == "gorm"`,
+		DropFields:   []string{"rows_affected_raw"},
+		RenameFields: map[string]string{"sql": "db_statement"},
+		SetFields:    map[string]interface{}{"subsystem": "db"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer SetTransforms()
+
+	InfofMap(nil, map[string]interface{}{"component": "gorm", "sql": "SELECT 1", "rows_affected_raw": "1"})
+	InfofMap(nil, map[string]interface{}{"component": "http", "sql": "untouched"})
+
+	entries := decodeLogLines(t, &buf)
+	gorm, other := entries[0], entries[1]
+	if gorm["db_statement"] != "SELECT 1" || gorm["sql"] != nil || gorm["rows_affected_raw"] != nil || gorm["subsystem"] != "db" {
+		t.Errorf("unexpected transformed entry: %v", gorm)
+	}
+	if other["sql"] != "untouched" || other["subsystem"] != nil {
+		t.Errorf("transform must only apply to matching entries: %v", other)
+	}
+}
+
+func TestSetTransforms_InvalidPattern(t *testing.T) {
+	if err := SetTransforms(Transform{MessagePattern: "("}); err == nil {
+		t.Errorf("expected error for invalid pattern")
+	}
+}
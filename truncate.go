@@ -0,0 +1,145 @@
+package logger
+
+import (
+	"log"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"unicode/utf8"
+)
+
+// truncationSuffix ends values shortened to fit the entry size limit.
+const truncationSuffix = "...[truncated]"
+
+// maxTruncationPasses bounds the re-encodings spent shrinking one entry
+// before it is cut down to its essential fields.
+const maxTruncationPasses = 8
+
+// essentialFields survive when an entry can't otherwise be made to fit.
+var essentialFields = map[string]bool{
+	"timestamp": true, "time": true, "level": true, "severity": true, "message": true,
+	"service": true, "environment": true, "trace_id": true, "span_id": true, "logger": true,
+	"truncated": true, gcpTraceKey: true, gcpSpanKey: true, gcpLabelsKey: true,
+}
+
+// minimalFields are what is left of an entry whose essential fields don't
+// fit either.
+var minimalFields = map[string]bool{
+	"timestamp": true, "time": true, "level": true, "severity": true, "message": true, "truncated": true,
+}
+
+// fitEntry re-encodes an entry that encoded to more than st.maxEntryBytes,
+// shortening its largest values and then its message until it fits, and
+// marking it with truncated=true. If that isn't enough, only the essential
+// fields are kept, and then only the level, timestamp and message.
+func (st *loggerState) fitEntry(b *entryBuffer, fields map[string]interface{}) error {
+	fields["truncated"] = true
+	msg, hasMessage := fields["message"]
+	for pass := 0; pass < maxTruncationPasses && b.Len() > st.maxEntryBytes; pass++ {
+		key := largestField(fields)
+		if key == "" {
+			break
+		}
+		excess := b.Len() - st.maxEntryBytes
+		if s, ok := fields[key].(string); ok && len(s) > excess+len(truncationSuffix) {
+			fields[key] = truncateString(s, len(s)-excess-len(truncationSuffix))
+		} else {
+			fields[key] = truncationSuffix
+		}
+		if err := st.reencode(b, fields); err != nil {
+			return err
+		}
+	}
+
+	for _, keep := range []map[string]bool{nil, essentialFields, minimalFields} {
+		if keep != nil {
+			for k := range fields {
+				if !keep[k] {
+					delete(fields, k)
+				}
+			}
+			if hasMessage {
+				fields["message"] = msg
+			}
+			if err := st.reencode(b, fields); err != nil {
+				return err
+			}
+		}
+		if err := st.fitMessage(b, fields); err != nil || b.Len() <= st.maxEntryBytes {
+			return err
+		}
+	}
+	// The limit is below the size of a minimal entry.
+	delete(fields, "timestamp")
+	delete(fields, "time")
+	return st.reencode(b, fields)
+}
+
+// fitMessage shortens the message so that the entry in b fits. Cutting n
+// bytes of the message shortens its encoding by at least n bytes.
+func (st *loggerState) fitMessage(b *entryBuffer, fields map[string]interface{}) error {
+	excess := b.Len() - st.maxEntryBytes
+	if excess <= 0 {
+		return nil
+	}
+	switch msg := fields["message"].(type) {
+	case nil:
+		return nil
+	case string:
+		fields["message"] = truncateString(msg, len(msg)-excess-len(truncationSuffix))
+	default:
+		fields["message"] = truncationSuffix
+	}
+	return st.reencode(b, fields)
+}
+
+func (st *loggerState) reencode(b *entryBuffer, fields map[string]interface{}) error {
+	b.Reset()
+	return st.encodeTo(b, fields)
+}
+
+// largestField returns the key of the non-essential field with the longest
+// encoding, ignoring values no longer than the truncation suffix.
+func largestField(fields map[string]interface{}) string {
+	var (
+		key     string
+		largest = len(truncationSuffix) + 2
+		scratch []byte
+	)
+	for k, v := range fields {
+		if essentialFields[k] {
+			continue
+		}
+		scratch, _ = appendJSONValue(scratch[:0], v)
+		if len(scratch) > largest {
+			key, largest = k, len(scratch)
+		}
+	}
+	return key
+}
+
+// truncateString cuts s to at most n bytes without splitting a UTF-8
+// sequence and appends truncationSuffix.
+func truncateString(s string, n int) string {
+	if n < 0 {
+		n = 0
+	}
+	if n >= len(s) {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + truncationSuffix
+}
+
+// textHeaderLen is the length of what pl.Output(calldepth, msg) adds around
+// msg when called in place of textHeaderLen: the prefix, the date, time and
+// file:line written with the text format's flags, and the newline.
+func textHeaderLen(pl *log.Logger, calldepth int) int {
+	file, line := "???", 0
+	if _, f, l, ok := runtime.Caller(calldepth); ok {
+		file, line = filepath.Base(f), l
+	}
+	return len(pl.Prefix()) + len("2006/01/02 15:04:05.000000 ") + len(file) + 1 + len(strconv.Itoa(line)) + len(": ") + 1
+}
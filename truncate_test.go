@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestMaxEntrySize_TruncatesLargestFields(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithFormat("json"), WithService("billing", "prod"), WithOutputs(&out), WithMaxEntrySize(1024))
+	l.InfofMap(context.Background(), map[string]interface{}{
+		"message":  "response body",
+		"body":     strings.Repeat("x", 10000),
+		"headers":  strings.Repeat("h", 3000),
+		"status":   200,
+		"trace_id": "abc",
+	})
+	line := strings.TrimSuffix(out.String(), "\n")
+	if len(line) > 1024 {
+		t.Errorf("expected at most 1024 bytes, got %d", len(line))
+	}
+	entries := decodeLogLines(t, &out)
+	e := entries[0]
+	if e["truncated"] != true || e["message"] != "response body" || e["status"] != float64(200) || e["trace_id"] != "abc" {
+		t.Errorf("unexpected entry %v", e)
+	}
+	if body, _ := e["body"].(string); !strings.HasSuffix(body, truncationSuffix) {
+		t.Errorf("expected the body to be shortened, got %q", body)
+	}
+}
+
+func TestMaxEntrySize_FallsBackToEssentialFields(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithFormat("json"), WithOutputs(&out), WithMaxEntrySize(400))
+	fields := map[string]interface{}{"message": strings.Repeat("m", 2000)}
+	for i := 0; i < 50; i++ {
+		fields[strings.Repeat("k", i+1)] = i
+	}
+	l.ErrorfMap(context.Background(), fields)
+	if line := strings.TrimSuffix(out.String(), "\n"); len(line) > 400 || !strings.Contains(line, `"truncated":true`) {
+		t.Errorf("expected a truncated entry within 400 bytes, got %d bytes: %s", len(line), line)
+	}
+
+	out.Reset()
+	l.Warning(strings.Repeat("w", 1000) + "é")
+	e := decodeLogLines(t, &out)[0]
+	if e["truncated"] != true || len(e["message"].(string)) > 400 {
+		t.Errorf("expected long plain messages to be truncated, got %v", e)
+	}
+}
+
+func TestMaxEntrySize_Text(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithOutputs(&out), WithMaxEntrySize(100))
+	l.Info(strings.Repeat("日本", 100))
+	line := out.String()
+	if !utf8.ValidString(line) || strings.Count(line, "日") > 40 || !strings.HasSuffix(line, truncationSuffix+"\n") {
+		t.Errorf("unexpected text entry %q", line)
+	}
+}
+
+func TestMaxEntrySize_HoldsForSmallLimits(t *testing.T) {
+	for _, format := range []string{"text", "json", "logfmt", "gcp"} {
+		var out bytes.Buffer
+		l := New(WithFormat(format), WithService("billing-service-with-a-long-name", "production"), WithOutputs(&out), WithMaxEntrySize(100))
+		l.Info(strings.Repeat("m", 95))
+		l.InfofMap(context.Background(), map[string]interface{}{"message": strings.Repeat("é", 200), "body": strings.Repeat("b", 200)})
+		for _, line := range strings.SplitAfter(out.String(), "\n") {
+			if len(line) > 100 {
+				t.Errorf("%s: %d-byte entry exceeds the limit: %s", format, len(line), line)
+			}
+		}
+		if format == "json" {
+			for _, e := range decodeLogLines(t, &out) {
+				if e["level"] != "INFO" || e["truncated"] != true || !strings.HasSuffix(e["message"].(string), truncationSuffix) {
+					t.Errorf("json: expected the level kept and the message truncated, got %v", e)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// WebhookConfig configures the HTTP webhook sink.
+type WebhookConfig struct {
+	// URL receives the batches; Method defaults to POST.
+	URL    string
+	Method string
+	// Headers are set on every request, after the defaults
+	// (Content-Type: application/json).
+	Headers map[string]string
+	// Username and Password enable basic auth; BearerToken sends an
+	// Authorization: Bearer header instead.
+	Username    string
+	Password    string
+	BearerToken string
+	// BatchSize and BatchInterval control batching; defaults are 100 entries
+	// and 1 second.
+	BatchSize     int
+	BatchInterval time.Duration
+	// MaxRetries and RetryBackoff control retries of requests that fail
+	// with a network error, 429 or 5xx; the backoff doubles after every
+	// attempt, up to 30 seconds, and a Retry-After header is honoured.
+	// Defaults are 3 retries and 500ms; a negative MaxRetries disables
+	// retries.
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// Client sends the requests; defaults to a client with a 30 second
+	// timeout.
+	Client *http.Client
+}
+
+const maxWebhookBackoff = 30 * time.Second
+
+// WebhookWriter sends entries to an HTTP endpoint in batches, each a JSON
+// array of entries. JSON entries are sent as they are; other lines become
+// objects with level and message. Writes do not block on the network, even
+// while a failed request waits to be retried; delivery errors are reported by
+// LastError and by the next Write.
+type WebhookWriter struct {
+	cfg    WebhookConfig
+	client *http.Client
+	batch  *batcher[json.RawMessage]
+}
+
+// NewWebhookWriter validates cfg and returns a sink that can be passed to
+// Init as an additional output.
+func NewWebhookWriter(cfg WebhookConfig) (*WebhookWriter, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("webhook: invalid URL %q", cfg.URL)
+	}
+	if cfg.Method == "" {
+		cfg.Method = http.MethodPost
+	}
+	if cfg.BearerToken != "" && cfg.Username != "" {
+		return nil, errors.New("webhook: BearerToken and Username are mutually exclusive")
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	interval := cfg.BatchInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 500 * time.Millisecond
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	w := &WebhookWriter{cfg: cfg, client: client}
+	w.batch = newBatcher(batchSize, interval, w.send)
+	return w, nil
+}
+
+// Write queues each line of p as one entry.
+func (w *WebhookWriter) Write(p []byte) (int, error) {
+	var err error
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		entry, err2 := webhookEntry(line)
+		if err2 == nil {
+			err2 = w.batch.add(entry)
+		}
+		if err2 != nil && err == nil {
+			err = err2
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("webhook: %w", err)
+	}
+	return len(p), nil
+}
+
+func webhookEntry(line []byte) (json.RawMessage, error) {
+	line = bytes.TrimSpace(line)
+	if line[0] == '{' && json.Valid(line) {
+		return append(json.RawMessage(nil), line...), nil
+	}
+	level, _ := syslogEntryMeta(line)
+	return json.Marshal(map[string]interface{}{"level": level, "message": string(line)})
+}
+
+// send posts entries, retrying failures that may be temporary.
+func (w *WebhookWriter) send(entries []json.RawMessage) error {
+	body, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	backoff := w.cfg.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, wait, err := w.post(body)
+		if err == nil || !retry || attempt >= w.cfg.MaxRetries {
+			return err
+		}
+		if wait <= 0 {
+			wait = backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		}
+		if wait > maxWebhookBackoff {
+			wait = maxWebhookBackoff
+		}
+		time.Sleep(wait)
+		backoff *= 2
+		if backoff > maxWebhookBackoff {
+			backoff = maxWebhookBackoff
+		}
+	}
+}
+
+// post sends one request. It reports whether a failure may be retried and
+// how long the server asked to wait.
+func (w *WebhookWriter) post(body []byte) (retry bool, wait time.Duration, err error) {
+	req, err := http.NewRequest(w.cfg.Method, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	switch {
+	case w.cfg.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+w.cfg.BearerToken)
+	case w.cfg.Username != "":
+		req.SetBasicAuth(w.cfg.Username, w.cfg.Password)
+	}
+	for k, v := range w.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return false, 0, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("endpoint rejected: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode/100 != 5 {
+		return false, 0, err
+	}
+	if secs, perr := strconv.Atoi(resp.Header.Get("Retry-After")); perr == nil && secs > 0 {
+		wait = time.Duration(secs) * time.Second
+	}
+	return true, wait, err
+}
+
+// Flush sends the pending entries.
+func (w *WebhookWriter) Flush() error {
+	if err := w.batch.flush(); err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	return nil
+}
+
+// LastError returns the most recent asynchronous delivery error.
+func (w *WebhookWriter) LastError() error {
+	return w.batch.lastError()
+}
+
+// Close sends the pending entries and stops the background sends.
+func (w *WebhookWriter) Close() error {
+	if err := w.batch.close(); err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	return nil
+}
+
+var _ io.WriteCloser = (*WebhookWriter)(nil)
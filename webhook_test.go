@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookWriter_Batch(t *testing.T) {
+	got := make(chan []map[string]interface{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("Authorization") != "Bearer tok" || r.Header.Get("X-Source") != "billing" {
+			t.Errorf("unexpected request %s %v", r.Method, r.Header)
+		}
+		var entries []map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&entries); err != nil {
+			t.Error(err)
+		}
+		got <- entries
+	}))
+	defer srv.Close()
+
+	w, err := NewWebhookWriter(WebhookConfig{
+		URL: srv.URL, Method: http.MethodPut, BearerToken: "tok",
+		Headers: map[string]string{"X-Source": "billing"}, BatchInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte(`{"level":"INFO","message":"charged","amount":3}` + "\n" + "WARNING: 2025/05/11 main.go:1: slow\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := <-got
+	if len(entries) != 2 || entries[0]["message"] != "charged" || entries[0]["amount"] != 3.0 {
+		t.Fatalf("unexpected batch %v", entries)
+	}
+	if entries[1]["level"] != "WARNING" || !strings.HasSuffix(entries[1]["message"].(string), "slow") {
+		t.Errorf("unexpected text entry %v", entries[1])
+	}
+}
+
+func TestWebhookWriter_Retries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+
+	w, err := NewWebhookWriter(WebhookConfig{URL: srv.URL, RetryBackoff: time.Millisecond, BatchInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte(`{"message":"x"}` + "\n"))
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("got %d requests, want 3", calls.Load())
+	}
+}
+
+func TestWebhookWriter_NoRetryOnClientError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "bad payload", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	w, err := NewWebhookWriter(WebhookConfig{URL: srv.URL, RetryBackoff: time.Millisecond, BatchInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte(`{"message":"x"}` + "\n"))
+	if err := w.Flush(); err == nil || !strings.Contains(err.Error(), "bad payload") {
+		t.Fatalf("Flush() = %v, want the 400 error", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("got %d requests, want 1", calls.Load())
+	}
+}
+
+func TestWebhookWriter_RetriesDontBlockWrites(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	w, err := NewWebhookWriter(WebhookConfig{URL: srv.URL, BatchSize: 2, BatchInterval: time.Hour, MaxRetries: 1, RetryBackoff: 300 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		_, _ = w.Write([]byte(`{"message":"while down"}` + "\n"))
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("the write of a full batch waited %s for the retry", elapsed)
+	}
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// With returns a child of the package-level logger that attaches fields to
+// every entry. See Logger.With.
+func With(fields map[string]interface{}) *Logger {
+	return std.With(fields)
+}
+
+// WithField is like With for a single field.
+func WithField(key string, value interface{}) *Logger {
+	return std.WithField(key, value)
+}
+
+// With returns a child logger that attaches fields to every entry, e.g. to
+// bind a request_id once per request:
+//
+//	log := logger.With(map[string]interface{}{"request_id": id, "user_id": uid})
+//	log.Info("fetching profile")
+//	log.ErrorfMap(ctx, map[string]interface{}{"message": "profile not found"})
+//
+// Fields passed to an individual call take precedence over bound ones. The
+// child shares l's level, outputs and metadata, including later
+// reconfiguration. Text-format entries carry the fields as key=value pairs
+// after the message.
+func (l *Logger) With(fields map[string]interface{}) *Logger {
+	merged := make(map[string]interface{}, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &Logger{parent: l.root(), fields: merged, name: l.name}
+}
+
+// WithField is like With for a single field.
+func (l *Logger) WithField(key string, value interface{}) *Logger {
+	return l.With(map[string]interface{}{key: value})
+}
+
+func (l *Logger) root() *Logger {
+	if l.parent != nil {
+		return l.parent
+	}
+	return l
+}
+
+// formatFieldSuffix renders fields as " key=value" pairs in key order.
+func formatFieldSuffix(fields map[string]interface{}) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		v := fmt.Sprint(fields[k])
+		if v == "" || strings.ContainsAny(v, " \t\"=") {
+			v = strconv.Quote(v)
+		}
+		b.WriteString(" ")
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(v)
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWith_AttachesFields(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	reqLog := With(map[string]interface{}{"request_id": "r-1", "user_id": 7})
+	reqLog.Info("fetching profile")
+	reqLog.WithField("step", "db").ErrorfMap(nil, map[string]interface{}{"message": "not found", "user_id": 8})
+	Info("unbound")
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if e := entries[0]; e["message"] != "fetching profile" || e["request_id"] != "r-1" || e["level"] != "INFO" {
+		t.Errorf("expected bound fields on plain entry, got %v", e)
+	}
+	if e := entries[1]; e["step"] != "db" || e["request_id"] != "r-1" || e["user_id"] != float64(8) {
+		t.Errorf("expected chained fields with call fields taking precedence, got %v", e)
+	}
+	if _, ok := entries[2]["request_id"]; ok {
+		t.Errorf("parent logger must not carry child fields")
+	}
+}
+
+func TestWith_TextFormatAndReconfiguration(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "text", "debug")
+	child := WithField("request_id", "r 2")
+
+	var later bytes.Buffer
+	initTestLogger(&later, "text", "debug")
+	child.Warning("slow query")
+
+	if buf.Len() != 0 {
+		t.Errorf("expected child to follow the reconfigured parent")
+	}
+	if !strings.Contains(later.String(), `with_test.go`) || !strings.Contains(later.String(), `slow query request_id="r 2"`) {
+		t.Errorf("unexpected text entry: %q", later.String())
+	}
+}
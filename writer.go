@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// DefaultMaxLineBytes is the longest line a writer returned by WriterLevel
+// buffers before emitting it as a partial entry.
+const DefaultMaxLineBytes = 64 << 10
+
+// WriterOptions configures a writer created with NewWriter.
+type WriterOptions struct {
+	// Context is passed to every entry, e.g. to carry a trace_id.
+	Context context.Context
+	// Fields are attached to every entry.
+	Fields map[string]interface{}
+	// MaxLineBytes caps the buffered line length. Defaults to DefaultMaxLineBytes.
+	MaxLineBytes int
+	// ParseJSON merges lines that are themselves JSON objects into the entry
+	// instead of logging them as an opaque message. Their level, message and
+	// timestamp keys are mapped onto ours.
+	ParseJSON bool
+}
+
+// WriterLevel returns an io.WriteCloser that turns every line written to it
+// into an entry at the given level. It is meant for exec.Cmd Stdout/Stderr and
+// libraries that only accept an io.Writer.
+//
+// Writes may split lines at arbitrary boundaries: an incomplete trailing line
+// is buffered until its newline arrives or the writer is closed. Lines longer
+// than DefaultMaxLineBytes are emitted in chunks marked partial=true.
+//
+// LevelFatal is written as LevelError: a line from a subprocess or library
+// must not terminate this process.
+func WriterLevel(level logLevel) io.WriteCloser {
+	return NewWriter(level, WriterOptions{})
+}
+
+// WriterLevelWithMaxLine is like WriterLevel with a custom line length cap.
+func WriterLevelWithMaxLine(level logLevel, maxLine int) io.WriteCloser {
+	return NewWriter(level, WriterOptions{MaxLineBytes: maxLine})
+}
+
+// NewWriter is like WriterLevel with additional options.
+func NewWriter(level logLevel, opts WriterOptions) io.WriteCloser {
+	if opts.MaxLineBytes <= 0 {
+		opts.MaxLineBytes = DefaultMaxLineBytes
+	}
+	if level == LevelFatal {
+		level = LevelError
+	}
+	return &levelWriter{
+		ctx:       opts.Context,
+		level:     level,
+		fields:    opts.Fields,
+		maxLine:   opts.MaxLineBytes,
+		parseJSON: opts.ParseJSON,
+	}
+}
+
+type levelWriter struct {
+	ctx       context.Context
+	level     logLevel
+	fields    map[string]interface{}
+	maxLine   int
+	parseJSON bool
+
+	mu      sync.Mutex
+	pending []byte
+}
+
+func (w *levelWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data := p
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			w.pending = append(w.pending, data...)
+			break
+		}
+		w.pending = append(w.pending, data[:i]...)
+		data = data[i+1:]
+		w.emitLine(w.pending, false)
+		w.pending = w.pending[:0]
+	}
+
+	for len(w.pending) > w.maxLine {
+		w.emitLine(w.pending[:w.maxLine], true)
+		w.pending = append(w.pending[:0], w.pending[w.maxLine:]...)
+	}
+	return len(p), nil
+}
+
+// Close emits any buffered partial line.
+func (w *levelWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) > 0 {
+		w.emitLine(w.pending, false)
+		w.pending = nil
+	}
+	return nil
+}
+
+func (w *levelWriter) emitLine(line []byte, partial bool) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) == 0 {
+		return
+	}
+	if w.parseJSON && !partial && w.emitJSON(line) {
+		return
+	}
+	for len(line) > w.maxLine {
+		w.emit(line[:w.maxLine], true)
+		line = line[w.maxLine:]
+	}
+	w.emit(line, partial)
+}
+
+func (w *levelWriter) emit(line []byte, partial bool) {
+	fields := w.baseFields(2)
+	fields["message"] = string(line)
+	if partial {
+		fields["partial"] = true
+	}
+	logWithMap(w.level, w.ctx, fields)
+}
+
+func (w *levelWriter) baseFields(extra int) map[string]interface{} {
+	fields := make(map[string]interface{}, len(w.fields)+extra)
+	for k, v := range w.fields {
+		fields[k] = v
+	}
+	return fields
+}
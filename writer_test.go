@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestWriterLevel_OneEntryPerLine(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	w := WriterLevel(LevelWarn)
+	fmt.Fprint(w, "first line\nsecond line\r\n\n")
+	_ = w.Close()
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0]["message"] != "first line" || entries[1]["message"] != "second line" {
+		t.Errorf("unexpected messages: %v / %v", entries[0]["message"], entries[1]["message"])
+	}
+	if entries[0]["level"] != "WARNING" {
+		t.Errorf("expected WARNING level, got %v", entries[0]["level"])
+	}
+}
+
+func TestWriterLevel_BuffersPartialWrites(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	w := WriterLevel(LevelInfo)
+	fmt.Fprint(w, "hel")
+	fmt.Fprint(w, "lo\nwor")
+	if n := len(decodeLogLines(t, &buf)); n != 1 {
+		t.Fatalf("expected only the completed line to be logged, got %d entries", n)
+	}
+	fmt.Fprint(w, "ld")
+	_ = w.Close()
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 2 || entries[0]["message"] != "hello" || entries[1]["message"] != "world" {
+		t.Errorf("unexpected entries: %v", entries)
+	}
+}
+
+func TestWriterLevel_MaxLineCap(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+
+	w := WriterLevelWithMaxLine(LevelInfo, 4)
+	fmt.Fprint(w, "abcdefghij\nxy\n")
+	_ = w.Close()
+
+	entries := decodeLogLines(t, &buf)
+	var got []string
+	for _, e := range entries {
+		got = append(got, e["message"].(string))
+	}
+	want := []string{"abcd", "efgh", "ij", "xy"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected chunks %v, got %v", want, got)
+	}
+	if entries[0]["partial"] != true || entries[2]["partial"] != nil {
+		t.Errorf("expected only capped chunks to be marked partial: %v", entries)
+	}
+}
+
+func TestWriterLevel_FatalIsWrittenAsError(t *testing.T) {
+	var buf bytes.Buffer
+	initTestLogger(&buf, "json", "debug")
+	code := stubExit(t)
+
+	w := WriterLevel(LevelFatal)
+	fmt.Fprint(w, "subprocess gave up\n")
+	_ = w.Close()
+
+	if *code != -1 {
+		t.Errorf("writer at LevelFatal exited with %d", *code)
+	}
+	if entries := decodeLogLines(t, &buf); len(entries) != 1 || entries[0]["level"] != "ERROR" {
+		t.Errorf("expected one ERROR entry, got %v", entries)
+	}
+}